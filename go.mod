@@ -1,10 +1,23 @@
 module github.com/telemachus/gradebook-suite
 
-go 1.25
+go 1.25.0
 
 require (
 	github.com/telemachus/gradebook v0.3.0
 	github.com/telemachus/opts v0.4.0
+	golang.org/x/sys v0.47.0
+	golang.org/x/term v0.45.0
+	modernc.org/sqlite v1.59.0
 )
 
-require cloud.google.com/go v0.123.0 // indirect
+require (
+	cloud.google.com/go v0.123.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.24 // indirect
+	github.com/ncruces/go-strftime v1.0.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	modernc.org/libc v1.75.7 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.12.1 // indirect
+)
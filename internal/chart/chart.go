@@ -0,0 +1,323 @@
+// Package chart renders simple SVG charts of grades.
+//
+// Every chart is a complete, standalone SVG document that can be written to
+// a file or embedded directly in HTML. Output depends only on the input, so
+// the same data always produces byte-identical SVG.
+package chart
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	width        = 640
+	height       = 360
+	marginTop    = 40
+	marginBottom = 50
+	marginLeft   = 50
+	marginRight  = 20
+	legendWidth  = 140
+	xLabelWidth  = 70
+
+	fontSize = 12
+	maxScore = 100
+)
+
+// palette holds the colors used for bars, boxes, and lines, in order.
+var palette = []string{
+	"#4e79a7",
+	"#f28e2b",
+	"#59a14f",
+	"#e15759",
+	"#76b7b2",
+	"#edc948",
+	"#b07aa1",
+	"#9c755f",
+}
+
+// BoxGroup is one box in a box plot.
+type BoxGroup struct {
+	Label  string
+	Values []float64
+}
+
+// Line is one series in a trend chart. Values line up with the chart's x
+// labels, and a NaN value leaves a gap in the line.
+type Line struct {
+	Label  string
+	Values []float64
+}
+
+// plot holds the area of a chart inside its margins.
+type plot struct {
+	left, top, right, bottom float64
+}
+
+func newPlot(rightMargin float64) plot {
+	return plot{
+		left:   marginLeft,
+		top:    marginTop,
+		right:  width - rightMargin,
+		bottom: height - marginBottom,
+	}
+}
+
+func (p plot) width() float64 {
+	return p.right - p.left
+}
+
+// y returns the vertical position of value on a scale from 0 to top.
+func (p plot) y(value, top float64) float64 {
+	return p.bottom - value/top*(p.bottom-p.top)
+}
+
+// svgWriter accumulates the elements of an SVG document.
+type svgWriter struct {
+	sb strings.Builder
+}
+
+func newSVG(title string) *svgWriter {
+	w := &svgWriter{}
+	fmt.Fprintf(
+		&w.sb,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="%d">`+"\n",
+		width,
+		height,
+		width,
+		height,
+		fontSize,
+	)
+	fmt.Fprintf(&w.sb, "<title>%s</title>\n", html.EscapeString(title))
+	fmt.Fprintf(&w.sb, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	w.text(width/2, marginTop/2, "middle", title)
+
+	return w
+}
+
+func (w *svgWriter) bytes() []byte {
+	w.sb.WriteString("</svg>\n")
+
+	return []byte(w.sb.String())
+}
+
+func (w *svgWriter) text(x, y float64, anchor, s string) {
+	fmt.Fprintf(
+		&w.sb,
+		`<text x="%s" y="%s" text-anchor="%s">%s</text>`+"\n",
+		num(x),
+		num(y),
+		anchor,
+		html.EscapeString(s),
+	)
+}
+
+func (w *svgWriter) line(x1, y1, x2, y2 float64, stroke string) {
+	fmt.Fprintf(
+		&w.sb,
+		`<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s"/>`+"\n",
+		num(x1),
+		num(y1),
+		num(x2),
+		num(y2),
+		stroke,
+	)
+}
+
+func (w *svgWriter) rect(x, y, rw, rh float64, fill string) {
+	fmt.Fprintf(
+		&w.sb,
+		`<rect x="%s" y="%s" width="%s" height="%s" fill="%s" stroke="#333333"/>`+"\n",
+		num(x),
+		num(y),
+		num(rw),
+		num(rh),
+		fill,
+	)
+}
+
+// yAxis draws a vertical axis from 0 to top with a labeled grid line every
+// step.
+func (w *svgWriter) yAxis(p plot, top, step float64) {
+	for value := 0.0; value <= top; value += step {
+		y := p.y(value, top)
+		w.line(p.left, y, p.right, y, "#dddddd")
+		w.text(p.left-6, y+fontSize/3, "end", num(value))
+	}
+	w.line(p.left, p.top, p.left, p.bottom, "#333333")
+	w.line(p.left, p.bottom, p.right, p.bottom, "#333333")
+}
+
+// num formats a coordinate or label with at most two decimal places, so
+// output does not depend on floating-point noise.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// Histogram draws the distribution of scores in bins of ten points: 0-9,
+// 10-19, and so on up to 90+, which includes scores above 100.
+func Histogram(title string, scores []float64) []byte {
+	const bins = 10
+
+	counts := make([]int, bins)
+	for _, score := range scores {
+		bin := int(math.Floor(score / 10))
+		counts[max(min(bin, bins-1), 0)]++
+	}
+
+	top := float64(max(slices.Max(counts), 1))
+	step := math.Max(1, math.Ceil(top/5))
+	top = math.Ceil(top/step) * step
+
+	p := newPlot(marginRight)
+	w := newSVG(title)
+	w.yAxis(p, top, step)
+
+	barWidth := p.width() / bins
+	for i, count := range counts {
+		x := p.left + float64(i)*barWidth
+		label := fmt.Sprintf("%d-%d", i*10, i*10+9)
+		if i == bins-1 {
+			label = "90+"
+		}
+		w.text(x+barWidth/2, p.bottom+fontSize+6, "middle", label)
+
+		if count == 0 {
+			continue
+		}
+		y := p.y(float64(count), top)
+		w.rect(x+2, y, barWidth-4, p.bottom-y, palette[0])
+		w.text(x+barWidth/2, y-4, "middle", strconv.Itoa(count))
+	}
+
+	return w.bytes()
+}
+
+// BoxPlot draws one box per group on a scale from 0 to 100 (or higher, if
+// a value is above 100). Each box spans the first to third quartile, with
+// a line at the median and whiskers out to the lowest and highest values.
+// A group without values gets a label but no box.
+func BoxPlot(title string, groups []BoxGroup) []byte {
+	top := float64(maxScore)
+	for _, g := range groups {
+		if len(g.Values) > 0 {
+			top = math.Max(top, math.Ceil(slices.Max(g.Values)/20)*20)
+		}
+	}
+
+	p := newPlot(marginRight)
+	w := newSVG(title)
+	w.yAxis(p, top, 20)
+
+	slot := p.width() / float64(max(len(groups), 1))
+	for i, g := range groups {
+		center := p.left + (float64(i)+0.5)*slot
+		w.text(center, p.bottom+fontSize+6, "middle", g.Label)
+		if len(g.Values) == 0 {
+			continue
+		}
+
+		sorted := slices.Sorted(slices.Values(g.Values))
+		q1, median, q3 := quantile(sorted, 0.25), quantile(sorted, 0.5), quantile(sorted, 0.75)
+		half := math.Min(slot/4, 40)
+		color := palette[i%len(palette)]
+
+		w.line(center, p.y(sorted[0], top), center, p.y(q1, top), "#333333")
+		w.line(center, p.y(q3, top), center, p.y(sorted[len(sorted)-1], top), "#333333")
+		w.line(center-half/2, p.y(sorted[0], top), center+half/2, p.y(sorted[0], top), "#333333")
+		w.line(
+			center-half/2,
+			p.y(sorted[len(sorted)-1], top),
+			center+half/2,
+			p.y(sorted[len(sorted)-1], top),
+			"#333333",
+		)
+		w.rect(center-half, p.y(q3, top), 2*half, p.y(q1, top)-p.y(q3, top), color)
+		w.line(center-half, p.y(median, top), center+half, p.y(median, top), "#000000")
+	}
+
+	return w.bytes()
+}
+
+// quantile returns the q-quantile of sorted values, interpolating linearly
+// between the closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// TrendLines draws each line against the x labels on a scale from 0 to 100,
+// with a legend to the right of the plot. If there are too many x labels to
+// fit, only every few are shown.
+func TrendLines(title string, xLabels []string, lines []Line) []byte {
+	p := newPlot(legendWidth)
+	w := newSVG(title)
+	w.yAxis(p, maxScore, 20)
+
+	step := p.width() / float64(max(len(xLabels), 1))
+	x := func(i int) float64 {
+		return p.left + (float64(i)+0.5)*step
+	}
+	// Label only as many points as fit without overlapping.
+	every := max(1, int(math.Ceil(float64(len(xLabels))*xLabelWidth/p.width())))
+	for i, label := range xLabels {
+		if i%every == 0 {
+			w.text(x(i), p.bottom+fontSize+6, "middle", label)
+		}
+	}
+
+	for i, l := range lines {
+		color := palette[i%len(palette)]
+		for _, segment := range segments(l.Values) {
+			points := make([]string, 0, len(segment))
+			for _, j := range segment {
+				points = append(points, num(x(j))+","+num(p.y(math.Min(l.Values[j], maxScore), maxScore)))
+			}
+			fmt.Fprintf(
+				&w.sb,
+				`<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n",
+				strings.Join(points, " "),
+				color,
+			)
+			for _, j := range segment {
+				y := p.y(math.Min(l.Values[j], maxScore), maxScore)
+				fmt.Fprintf(&w.sb, `<circle cx="%s" cy="%s" r="3" fill="%s"/>`+"\n", num(x(j)), num(y), color)
+			}
+		}
+
+		legendY := p.top + float64(i)*(fontSize+8)
+		w.rect(p.right+16, legendY, fontSize, fontSize, color)
+		w.text(p.right+16+fontSize+6, legendY+fontSize-2, "start", l.Label)
+	}
+
+	return w.bytes()
+}
+
+// segments splits the indexes of values into runs without NaN values.
+func segments(values []float64) [][]int {
+	var runs [][]int
+	var run []int
+	for i, v := range values {
+		if math.IsNaN(v) {
+			if len(run) > 0 {
+				runs = append(runs, run)
+			}
+			run = nil
+
+			continue
+		}
+		run = append(run, i)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+
+	return runs
+}
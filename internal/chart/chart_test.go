@@ -0,0 +1,125 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+// countElements checks that svg is well-formed XML and counts its elements
+// by name.
+func countElements(t *testing.T, svg []byte) map[string]int {
+	t.Helper()
+
+	counts := make(map[string]int)
+	dec := xml.NewDecoder(bytes.NewReader(svg))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("svg is not well-formed: %v\n%s", err, svg)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+
+	return counts
+}
+
+func TestHistogram(t *testing.T) {
+	t.Parallel()
+
+	svg := Histogram("Quiz <1> & more", []float64{55, 72, 79, 88, 91, 100, 104, -3})
+	counts := countElements(t, svg)
+
+	// One background rect plus one bar for each of the five non-empty bins.
+	if counts["rect"] != 6 {
+		t.Fatalf("rect count = %d; want 6\n%s", counts["rect"], svg)
+	}
+	if !bytes.Contains(svg, []byte("<title>Quiz &lt;1&gt; &amp; more</title>")) {
+		t.Fatalf("svg does not contain escaped title\n%s", svg)
+	}
+	if !bytes.Contains(svg, []byte(">90+</text>")) {
+		t.Fatalf("svg does not label the top bin\n%s", svg)
+	}
+}
+
+func TestHistogramIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	scores := []float64{61.333, 72.5, 88.125}
+	if !bytes.Equal(Histogram("Quiz", scores), Histogram("Quiz", scores)) {
+		t.Fatal("Histogram() output differs between runs")
+	}
+}
+
+func TestBoxPlot(t *testing.T) {
+	t.Parallel()
+
+	svg := BoxPlot("Category means", []BoxGroup{
+		{Label: "Major", Values: []float64{60, 70, 80, 90}},
+		{Label: "Minor", Values: nil},
+		{Label: "Participation", Values: []float64{95}},
+	})
+	counts := countElements(t, svg)
+
+	// One background rect plus one box for each group with values.
+	if counts["rect"] != 3 {
+		t.Fatalf("rect count = %d; want 3\n%s", counts["rect"], svg)
+	}
+	if !strings.Contains(string(svg), ">Minor</text>") {
+		t.Fatalf("svg does not label the empty group\n%s", svg)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	t.Parallel()
+
+	sorted := []float64{60, 70, 80, 90}
+	testCases := map[float64]float64{
+		0:    60,
+		0.25: 67.5,
+		0.5:  75,
+		0.75: 82.5,
+		1:    90,
+	}
+
+	for q, want := range testCases {
+		if got := quantile(sorted, q); got != want {
+			t.Errorf("quantile(%v) = %v; want %v", q, got, want)
+		}
+	}
+}
+
+func TestTrendLines(t *testing.T) {
+	t.Parallel()
+
+	svg := TrendLines("Bob Young", []string{"quiz-1", "test-1", "quiz-2"}, []Line{
+		{Label: "Overall", Values: []float64{90, 71.25, 80}},
+		{Label: "Major", Values: []float64{math.NaN(), 60, math.NaN()}},
+	})
+	counts := countElements(t, svg)
+
+	if counts["polyline"] != 2 {
+		t.Fatalf("polyline count = %d; want 2\n%s", counts["polyline"], svg)
+	}
+	if counts["circle"] != 4 {
+		t.Fatalf("circle count = %d; want 4\n%s", counts["circle"], svg)
+	}
+}
+
+func TestSegments(t *testing.T) {
+	t.Parallel()
+
+	nan := math.NaN()
+	got := segments([]float64{nan, 1, 2, nan, nan, 3})
+	if len(got) != 2 || len(got[0]) != 2 || len(got[1]) != 1 || got[1][0] != 5 {
+		t.Fatalf("segments() = %v; want [[1 2] [5]]", got)
+	}
+}
@@ -0,0 +1,402 @@
+package cli
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	formatText      = "text"
+	formatJSON      = "json"
+	formatMailMerge = "mail-merge"
+	formatCSV       = "csv"
+	formatSVG       = "svg"
+)
+
+// alertThresholds holds the default thresholds for gradebook-alerts. A student
+// is flagged if the student's overall average is below BelowAverage, if it has
+// dropped by more than DropPoints over the last DropDays days, or if the
+// student has more than MaxUnscored unscored assignments. A nil threshold is
+// not checked.
+type alertThresholds struct {
+	BelowAverage *float64 `json:"below_average"`
+	DropPoints   *float64 `json:"drop_points"`
+	MaxUnscored  *int     `json:"max_unscored"`
+	DropDays     int      `json:"drop_days"`
+}
+
+func (ce *classExtras) checkAlerts() error {
+	if ce == nil || ce.Alerts == nil {
+		return nil
+	}
+
+	at := ce.Alerts
+	errs := make([]error, 0, 4)
+	if at.BelowAverage != nil && *at.BelowAverage <= 0 {
+		errs = append(errs, errors.New("alerts below_average must be positive"))
+	}
+	if at.DropPoints != nil && *at.DropPoints <= 0 {
+		errs = append(errs, errors.New("alerts drop_points must be positive"))
+	}
+	if at.MaxUnscored != nil && *at.MaxUnscored < 0 {
+		errs = append(errs, errors.New("alerts max_unscored must not be negative"))
+	}
+	if at.DropDays < 0 {
+		errs = append(errs, errors.New("alerts drop_days must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// GradebookAlerts lists students who may be in trouble: students whose
+// overall average is low, has dropped sharply, or who have too many unscored
+// assignments.
+func GradebookAlerts(args []string) int {
+	cmd := cmdFrom("gradebook-alerts", alertsUsage)
+
+	return runCommand(cmd, args, commandRun[alertsCfg]{
+		parse:     (*cmdEnv).parseAlerts,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg alertsCfg) {
+			cmd.findTerm(class, cfg.term)
+			cmd.findSection()
+			cfg = cmd.resolveAlerts(cfg)
+			gbFiles := cmd.readGradebooks(class, cfg.term)
+			alerts := cmd.findAlerts(class, gbFiles, cfg)
+			cmd.printAlerts(alerts, cfg.format)
+		},
+	})
+}
+
+// alertsCfg holds the thresholds for one run of gradebook-alerts. A NaN
+// average threshold or a negative unscored threshold is not checked.
+type alertsCfg struct {
+	term        string
+	since       string
+	format      string
+	below       float64
+	drop        float64
+	maxUnscored int
+}
+
+func (cmd *cmdEnv) parseAlerts(args []string) alertsCfg {
+	og := cmd.commonOptsGroup(parseOpts{lastFirst: true, section: true, store: true})
+
+	var cfg alertsCfg
+	og.String(&cfg.term, "term", "")
+	og.String(&cfg.since, "since", "")
+	og.String(&cfg.format, "format", formatText)
+	og.Float64(&cfg.below, "below", math.NaN())
+	og.Float64(&cfg.drop, "drop", math.NaN())
+	og.Int(&cfg.maxUnscored, "max-unscored", -1)
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkNameStyle()
+	cmd.checkAlertsFlags(cfg)
+
+	return cfg
+}
+
+func (cmd *cmdEnv) checkAlertsFlags(cfg alertsCfg) {
+	if cmd.noOp() {
+		return
+	}
+
+	var err error
+	switch {
+	case cfg.format != formatText && cfg.format != formatJSON && cfg.format != formatMailMerge:
+		err = fmt.Errorf("unknown format %q (want %q, %q, or %q)", cfg.format, formatText, formatJSON, formatMailMerge)
+	case cfg.since != "" && !isDate(cfg.since):
+		err = fmt.Errorf("invalid yyyymmdd date for -since: %q", cfg.since)
+	case cfg.below <= 0 || cfg.drop <= 0:
+		err = errors.New("-below and -drop must be positive")
+	}
+
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+	}
+}
+
+func isDate(date string) bool {
+	_, err := time.Parse(dateLayout, date)
+
+	return err == nil
+}
+
+// resolveAlerts fills in every threshold that was not set by a flag from the
+// class's alerts. Without -since, a drop is measured over the last drop_days
+// days.
+func (cmd *cmdEnv) resolveAlerts(cfg alertsCfg) alertsCfg {
+	if cmd.noOp() {
+		return cfg
+	}
+
+	if at := cmd.alertThresholds(); at != nil {
+		if math.IsNaN(cfg.below) && at.BelowAverage != nil {
+			cfg.below = *at.BelowAverage
+		}
+		if math.IsNaN(cfg.drop) && at.DropPoints != nil {
+			cfg.drop = *at.DropPoints
+		}
+		if cfg.maxUnscored < 0 && at.MaxUnscored != nil {
+			cfg.maxUnscored = *at.MaxUnscored
+		}
+		if cfg.since == "" && at.DropDays > 0 {
+			today, _ := time.Parse(dateLayout, cmd.today)
+			cfg.since = today.AddDate(0, 0, -at.DropDays).Format(dateLayout)
+		}
+	}
+
+	switch {
+	case math.IsNaN(cfg.below) && math.IsNaN(cfg.drop) && cfg.maxUnscored < 0:
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: no alert thresholds set in flags or class alerts\n", cmd.name)
+	case !math.IsNaN(cfg.drop) && cfg.since == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: a drop threshold needs -since or alerts drop_days\n", cmd.name)
+	}
+
+	return cfg
+}
+
+func (cmd *cmdEnv) alertThresholds() *alertThresholds {
+	if cmd.extras == nil {
+		return nil
+	}
+
+	return cmd.extras.Alerts
+}
+
+// studentAlert lists the reasons that a student was flagged, along with the
+// latest comment on the student's work.
+type studentAlert struct {
+	Email         string                  `json:"email"`
+	Name          string                  `json:"name"`
+	FirstName     string                  `json:"first_name"`
+	LastName      string                  `json:"last_name"`
+	Reasons       []string                `json:"reasons"`
+	LatestComment string                  `json:"latest_comment,omitempty"`
+	Average       gradebook.AverageResult `json:"-"`
+}
+
+func (cmd *cmdEnv) findAlerts(class *gradebook.Class, gbFiles []*gradebookFile, cfg alertsCfg) []*studentAlert {
+	if cmd.noOp() {
+		return nil
+	}
+
+	earlier := cmd.averagesSince(class, gbFiles, cfg)
+	addGrades(class, cmd.extras, gbFiles, cmd.today)
+	addUnscored(class, cmd.extras, gbFiles)
+
+	comments := latestComments(gbFiles)
+	alerts := make([]*studentAlert, 0, len(class.StudentsByEmail))
+	for _, email := range cmd.emailsInSection(class) {
+		s := class.StudentsByEmail[email]
+		alert := &studentAlert{
+			Email:         email,
+			Name:          cmd.studentName(s, email),
+			FirstName:     s.FirstName,
+			LastName:      s.LastName,
+			Reasons:       alertReasons(s, class, earlier[email], cfg),
+			LatestComment: comments[email],
+			Average:       s.TotalAverage(class.WeightsByAssignmentCategory),
+		}
+		if len(alert.Reasons) > 0 {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts
+}
+
+// latestComments returns each student's most recent comment, by assignment
+// date, prefixed with the name of the assignment.
+func latestComments(gbFiles []*gradebookFile) map[string]string {
+	comments := make(map[string]string)
+	for _, gbf := range sortedByDate(gbFiles) {
+		for _, ar := range gbf.AssignmentRecords {
+			if ar.Comment != "" {
+				comments[ar.Email] = gbf.AssignmentName + ": " + ar.Comment
+			}
+		}
+	}
+
+	return comments
+}
+
+// averagesSince returns each student's overall average as it stood on
+// cfg.since, counting only assignments dated on or before it.
+func (cmd *cmdEnv) averagesSince(
+	class *gradebook.Class,
+	gbFiles []*gradebookFile,
+	cfg alertsCfg,
+) map[string]gradebook.AverageResult {
+	if math.IsNaN(cfg.drop) {
+		return map[string]gradebook.AverageResult{}
+	}
+
+	before := make([]*gradebookFile, 0, len(gbFiles))
+	for _, gbf := range gbFiles {
+		if gbf.AssignmentDate <= cfg.since {
+			before = append(before, gbf)
+		}
+	}
+	addGrades(class, cmd.extras, before, cfg.since)
+
+	averages := make(map[string]gradebook.AverageResult, len(class.StudentsByEmail))
+	for email, s := range class.StudentsByEmail {
+		averages[email] = s.TotalAverage(class.WeightsByAssignmentCategory)
+	}
+
+	return averages
+}
+
+func alertReasons(
+	s *gradebook.Student,
+	class *gradebook.Class,
+	earlier gradebook.AverageResult,
+	cfg alertsCfg,
+) []string {
+	reasons := make([]string, 0, 3)
+	avg := s.TotalAverage(class.WeightsByAssignmentCategory)
+
+	if !math.IsNaN(cfg.below) && avg.Valid && avg.Value < cfg.below {
+		reasons = append(reasons, fmt.Sprintf("overall average %s is below %s", avg, formatScore(cfg.below)))
+	}
+
+	if !math.IsNaN(cfg.drop) && avg.Valid && earlier.Valid && earlier.Value-avg.Value > cfg.drop {
+		reasons = append(reasons, fmt.Sprintf(
+			"overall average dropped %s points since %s (%s -> %s)",
+			formatScore(earlier.Value-avg.Value),
+			cfg.since,
+			earlier,
+			avg,
+		))
+	}
+
+	if cfg.maxUnscored >= 0 {
+		unscored := 0
+		for _, n := range s.UnscoredByCategory {
+			unscored += n
+		}
+		word := "assignments"
+		if unscored == 1 {
+			word = "assignment"
+		}
+		if unscored > cfg.maxUnscored {
+			reasons = append(reasons, fmt.Sprintf("%d unscored %s (more than %d)", unscored, word, cfg.maxUnscored))
+		}
+	}
+
+	return reasons
+}
+
+func (cmd *cmdEnv) printAlerts(alerts []*studentAlert, format string) {
+	if cmd.noOp() {
+		return
+	}
+
+	var err error
+	switch format {
+	case formatJSON:
+		err = cmd.printAlertsJSON(alerts)
+	case formatMailMerge:
+		err = cmd.printAlertsMailMerge(alerts)
+	default:
+		cmd.printAlertsText(alerts)
+	}
+
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+	}
+}
+
+func (cmd *cmdEnv) printAlertsText(alerts []*studentAlert) {
+	if len(alerts) == 0 {
+		fmt.Fprintln(cmd.stdout, "No students to alert")
+
+		return
+	}
+
+	for _, alert := range alerts {
+		fmt.Fprintln(cmd.stdout, alert.Name)
+		for _, reason := range alert.Reasons {
+			fmt.Fprintf(cmd.stdout, "\t%s\n", reason)
+		}
+	}
+}
+
+// alertJSON adds the student's overall average, rounded to two decimal
+// places, to a studentAlert. The average is null if the student has none.
+type alertJSON struct {
+	*studentAlert
+	OverallAverage *float64 `json:"overall_average"`
+}
+
+func (cmd *cmdEnv) printAlertsJSON(alerts []*studentAlert) error {
+	out := make([]alertJSON, 0, len(alerts))
+	for _, alert := range alerts {
+		aj := alertJSON{studentAlert: alert}
+		if alert.Average.Valid {
+			avg := roundScore(alert.Average.Value)
+			aj.OverallAverage = &avg
+		}
+		out = append(out, aj)
+	}
+
+	data, err := marshalJSONFile(out)
+	if err != nil {
+		return err
+	}
+
+	if _, err = cmd.stdout.Write(data); err != nil {
+		return fmt.Errorf("write alerts: %w", err)
+	}
+
+	return nil
+}
+
+// printAlertsMailMerge prints one CSV row per flagged student, with the
+// reasons joined into a single field and the latest comment, for use in a mail
+// merge.
+func (cmd *cmdEnv) printAlertsMailMerge(alerts []*studentAlert) error {
+	w := csv.NewWriter(cmd.stdout)
+	rows := make([][]string, 0, len(alerts)+1)
+	rows = append(rows, []string{"Email", "Name", "First Name", "Last Name", "Overall Average", "Reasons", "Latest Comment"})
+	for _, alert := range alerts {
+		avg := ""
+		if alert.Average.Valid {
+			avg = formatScore(alert.Average.Value)
+		}
+		rows = append(rows, []string{
+			alert.Email,
+			alert.Name,
+			alert.FirstName,
+			alert.LastName,
+			avg,
+			strings.Join(alert.Reasons, "; "),
+			alert.LatestComment,
+		})
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("write alerts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const alertsClassJSON = `{
+    "alerts": {
+        "below_average": 80,
+        "max_unscored": 0
+    }
+}`
+
+const alertsTestGradebookJSON = `{
+    "assignment_category": "major",
+    "assignment_date": "20240401",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 60,
+            "comment": "See me about retaking, please"
+        },
+        {
+            "email": "alice@example.com",
+            "grade": 70
+        }
+    ],
+    "assignment_name": "test-1",
+    "assignment_type": "test"
+}`
+
+func writeAlertsFixture(t *testing.T) string {
+	t.Helper()
+
+	return writeFixtureDir(t, alertsClassJSON, map[string]string{
+		"quiz-quiz-1-20240319.gradebook": gradebookFixtureJSON,
+		"test-test-1-20240401.gradebook": alertsTestGradebookJSON,
+	})
+}
+
+func TestPublicGradebookAlertsText(t *testing.T) {
+	t.Parallel()
+
+	dir := writeAlertsFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookAlerts, []string{
+		"-dir", dir,
+		"-drop", "10",
+		"-since", "20240320",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	// Bob averaged 90 on 20240320 and 71.25 after the test. Alice had no
+	// average on 20240320, so she cannot have dropped.
+	want := "" +
+		"Bob Young\n" +
+		"\toverall average 71 is below 80\n" +
+		"\toverall average dropped 18.75 points since 20240320 (90 -> 71)\n" +
+		"Alice Zephyr\n" +
+		"\toverall average 70 is below 80\n" +
+		"\t1 unscored assignment (more than 0)\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookAlertsFlagsOverrideClass(t *testing.T) {
+	t.Parallel()
+
+	dir := writeAlertsFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookAlerts, []string{
+		"-dir", dir,
+		"-below", "50",
+		"-max-unscored", "1",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if stdout != "No students to alert\n" {
+		t.Fatalf("stdout = %q; want no alerts", stdout)
+	}
+}
+
+func TestPublicGradebookAlertsJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := writeAlertsFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookAlerts, []string{
+		"-dir", dir,
+		"-format", "json",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	var got []struct {
+		OverallAverage *float64 `json:"overall_average"`
+		Email          string   `json:"email"`
+		LatestComment  string   `json:"latest_comment"`
+		Reasons        []string `json:"reasons"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v (stdout: %q)", err, stdout)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(alerts) = %d; want 2", len(got))
+	}
+	if got[0].Email != "bob@example.com" || got[0].OverallAverage == nil || *got[0].OverallAverage != 71.25 {
+		t.Fatalf("alerts[0] = %+v; want Bob with average 71.25", got[0])
+	}
+	if want := "test-1: See me about retaking, please"; got[0].LatestComment != want {
+		t.Fatalf("alerts[0].LatestComment = %q; want %q", got[0].LatestComment, want)
+	}
+	if len(got[1].Reasons) != 2 {
+		t.Fatalf("alerts[1].Reasons = %q; want 2 reasons", got[1].Reasons)
+	}
+}
+
+func TestPublicGradebookAlertsMailMerge(t *testing.T) {
+	t.Parallel()
+
+	dir := writeAlertsFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookAlerts, []string{
+		"-dir", dir,
+		"-format", "mail-merge",
+		"-max-unscored", "5",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "" +
+		"Email,Name,First Name,Last Name,Overall Average,Reasons,Latest Comment\n" +
+		"bob@example.com,Bob Young,Bob,Young,71.25,overall average 71 is below 80,\"test-1: See me about retaking, please\"\n" +
+		"alice@example.com,Alice Zephyr,Alice,Zephyr,70,overall average 70 is below 80,\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookAlertsNeedsThresholds(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookAlerts, []string{"-dir", dir})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.Contains(stderr, "no alert thresholds") {
+		t.Fatalf("stderr = %q; want thresholds error", stderr)
+	}
+}
+
+func TestPublicGradebookAlertsDropNeedsSince(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookAlerts, []string{"-dir", dir, "-drop", "5"})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.Contains(stderr, "needs -since") {
+		t.Fatalf("stderr = %q; want -since error", stderr)
+	}
+}
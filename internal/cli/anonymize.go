@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/telemachus/gradebook"
+)
+
+// anonFirstName is every anonymized student's first name. Their last name is
+// the pseudonym's ID, so anonymized students sort in a stable order that says
+// nothing about who they are.
+const anonFirstName = "Student"
+
+// GradebookAnonymize writes a copy of a class with every student replaced by
+// a pseudonym.
+func GradebookAnonymize(args []string) int {
+	cmd := cmdFrom("gradebook-anonymize", anonymizeUsage)
+
+	return runCommand(cmd, args, commandRun[anonymizeCfg]{
+		parse:     (*cmdEnv).parseAnonymize,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg anonymizeCfg) {
+			gbFiles := cmd.readGradebooks(class, "")
+			pseudonyms := cmd.pseudonyms(class, cfg.secretFile)
+			files := cmd.anonymizeFiles(gbFiles, pseudonyms, cfg.out)
+			cmd.writeAnonymized(files, cfg.out)
+			cmd.writeAnonKey(class, pseudonyms, cfg.keyFile)
+		},
+	})
+}
+
+type anonymizeCfg struct {
+	out        string
+	secretFile string
+	keyFile    string
+}
+
+func (cmd *cmdEnv) parseAnonymize(args []string) anonymizeCfg {
+	var cfg anonymizeCfg
+
+	og := cmd.commonOptsGroup(parseOpts{store: true})
+	og.String(&cfg.out, "out", "")
+	og.String(&cfg.secretFile, "secret", "")
+	og.String(&cfg.keyFile, "key", "")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+	if cmd.noOp() {
+		return cfg
+	}
+
+	switch {
+	case cfg.out == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -out is required\n", cmd.name)
+	case cfg.secretFile == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -secret is required\n", cmd.name)
+	}
+
+	return cfg
+}
+
+// pseudonyms maps each student's email to a pseudonymous email. A pseudonym
+// is an HMAC of the email keyed by the secret, so the same secret gives the
+// same pseudonyms on every run, and without the secret they cannot be traced
+// back to students.
+func (cmd *cmdEnv) pseudonyms(class *gradebook.Class, secretFile string) map[string]string {
+	if cmd.noOp() {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(secretFile))
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: read secret file %q: %s\n", cmd.name, secretFile, err)
+
+		return nil
+	}
+	secret := bytes.TrimSpace(data)
+	if len(secret) == 0 {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: secret file %q is empty\n", cmd.name, secretFile)
+
+		return nil
+	}
+
+	pseudonyms := make(map[string]string, len(class.StudentsByEmail))
+	taken := make(map[string]string, len(class.StudentsByEmail))
+	for email := range class.StudentsByEmail {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(strings.ToLower(email)))
+		pseudonym := fmt.Sprintf("student-%s@example.invalid", hex.EncodeToString(mac.Sum(nil)[:5]))
+		if other, ok := taken[pseudonym]; ok {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: %q and %q have the same pseudonym; use another secret\n", cmd.name, email, other)
+
+			return nil
+		}
+		taken[pseudonym] = email
+		pseudonyms[email] = pseudonym
+	}
+
+	return pseudonyms
+}
+
+// anonPseudonymID returns the ID part of a pseudonymous email.
+func anonPseudonymID(pseudonym string) string {
+	id, _, _ := strings.Cut(strings.TrimPrefix(pseudonym, "student-"), "@")
+
+	return strings.ToUpper(id)
+}
+
+// anonymizeFiles returns the anonymized class file and gradebook files, keyed
+// by their paths in out.
+func (cmd *cmdEnv) anonymizeFiles(
+	gbFiles []*gradebookFile,
+	pseudonyms map[string]string,
+	out string,
+) map[string][]byte {
+	if cmd.noOp() {
+		return nil
+	}
+
+	store := cmd.classStore()
+	files := make(map[string][]byte, len(gbFiles)+1)
+
+	data, err := store.readFile(cmd.classFile)
+	if err == nil {
+		data, err = anonymizeClassFile(data, pseudonyms)
+	}
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem anonymizing class file: %s\n", cmd.name, err)
+
+		return nil
+	}
+	files[filepath.Join(out, suiteClassFile)] = data
+
+	for _, gbf := range gbFiles {
+		data, err := store.readFile(gbf.path)
+		if err == nil {
+			data, err = anonymizeGradebookFile(data, pseudonyms)
+		}
+		if err != nil {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: problem anonymizing %q: %s\n", cmd.name, gbf.path, err)
+
+			return nil
+		}
+		files[filepath.Join(out, path.Base(filepath.ToSlash(gbf.path)))] = data
+	}
+
+	return files
+}
+
+// anonymizeClassFile replaces students_by_email with pseudonymous students.
+// Only a student's section is kept, so that sections can still be compared.
+// Every other field in the class is carried over unchanged.
+func anonymizeClassFile(data []byte, pseudonyms map[string]string) ([]byte, error) {
+	raw := newJSONObject()
+	if err := json.Unmarshal(data, raw); err != nil {
+		return nil, fmt.Errorf("unmarshal class file: %w", err)
+	}
+
+	var extras map[string]*studentExtras
+	if err := json.Unmarshal(raw.get("students_by_email"), &extras); err != nil {
+		return nil, fmt.Errorf("unmarshal students: %w", err)
+	}
+
+	type anonStudent struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Section   string `json:"section,omitempty"`
+	}
+	students := make(map[string]anonStudent, len(pseudonyms))
+	for email, pseudonym := range pseudonyms {
+		s := anonStudent{FirstName: anonFirstName, LastName: anonPseudonymID(pseudonym)}
+		if se := extras[email]; se != nil {
+			s.Section = se.Section
+		}
+		students[pseudonym] = s
+	}
+
+	if err := raw.setValue("students_by_email", students); err != nil {
+		return nil, fmt.Errorf("marshal students: %w", err)
+	}
+
+	return marshalJSONFile(raw)
+}
+
+// anonymizeGradebookFile replaces the email in each record with its
+// pseudonym and drops comments, which may name students. The records are
+// sorted by pseudonym, since their original order follows the roster and
+// would give away who is who. Every other field in the file and in its
+// records is carried over unchanged.
+func anonymizeGradebookFile(data []byte, pseudonyms map[string]string) ([]byte, error) {
+	raw := newJSONObject()
+	if err := json.Unmarshal(data, raw); err != nil {
+		return nil, fmt.Errorf("unmarshal gradebook file: %w", err)
+	}
+
+	var records []*jsonObject
+	if err := json.Unmarshal(raw.get("assignment_records"), &records); err != nil {
+		return nil, fmt.Errorf("unmarshal assignment records: %w", err)
+	}
+	byRecord := make(map[*jsonObject]string, len(records))
+	for _, rec := range records {
+		var email string
+		if err := json.Unmarshal(rec.get("email"), &email); err != nil {
+			return nil, fmt.Errorf("unmarshal email: %w", err)
+		}
+		pseudonym, ok := pseudonyms[email]
+		if !ok {
+			return nil, fmt.Errorf("no student with email %q", email)
+		}
+		if err := rec.setValue("email", pseudonym); err != nil {
+			return nil, err
+		}
+		rec.delete("comment")
+		byRecord[rec] = pseudonym
+	}
+	slices.SortStableFunc(records, func(a, b *jsonObject) int {
+		return strings.Compare(byRecord[a], byRecord[b])
+	})
+
+	if err := raw.setValue("assignment_records", records); err != nil {
+		return nil, fmt.Errorf("marshal assignment records: %w", err)
+	}
+
+	return marshalJSONFile(raw)
+}
+
+// writeAnonymized writes the anonymized files to out. No file is written if
+// any of them already exists.
+func (cmd *cmdEnv) writeAnonymized(files map[string][]byte, out string) {
+	if cmd.noOp() {
+		return
+	}
+
+	names := slices.Sorted(maps.Keys(files))
+	for _, name := range names {
+		if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: %q already exists\n", cmd.name, name)
+
+			return
+		}
+	}
+
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return
+	}
+	for _, name := range names {
+		if err := writeFile(name, files[name]); err != nil {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: problem writing %q: %s\n", cmd.name, name, err)
+
+			return
+		}
+	}
+
+	fmt.Fprintf(cmd.stdout, "Wrote an anonymized class with %d gradebook file(s) to %s\n", len(files)-1, out)
+}
+
+// writeAnonKey writes a CSV that maps each pseudonym back to its student. The
+// key is for the teacher alone, so only its owner can read it.
+func (cmd *cmdEnv) writeAnonKey(class *gradebook.Class, pseudonyms map[string]string, keyFile string) {
+	if cmd.noOp() || keyFile == "" {
+		return
+	}
+
+	rows := [][]string{{"pseudonym", "email", "first_name", "last_name"}}
+	for _, email := range class.EmailsSortedByStudentName() {
+		s := class.StudentsByEmail[email]
+		rows = append(rows, []string{pseudonyms[email], email, s.FirstName, s.LastName})
+	}
+	slices.SortFunc(rows[1:], func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+
+	var buf bytes.Buffer
+	err := csv.NewWriter(&buf).WriteAll(rows)
+	if err == nil {
+		err = writeFileMode(keyFile, buf.Bytes(), 0o600)
+	}
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem writing key: %s\n", cmd.name, err)
+
+		return
+	}
+
+	fmt.Fprintf(cmd.stdout, "Wrote the key to %s\n", keyFile)
+}
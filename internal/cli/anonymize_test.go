@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func anonymizeFixture(t *testing.T, dir, secret string, extra ...string) (string, string) {
+	t.Helper()
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	mustWriteFixtureFile(t, secretFile, secret)
+	out := filepath.Join(t.TempDir(), "anon")
+
+	args := append([]string{"-dir", dir, "-out", out, "-secret", secretFile}, extra...)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookAnonymize, args)
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	return out, stdout
+}
+
+func TestPublicGradebookAnonymize(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtrasFixture(t)
+	keyFile := filepath.Join(t.TempDir(), "key.csv")
+	out, stdout := anonymizeFixture(t, dir, "s3cret\n", "-key", keyFile)
+
+	wantStdout := "Wrote an anonymized class with 1 gradebook file(s) to " + out + "\n" +
+		"Wrote the key to " + keyFile + "\n"
+	if stdout != wantStdout {
+		t.Errorf("stdout = %q; want %q", stdout, wantStdout)
+	}
+
+	for _, name := range []string{suiteClassFile, "quiz-quiz-1-20240319.gradebook"} {
+		data, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		for _, secret := range []string{"alice", "bob", "Zephyr", "Young", "1001", "Ali"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s still contains %q", name, secret)
+			}
+		}
+	}
+
+	// The anonymized class is a valid class with the same grades.
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", out, "-section", "B"})
+	if exitCode != exitSuccess {
+		t.Fatalf("calc exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if !strings.HasPrefix(stdout, "Student ") || !strings.Contains(stdout, "Overall average: 90\n") {
+		t.Errorf("calc stdout = %q; want Bob's grades under a pseudonym", stdout)
+	}
+
+	fh, err := os.Open(keyFile)
+	if err != nil {
+		t.Fatalf("failed to open key: %v", err)
+	}
+	defer fh.Close()
+	info, err := fh.Stat()
+	if err != nil {
+		t.Fatalf("failed to stat key: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("key permissions = %o; want 600", perm)
+	}
+	rows, err := csv.NewReader(fh).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read key: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("key has %d rows; want 4", len(rows))
+	}
+	byEmail := make(map[string]string)
+	for _, row := range rows[1:] {
+		byEmail[row[1]] = row[0]
+	}
+	bobPseudonym := byEmail["bob@example.com"]
+	if !strings.HasPrefix(bobPseudonym, "student-") {
+		t.Errorf("bob's pseudonym = %q; want a student- pseudonym", bobPseudonym)
+	}
+	if !strings.Contains(stdout, anonPseudonymID(bobPseudonym)) {
+		t.Errorf("calc stdout = %q; want it to name %q", stdout, anonPseudonymID(bobPseudonym))
+	}
+
+	// Apart from the emails, the gradebook file comes through unchanged.
+	gbData, err := os.ReadFile(filepath.Join(out, "quiz-quiz-1-20240319.gradebook"))
+	if err != nil {
+		t.Fatalf("failed to read anonymized gradebook file: %v", err)
+	}
+	wantGB := strings.NewReplacer(
+		"bob@example.com", bobPseudonym,
+		"alice@example.com", byEmail["alice@example.com"],
+	).Replace(gradebookFixtureJSON) + "\n"
+	if string(gbData) != wantGB {
+		t.Errorf("anonymized gradebook file = %s; want %s", gbData, wantGB)
+	}
+}
+
+func TestPublicGradebookAnonymizeIsStable(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	classData := func(out string) string {
+		t.Helper()
+
+		data, err := os.ReadFile(filepath.Join(out, suiteClassFile))
+		if err != nil {
+			t.Fatalf("failed to read class: %v", err)
+		}
+
+		return string(data)
+	}
+
+	first, _ := anonymizeFixture(t, dir, "one secret")
+	again, _ := anonymizeFixture(t, dir, "one secret")
+	other, _ := anonymizeFixture(t, dir, "another secret")
+
+	if classData(first) != classData(again) {
+		t.Error("the same secret gave different pseudonyms")
+	}
+	if classData(first) == classData(other) {
+		t.Error("different secrets gave the same pseudonyms")
+	}
+}
+
+func TestAnonymizeGradebookFileSortsRecords(t *testing.T) {
+	t.Parallel()
+
+	// The records are in roster order, and the pseudonyms sort differently.
+	const gbData = `{
+    "assignment_name": "t1",
+    "assignment_records": [
+        {"email": "alice@example.com", "grade": 90},
+        {"email": "bob@example.com", "grade": 80},
+        {"email": "carol@example.com", "grade": 70}
+    ]
+}`
+	pseudonyms := map[string]string{
+		"alice@example.com": "student-c@example.invalid",
+		"bob@example.com":   "student-a@example.invalid",
+		"carol@example.com": "student-b@example.invalid",
+	}
+
+	data, err := anonymizeGradebookFile([]byte(gbData), pseudonyms)
+	if err != nil {
+		t.Fatalf("anonymizeGradebookFile() error = %v", err)
+	}
+
+	var gbf gradebookFile
+	if err = json.Unmarshal(data, &gbf); err != nil {
+		t.Fatalf("failed to unmarshal anonymized file: %v", err)
+	}
+	got := make([]string, 0, len(gbf.AssignmentRecords))
+	for _, ar := range gbf.AssignmentRecords {
+		got = append(got, fmt.Sprintf("%s %s", ar.Email, formatScore(*ar.Grade)))
+	}
+	want := []string{"student-a@example.invalid 80", "student-b@example.invalid 70", "student-c@example.invalid 90"}
+	if !slices.Equal(got, want) {
+		t.Errorf("records = %q; want %q", got, want)
+	}
+}
+
+func TestPublicGradebookAnonymizeErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	secretFile := filepath.Join(dir, "secret")
+	mustWriteFixtureFile(t, secretFile, "s3cret")
+	emptySecret := filepath.Join(dir, "empty-secret")
+	mustWriteFixtureFile(t, emptySecret, "  \n")
+
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"missing out": {
+			args: []string{"-dir", dir, "-secret", secretFile},
+			want: "-out is required",
+		},
+		"missing secret": {
+			args: []string{"-dir", dir, "-out", filepath.Join(dir, "anon")},
+			want: "-secret is required",
+		},
+		"empty secret": {
+			args: []string{"-dir", dir, "-out", filepath.Join(dir, "anon"), "-secret", emptySecret},
+			want: "is empty",
+		},
+		"existing files": {
+			args: []string{"-dir", dir, "-out", dir, "-secret", secretFile},
+			want: "already exists",
+		},
+	}
+
+	for msg, tc := range testCases {
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+
+			exitCode, _, stderr := runPublicCommand(t, GradebookAnonymize, tc.args)
+			if exitCode != exitFailure {
+				t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+			}
+			if !strings.Contains(stderr, tc.want) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	archiveFormat       = "gradebook-archive"
+	archiveVersion      = 1
+	archiveManifestName = "manifest.json"
+	archiveFinalGrades  = "final-grades.csv"
+)
+
+// archiveManifest describes an archive from gradebook-archive. Every other
+// file in the archive is listed in Files with its SHA-256 checksum, and an
+// archive that does not match its manifest cannot be read.
+type archiveManifest struct {
+	Format       string         `json:"format"`
+	Version      int            `json:"version"`
+	Class        string         `json:"class"`
+	Term         string         `json:"term"`
+	Start        string         `json:"start"`
+	End          string         `json:"end"`
+	Created      string         `json:"created"`
+	SuiteVersion string         `json:"suite_version"`
+	Files        []archiveEntry `json:"files"`
+}
+
+type archiveEntry struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+}
+
+// GradebookArchive bundles a term's class file, gradebook files, and final
+// grades into a single checksummed archive.
+func GradebookArchive(args []string) int {
+	cmd := cmdFrom("gradebook-archive", archiveUsage)
+
+	return runCommand(cmd, args, commandRun[archiveCfg]{
+		parse:     (*cmdEnv).parseArchive,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg archiveCfg) {
+			cmd.findTerm(class, cfg.term)
+			gbFiles := cmd.loadGrades(class, cfg.term)
+			data := cmd.buildArchive(class, gbFiles, cfg.term)
+			cmd.writeArchive(cfg.out, data, len(gbFiles), cfg.term)
+		},
+	})
+}
+
+type archiveCfg struct {
+	term string
+	out  string
+}
+
+func (cmd *cmdEnv) parseArchive(args []string) archiveCfg {
+	var cfg archiveCfg
+
+	og := cmd.commonOptsGroup(parseOpts{})
+	og.String(&cfg.term, "term", "")
+	og.String(&cfg.out, "out", "")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+	if cmd.noOp() {
+		return cfg
+	}
+
+	switch {
+	case cfg.term == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -term is required\n", cmd.name)
+	case cfg.out == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -out is required\n", cmd.name)
+	}
+
+	return cfg
+}
+
+// buildArchive returns a zip archive of the class file, the term's gradebook
+// files, the final grades as CSV, and a manifest of them all. The grades in
+// gbFiles must already be added to class.
+func (cmd *cmdEnv) buildArchive(class *gradebook.Class, gbFiles []*gradebookFile, term string) []byte {
+	if cmd.noOp() {
+		return nil
+	}
+
+	data, err := cmd.archiveContents(class, gbFiles, term)
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem building archive: %s\n", cmd.name, err)
+
+		return nil
+	}
+
+	return data
+}
+
+func (cmd *cmdEnv) archiveContents(class *gradebook.Class, gbFiles []*gradebookFile, term string) ([]byte, error) {
+	// Files go into the archive as they are on disk, so an encrypted class
+	// stays encrypted.
+	store := dirStore{dir: cmd.directory}
+	manifest := archiveManifest{
+		Format:       archiveFormat,
+		Version:      archiveVersion,
+		Class:        class.Name,
+		Term:         term,
+		Start:        class.TermsByID[term].Start,
+		End:          class.TermsByID[term].End,
+		Created:      cmd.today,
+		SuiteVersion: cmd.version,
+	}
+
+	// Every file is stamped with the creation date rather than the time of
+	// day, so that archiving the same files on the same day gives the same
+	// bytes.
+	modified, err := time.Parse(dateLayout, cmd.today)
+	if err != nil {
+		return nil, fmt.Errorf("invalid creation date %q: %w", cmd.today, err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name string, data []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return fmt.Errorf("add %q: %w", name, err)
+		}
+		if _, err = w.Write(data); err != nil {
+			return fmt.Errorf("write %q: %w", name, err)
+		}
+		if name != archiveManifestName {
+			sum := sha256.Sum256(data)
+			manifest.Files = append(manifest.Files, archiveEntry{
+				Name:   name,
+				SHA256: hex.EncodeToString(sum[:]),
+				Size:   len(data),
+			})
+		}
+
+		return nil
+	}
+
+	data, err := store.readFile(cmd.classFile)
+	if err != nil {
+		return nil, fmt.Errorf("read class file %q: %w", cmd.classFile, err)
+	}
+	if err = add(suiteClassFile, data); err != nil {
+		return nil, err
+	}
+
+	for _, gbf := range sortedByDate(gbFiles) {
+		data, err := store.readFile(gbf.path)
+		if err != nil {
+			return nil, fmt.Errorf("read gradebook file %q: %w", gbf.path, err)
+		}
+		if err = add(filepath.Base(gbf.path), data); err != nil {
+			return nil, err
+		}
+	}
+
+	var grades bytes.Buffer
+	if err = writeFinalGrades(&grades, cmd.lmsGrades(class, gbFiles)); err != nil {
+		return nil, fmt.Errorf("write final grades: %w", err)
+	}
+	data, err = cmd.sealLikeClass(grades.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if err = add(archiveFinalGrades, data); err != nil {
+		return nil, err
+	}
+
+	data, err = marshalJSONFile(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err = add(archiveManifestName, data); err != nil {
+		return nil, err
+	}
+
+	if err = zw.Close(); err != nil {
+		return nil, fmt.Errorf("close zip archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeFinalGrades writes each student's category averages and overall
+// average, with any extra credit, as CSV.
+func writeFinalGrades(buf *bytes.Buffer, grades *lmsGrades) error {
+	header := []string{"Email", "Last Name", "First Name", "Student ID", "Section"}
+	header = append(header, grades.labels...)
+	header = append(header, "Overall")
+
+	rows := [][]string{header}
+	for _, ls := range grades.students {
+		row := []string{ls.email, ls.lastName, ls.firstName, ls.studentID, ls.section}
+		for _, avg := range ls.averages {
+			row = append(row, lmsAverage(avg, ""))
+		}
+		row = append(row, lmsAverage(ls.overall, ""))
+		rows = append(rows, row)
+	}
+
+	return csv.NewWriter(buf).WriteAll(rows)
+}
+
+// writeArchive writes a new archive. It never replaces an existing file, so
+// an archive, once written, stays as it was.
+func (cmd *cmdEnv) writeArchive(out string, data []byte, count int, term string) {
+	if cmd.noOp() {
+		return
+	}
+
+	if err := writeFile(out, data); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem writing archive: %s\n", cmd.name, err)
+
+		return
+	}
+
+	fmt.Fprintf(cmd.stdout, "Archived %d gradebook file(s) from term %q to %s\n", count, term, out)
+}
+
+// openArchiveStore opens an archive from gradebook-archive and checks every
+// file in it against the manifest.
+func openArchiveStore(archive string) (classStore, error) {
+	zs, err := openZipStore(archive, archiveManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a gradebook archive: %w", archive, err)
+	}
+
+	data, err := zs.readFile(archiveManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest in %q: %w", archive, err)
+	}
+	var manifest archiveManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest in %q: %w", archive, err)
+	}
+	if manifest.Format != archiveFormat {
+		return nil, fmt.Errorf("%q is not a gradebook archive: manifest format is %q", archive, manifest.Format)
+	}
+	if manifest.Version != archiveVersion {
+		return nil, fmt.Errorf("archive %q has unsupported version %d", archive, manifest.Version)
+	}
+
+	ms := memStore{files: make(map[string][]byte, len(manifest.Files))}
+	var errs []error
+	for _, entry := range manifest.Files {
+		data, err := zs.readFile(entry.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read %q: %w", entry.Name, err))
+
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != entry.SHA256 {
+			errs = append(errs, fmt.Errorf("checksum mismatch for %q", entry.Name))
+
+			continue
+		}
+		ms.files[entry.Name] = data
+	}
+
+	names, err := zs.gradebookFiles()
+	if err != nil {
+		return nil, fmt.Errorf("read archive %q: %w", archive, err)
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(manifest.Files, func(entry archiveEntry) bool { return entry.Name == name }) {
+			errs = append(errs, fmt.Errorf("%q is not in the manifest", name))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("archive %q failed verification: %w", archive, err)
+	}
+
+	return ms, nil
+}
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeArchiveFixture writes the trend fixture with two terms: q1 holds the
+// quiz and q2 holds the test.
+func writeArchiveFixture(t *testing.T) string {
+	t.Helper()
+
+	dir := writeTrendFixture(t)
+	mustWriteFixtureFile(t, filepath.Join(dir, suiteClassFile), classFixtureWith(t, `{
+    "terms_by_id": {
+        "q1": {
+            "start": "20240101",
+            "end": "20240331"
+        },
+        "q2": {
+            "start": "20240401",
+            "end": "20240630"
+        }
+    }
+}`))
+
+	return dir
+}
+
+func archiveFixture(t *testing.T, dir, term string) string {
+	t.Helper()
+
+	out := filepath.Join(t.TempDir(), "q.zip")
+	exitCode, _, stderr := runPublicCommand(t, GradebookArchive, []string{"-dir", dir, "-term", term, "-out", out})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	return out
+}
+
+func TestPublicGradebookArchive(t *testing.T) {
+	t.Parallel()
+
+	dir := writeArchiveFixture(t)
+	out := filepath.Join(t.TempDir(), "q1.zip")
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookArchive, []string{"-dir", dir, "-term", "q1", "-out", out})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if want := `Archived 1 gradebook file(s) from term "q1" to ` + out + "\n"; stdout != want {
+		t.Errorf("stdout = %q; want %q", stdout, want)
+	}
+
+	zr, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", out, err)
+	}
+	defer zr.Close()
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Method != zip.Deflate {
+			t.Errorf("%s is not compressed", f.Name)
+		}
+	}
+	wantNames := []string{suiteClassFile, "quiz-quiz-1-20240319.gradebook", archiveFinalGrades, archiveManifestName}
+	if !slices.Equal(names, wantNames) {
+		t.Errorf("archive files = %q; want %q", names, wantNames)
+	}
+
+	var manifest archiveManifest
+	if err = json.Unmarshal([]byte(readZipEntry(t, out, archiveManifestName)), &manifest); err != nil {
+		t.Fatalf("failed to unmarshal manifest: %v", err)
+	}
+	if manifest.Term != "q1" || manifest.Start != "20240101" || manifest.End != "20240331" {
+		t.Errorf("manifest term = %q (%s-%s); want q1 (20240101-20240331)", manifest.Term, manifest.Start, manifest.End)
+	}
+	if len(manifest.Files) != 3 {
+		t.Errorf("manifest lists %d files; want 3", len(manifest.Files))
+	}
+
+	wantGrades := "Email,Last Name,First Name,Student ID,Section,Major,Minor,Participation,Overall\n" +
+		"bob@example.com,Young,Bob,,,,90,,90\n" +
+		"alice@example.com,Zephyr,Alice,,,,,,\n"
+	if got := readZipEntry(t, out, archiveFinalGrades); got != wantGrades {
+		t.Errorf("final grades = %q; want %q", got, wantGrades)
+	}
+}
+
+func TestArchiveIsReproducible(t *testing.T) {
+	t.Parallel()
+
+	dir := writeArchiveFixture(t)
+	build := func() []byte {
+		t.Helper()
+
+		var stdout, stderr bytes.Buffer
+		cmd := cmdFromWithWriters("gradebook-archive", archiveUsage, &stdout, &stderr)
+		cmd.today = "20240501"
+		cfg := cmd.parseArchive([]string{"-dir", dir, "-term", "q1", "-out", "unused.zip"})
+		cmd.resolvePaths()
+		class := cmd.unmarshalClass()
+		data := cmd.buildArchive(class, cmd.loadGrades(class, cfg.term), cfg.term)
+		if cmd.exitValue != exitSuccess {
+			t.Fatalf("exitValue = %d; want %d (stderr: %q)", cmd.exitValue, exitSuccess, stderr.String())
+		}
+
+		return data
+	}
+
+	first := build()
+	if !bytes.Equal(first, build()) {
+		t.Error("archiving the same files on the same day gave different bytes")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	want := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	for _, f := range zr.File {
+		if !f.Modified.Equal(want) {
+			t.Errorf("%s modified = %v; want %v", f.Name, f.Modified, want)
+		}
+	}
+}
+
+func TestPublicReadOnlyCommandsReadArchive(t *testing.T) {
+	t.Parallel()
+
+	dir := writeArchiveFixture(t)
+	archive := archiveFixture(t, dir, "q2")
+
+	testCases := map[string]func([]string) int{
+		"calc":     GradebookCalc,
+		"names":    GradebookNames,
+		"emails":   GradebookEmails,
+		"unscored": GradebookUnscored,
+	}
+
+	for msg, cmdFunc := range testCases {
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+
+			args := []string{"-dir", dir}
+			if msg == "calc" || msg == "unscored" {
+				args = append(args, "-term", "q2")
+			}
+			exitCode, want, stderr := runPublicCommand(t, cmdFunc, args)
+			if exitCode != exitSuccess {
+				t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+			}
+
+			exitCode, got, stderr := runPublicCommand(t, cmdFunc, []string{"-dir", archive})
+			if exitCode != exitSuccess {
+				t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+			}
+			if got != want {
+				t.Errorf("stdout = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPublicGradebookArchiveRejectsTampering(t *testing.T) {
+	t.Parallel()
+
+	dir := writeArchiveFixture(t)
+	archive := archiveFixture(t, dir, "q1")
+
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	fh, err := os.Create(tampered)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", tampered, err)
+	}
+	zw := zip.NewWriter(fh)
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", archive, err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		data := readZipEntry(t, archive, f.Name)
+		if strings.HasSuffix(f.Name, gradebookSuffix) {
+			data = strings.Replace(data, `"grade": 90`, `"grade": 100`, 1)
+		}
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", f.Name, err)
+		}
+		if _, err = w.Write([]byte(data)); err != nil {
+			t.Fatalf("failed to write %s: %v", f.Name, err)
+		}
+	}
+	w, err := zw.Create("test-test-2-20240320.gradebook")
+	if err != nil {
+		t.Fatalf("failed to add extra gradebook: %v", err)
+	}
+	if _, err = w.Write([]byte(alertsTestGradebookJSON)); err != nil {
+		t.Fatalf("failed to write extra gradebook: %v", err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatalf("failed to close zip writer: %v", err)
+	}
+	if err = fh.Close(); err != nil {
+		t.Fatalf("failed to close %s: %v", tampered, err)
+	}
+
+	exitCode, _, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", tampered})
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	for _, want := range []string{
+		`checksum mismatch for "quiz-quiz-1-20240319.gradebook"`,
+		`"test-test-2-20240320.gradebook" is not in the manifest`,
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr = %q; want it to contain %q", stderr, want)
+		}
+	}
+}
+
+func TestPublicGradebookArchiveErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := writeArchiveFixture(t)
+	existing := filepath.Join(dir, "existing.zip")
+	mustWriteFixtureFile(t, existing, "")
+	plainZip := zipFixture(t, dir, "")
+
+	testCases := map[string]struct {
+		cmdFunc func([]string) int
+		args    []string
+		want    string
+	}{
+		"missing term": {
+			cmdFunc: GradebookArchive,
+			args:    []string{"-dir", dir, "-out", filepath.Join(dir, "q1.zip")},
+			want:    "-term is required",
+		},
+		"missing out": {
+			cmdFunc: GradebookArchive,
+			args:    []string{"-dir", dir, "-term", "q1"},
+			want:    "-out is required",
+		},
+		"invalid term": {
+			cmdFunc: GradebookArchive,
+			args:    []string{"-dir", dir, "-term", "q9", "-out", filepath.Join(dir, "q9.zip")},
+			want:    `"q9" is not a valid term`,
+		},
+		"existing archive": {
+			cmdFunc: GradebookArchive,
+			args:    []string{"-dir", dir, "-term", "q1", "-out", existing},
+			want:    "file exists",
+		},
+		"not a gradebook archive": {
+			cmdFunc: GradebookCalc,
+			args:    []string{"-dir", plainZip},
+			want:    "is not a gradebook archive",
+		},
+	}
+
+	for msg, tc := range testCases {
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+
+			exitCode, _, stderr := runPublicCommand(t, tc.cmdFunc, tc.args)
+			if exitCode != exitFailure {
+				t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+			}
+			if !strings.Contains(stderr, tc.want) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr, tc.want)
+			}
+		})
+	}
+}
@@ -15,14 +15,15 @@ func GradebookCalc(args []string) int {
 		loadClass: true,
 		action: func(cmd *cmdEnv, class *gradebook.Class, term string) {
 			cmd.findTerm(class, term)
-			cmd.loadGrades(class, term)
-			cmd.printAll(class)
+			cmd.findSection()
+			gbFiles := cmd.loadGrades(class, term)
+			cmd.printAll(class, gbFiles)
 		},
 	})
 }
 
 func (cmd *cmdEnv) parseCalculate(args []string) string {
-	og := cmd.commonOptsGroup(parseOpts{})
+	og := cmd.commonOptsGroup(parseOpts{section: true, store: true, watch: true})
 
 	term := ""
 	og.String(&term, "term", "")
@@ -49,31 +50,46 @@ func (cmd *cmdEnv) findTerm(class *gradebook.Class, term string) {
 	}
 }
 
-func (cmd *cmdEnv) loadGrades(class *gradebook.Class, term string) {
+func (cmd *cmdEnv) loadGrades(class *gradebook.Class, term string) []*gradebookFile {
+	gbFiles := cmd.readGradebooks(class, term)
 	if cmd.noOp() {
-		return
+		return nil
 	}
 
-	err := class.LoadGrades(cmd.directory, class.TermsByID[term])
-	if err != nil {
-		cmd.exitValue = exitFailure
-		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
-	}
+	addGrades(class, cmd.extras, gbFiles, cmd.today)
+
+	return gbFiles
 }
 
-func (cmd *cmdEnv) printAll(class *gradebook.Class) {
+func (cmd *cmdEnv) printAll(class *gradebook.Class, gbFiles []*gradebookFile) {
 	if cmd.noOp() {
 		return
 	}
 
-	students := class.StudentsSortedByName()
-	for _, s := range students {
+	bonusByEmail := cmd.extras.extraCreditPoints(gbFiles)
+	missingByEmail := countMissing(class, cmd.extras, gbFiles, cmd.today)
+
+	for _, email := range cmd.emailsInSection(class) {
+		s := class.StudentsByEmail[email]
 		fmt.Fprintf(cmd.stdout, "%s %s\n", s.FirstName, s.LastName)
 
-		fmt.Fprintf(cmd.stdout, "\tOverall average: %s\n", s.TotalAverage(class.WeightsByAssignmentCategory))
+		total := s.TotalAverage(class.WeightsByAssignmentCategory)
+		fmt.Fprintf(cmd.stdout, "\tOverall average: %s\n", total)
+
+		if cmd.extras.hasExtraCredit() {
+			bonus := bonusByEmail[email]
+			fmt.Fprintf(cmd.stdout, "\tExtra credit: +%s\n", formatScore(bonus))
+			fmt.Fprintf(cmd.stdout, "\tOverall with extra credit: %s\n", withBonus(total, bonus))
+		}
 
 		for _, cat := range class.AssignmentCategoriesSortedByLabel() {
-			fmt.Fprintf(cmd.stdout, "\t%s: %s\n", class.LabelsByAssignmentCategory[cat], s.Average(cat))
+			label := class.LabelsByAssignmentCategory[cat]
+			if n := missingByEmail[email][cat]; n > 0 {
+				fmt.Fprintf(cmd.stdout, "\t%s: %s (%d missing)\n", label, s.Average(cat), n)
+
+				continue
+			}
+			fmt.Fprintf(cmd.stdout, "\t%s: %s\n", label, s.Average(cat))
 		}
 	}
 }
@@ -0,0 +1,443 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telemachus/gradebook"
+)
+
+// In a Google Classroom grade CSV, every column after the email column is an
+// assignment, and the row that gives each assignment's points is labeled
+// "Points".
+const (
+	classroomEmail  = "Email Address"
+	classroomPoints = "Points"
+)
+
+// classroomDateLayouts are the date formats that may appear in the date row
+// of a Google Classroom grade CSV.
+var classroomDateLayouts = []string{"Jan 2, 2006", "January 2, 2006", "1/2/2006", "2006-01-02"}
+
+// GradebookClassroom creates gradebook files from a Google Classroom grade
+// CSV.
+func GradebookClassroom(args []string) int {
+	cmd := cmdFrom("gradebook-classroom", classroomUsage)
+
+	return runCommand(cmd, args, commandRun[classroomCfg]{
+		parse:     (*cmdEnv).parseClassroom,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg classroomCfg) {
+			if cfg.gbType != "" {
+				isValidType(cmd, cfg.gbType, class)
+			}
+			sheet := cmd.readClassroom(class, cfg.file)
+			gbFiles := cmd.classroomGradebooks(class, sheet, cfg)
+			cmd.printClassroomImport(sheet, gbFiles)
+			if !cmd.confirmClassroomImport(gbFiles, cfg.yes) {
+				return
+			}
+			cmd.writeClassroomGradebooks(gbFiles)
+		},
+	})
+}
+
+type classroomCfg struct {
+	subcommand string
+	file       string
+	gbType     string
+	gbDate     string
+	yes        bool
+}
+
+func (cmd *cmdEnv) parseClassroom(args []string) classroomCfg {
+	var cfg classroomCfg
+	cfg.subcommand, args = splitSubcommand(args)
+
+	og := cmd.commonOptsGroup(parseOpts{})
+	og.String(&cfg.file, "file", "")
+	og.String(&cfg.gbType, "type", "")
+	og.String(&cfg.gbDate, "date", "")
+	og.Bool(&cfg.yes, "yes")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkSubcommand(cfg.subcommand, "import")
+	if cmd.noOp() {
+		return cfg
+	}
+
+	if cfg.file == "" {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -file is required\n", cmd.name)
+	}
+	if cfg.gbDate == "" {
+		cfg.gbDate = cmd.today
+	}
+	isValidDate(cmd, cfg.gbDate)
+
+	return cfg
+}
+
+// classroomColumn is one assignment column of a Google Classroom grade CSV.
+// Grades are percentages of the column's points.
+type classroomColumn struct {
+	title  string
+	date   string
+	grades map[string]float64
+}
+
+type classroomSheet struct {
+	columns     []*classroomColumn
+	skippedRows []string
+}
+
+func (cmd *cmdEnv) readClassroom(class *gradebook.Class, file string) *classroomSheet {
+	if cmd.noOp() {
+		return nil
+	}
+
+	sheet, err := readClassroomCSV(class, file)
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return nil
+	}
+
+	return sheet
+}
+
+// readClassroomCSV reads a Google Classroom grade CSV. After the header, rows
+// without an email address describe the assignments: a row labeled "Points"
+// gives each assignment's points, and a row of dates gives each assignment's
+// date. Without a Points row, every assignment is out of 100 points. Rows for
+// students who are not in class are skipped.
+func readClassroomCSV(class *gradebook.Class, file string) (sheet *classroomSheet, err error) {
+	fh, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return nil, fmt.Errorf("open Classroom CSV %q: %w", file, err)
+	}
+	defer func() {
+		if closeErr := fh.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close Classroom CSV %q: %w", file, closeErr))
+		}
+	}()
+
+	rdr := csv.NewReader(fh)
+	rdr.FieldsPerRecord = -1
+
+	header, err := rdr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header of Classroom CSV %q: %w", file, err)
+	}
+	for i, h := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+	emailIdx := slices.Index(header, classroomEmail)
+	if emailIdx < 0 {
+		return nil, fmt.Errorf("missing column %q in Classroom CSV %q", classroomEmail, file)
+	}
+
+	var rows [][]string
+	points := make([]float64, len(header))
+	for i := range points {
+		points[i] = 100
+	}
+	dates := make([]string, len(header))
+	sheet = &classroomSheet{}
+	for {
+		row, err := rdr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read Classroom CSV %q: %w", file, err)
+		}
+
+		line, _ := rdr.FieldPos(0)
+		email := ""
+		if emailIdx < len(row) {
+			email = strings.TrimSpace(row[emailIdx])
+		}
+		switch {
+		case email != "" && class.StudentsByEmail[email] != nil:
+			rows = append(rows, row)
+		case email != "":
+			sheet.skippedRows = append(sheet.skippedRows, email)
+		case strings.EqualFold(strings.TrimSpace(row[0]), classroomPoints):
+			for i := emailIdx + 1; i < len(row) && i < len(header); i++ {
+				if strings.TrimSpace(row[i]) == "" {
+					continue
+				}
+				p, err := strconv.ParseFloat(strings.TrimSpace(row[i]), 64)
+				if err != nil || p <= 0 {
+					return nil, fmt.Errorf("invalid points %q on line %d of Classroom CSV %q", row[i], line, file)
+				}
+				points[i] = p
+			}
+		default:
+			for i := emailIdx + 1; i < len(row) && i < len(header); i++ {
+				if date, ok := classroomDate(row[i]); ok {
+					dates[i] = date
+				}
+			}
+		}
+	}
+
+	for i := emailIdx + 1; i < len(header); i++ {
+		col := &classroomColumn{title: header[i], date: dates[i], grades: make(map[string]float64)}
+		for _, row := range rows {
+			if i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				continue
+			}
+
+			email := strings.TrimSpace(row[emailIdx])
+			score, err := strconv.ParseFloat(strings.TrimSpace(row[i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid score %q for %q in column %q of Classroom CSV %q", row[i], email, col.title, file)
+			}
+			col.grades[email] = math.Round(score/points[i]*100*100) / 100
+		}
+		sheet.columns = append(sheet.columns, col)
+	}
+
+	return sheet, nil
+}
+
+// classroomDate converts a date from a Google Classroom CSV to YYYYMMDD.
+func classroomDate(s string) (string, bool) {
+	for _, layout := range classroomDateLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t.Format(dateLayout), true
+		}
+	}
+
+	return "", false
+}
+
+// classroomGradebooks builds a gradebook file for each assignment column. Every
+// student in the class gets a record, and students without a grade in the
+// column are unscored.
+func (cmd *cmdEnv) classroomGradebooks(
+	class *gradebook.Class,
+	sheet *classroomSheet,
+	cfg classroomCfg,
+) []*gradebookFile {
+	if cmd.noOp() {
+		return nil
+	}
+
+	gbFiles := make([]*gradebookFile, 0, len(sheet.columns))
+	seen := make(map[string]bool, len(sheet.columns))
+	for _, col := range sheet.columns {
+		gbName := classroomName(col.title)
+		if gbName == "" {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: cannot make an assignment name from %q\n", cmd.name, col.title)
+
+			return nil
+		}
+
+		gbType := cmp.Or(cfg.gbType, cmd.inferType(class, col.title))
+		if gbType == "" {
+			gbType = cmd.askType(class, col.title, cfg.yes)
+		}
+		if cmd.noOp() {
+			return nil
+		}
+
+		category := class.CategoriesByAssignmentType[gbType]
+		if cmd.extras.isExtraCreditType(gbType) {
+			category = extraCreditCategory
+		}
+
+		gbf := &gradebookFile{
+			AssignmentCategory: category,
+			AssignmentDate:     cmp.Or(col.date, cfg.gbDate),
+			AssignmentName:     gbName,
+			AssignmentType:     gbType,
+		}
+		for _, email := range class.EmailsSortedByStudentName() {
+			ar := &assignmentRecord{Email: email}
+			if grade, ok := col.grades[email]; ok {
+				ar.Grade = &grade
+			}
+			gbf.AssignmentRecords = append(gbf.AssignmentRecords, ar)
+		}
+
+		fileName := fmt.Sprintf("%s-%s-%s%s", gbf.AssignmentType, gbf.AssignmentName, gbf.AssignmentDate, gradebookSuffix)
+		gbf.path = filepath.Join(cmd.directory, fileName)
+		if _, err := os.Stat(gbf.path); err == nil || seen[gbf.path] {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: %q already exists\n", cmd.name, gbf.path)
+
+			return nil
+		}
+		seen[gbf.path] = true
+
+		gbFiles = append(gbFiles, gbf)
+	}
+
+	return gbFiles
+}
+
+// classroomName converts an assignment title to an assignment name that
+// gradebook-new would accept: lowercase, with every run of characters outside
+// [A-Za-z0-9._-] replaced by a hyphen.
+func classroomName(title string) string {
+	name := invalidGbNameRegex.ReplaceAllString(strings.ToLower(strings.TrimSpace(title)), "-")
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+
+	return strings.Trim(name, "-")
+}
+
+// inferType returns the assignment type named in title, such as "quiz" in
+// "Quiz 3: Fractions". It returns "" unless exactly one type matches a word
+// of the title.
+func (cmd *cmdEnv) inferType(class *gradebook.Class, title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+
+	var found []string
+	for _, gbType := range cmd.assignmentTypes(class) {
+		if slices.Contains(words, strings.ToLower(gbType)) || slices.Contains(words, strings.ToLower(gbType)+"s") {
+			found = append(found, gbType)
+		}
+	}
+	if len(found) != 1 {
+		return ""
+	}
+
+	return found[0]
+}
+
+// assignmentTypes returns every assignment type of the class, including extra
+// credit types, in sorted order.
+func (cmd *cmdEnv) assignmentTypes(class *gradebook.Class) []string {
+	gbTypes := slices.Collect(maps.Keys(class.CategoriesByAssignmentType))
+	if cmd.extras.hasExtraCredit() {
+		gbTypes = append(gbTypes, cmd.extras.ExtraCredit.AssignmentTypes...)
+	}
+	slices.Sort(gbTypes)
+
+	return slices.Compact(gbTypes)
+}
+
+// askType asks for the assignment type of a column whose type cannot be
+// inferred. With -yes, there is no one to ask, so the import fails.
+func (cmd *cmdEnv) askType(class *gradebook.Class, title string, yes bool) string {
+	gbTypes := cmd.assignmentTypes(class)
+	if yes {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: cannot infer the type of %q; use -type\n", cmd.name, title)
+
+		return ""
+	}
+
+	fmt.Fprintf(cmd.stdout, "Type for %q (%s): ", title, strings.Join(gbTypes, ", "))
+	answer, err := cmd.readLine()
+	if err != nil && !errors.Is(err, io.EOF) {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem reading type: %s\n", cmd.name, err)
+
+		return ""
+	}
+
+	gbType := strings.TrimSpace(answer)
+	if !slices.Contains(gbTypes, gbType) {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: invalid type for %q: %q\n", cmd.name, title, gbType)
+
+		return ""
+	}
+
+	return gbType
+}
+
+func (cmd *cmdEnv) printClassroomImport(sheet *classroomSheet, gbFiles []*gradebookFile) {
+	if cmd.noOp() {
+		return
+	}
+
+	if len(sheet.skippedRows) > 0 {
+		fmt.Fprintln(cmd.stdout, "Skipped students not in class:")
+		for _, email := range sheet.skippedRows {
+			fmt.Fprintf(cmd.stdout, "\t%s\n", email)
+		}
+	}
+
+	if len(gbFiles) == 0 {
+		fmt.Fprintln(cmd.stdout, "No assignments to import")
+
+		return
+	}
+
+	fmt.Fprintln(cmd.stdout, "New gradebook files:")
+	for i, gbf := range gbFiles {
+		scored := 0
+		for _, ar := range gbf.AssignmentRecords {
+			if ar.Grade != nil {
+				scored++
+			}
+		}
+		fmt.Fprintf(
+			cmd.stdout,
+			"\t%s (%q, %d of %d scored)\n",
+			filepath.Base(gbf.path),
+			sheet.columns[i].title,
+			scored,
+			len(gbf.AssignmentRecords),
+		)
+	}
+}
+
+func (cmd *cmdEnv) confirmClassroomImport(gbFiles []*gradebookFile, yes bool) bool {
+	if cmd.noOp() || len(gbFiles) == 0 {
+		return false
+	}
+
+	return cmd.confirm(fmt.Sprintf("Write %d gradebook file(s)?", len(gbFiles)), yes)
+}
+
+func (cmd *cmdEnv) writeClassroomGradebooks(gbFiles []*gradebookFile) {
+	if cmd.noOp() {
+		return
+	}
+
+	for _, gbf := range gbFiles {
+		data, err := marshalJSONFile(gbf)
+		if err == nil {
+			data, err = cmd.sealLikeClass(data)
+		}
+		if err == nil {
+			err = writeFile(gbf.path, data)
+		}
+		if err != nil {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: problem writing %q: %s\n", cmd.name, gbf.path, err)
+
+			return
+		}
+	}
+}
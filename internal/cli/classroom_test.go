@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/telemachus/gradebook"
+)
+
+const classroomFixtureCSV = "Last Name,First Name,Email Address,Quiz 2: Fractions,Unit Test\n" +
+	",,,\"Apr 2, 2024\",\n" +
+	"Points,,,20,\n" +
+	"Zephyr,Alice,alice@example.com,17,\n" +
+	"Young,Bob,bob@example.com,18,75\n" +
+	"Doe,Dana,dana@example.com,10,50\n"
+
+func TestPublicGradebookClassroomImport(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	csvFile := filepath.Join(dir, "classroom.csv")
+	mustWriteFixtureFile(t, csvFile, classroomFixtureCSV)
+
+	args := []string{"import", "-dir", dir, "-file", csvFile, "-date", "20240405", "-yes"}
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookClassroom, args)
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	for _, want := range []string{
+		"Skipped students not in class:\n\tdana@example.com\n",
+		"\tquiz-quiz-2-fractions-20240402.gradebook (\"Quiz 2: Fractions\", 2 of 2 scored)\n",
+		"\ttest-unit-test-20240405.gradebook (\"Unit Test\", 1 of 2 scored)\n",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q; want it to contain %q", stdout, want)
+		}
+	}
+
+	class, err := gradebook.UnmarshalClass(filepath.Join(dir, suiteClassFile))
+	if err != nil {
+		t.Fatalf("failed to read class: %v", err)
+	}
+	gbFiles, err := loadGradebookFiles(dirStore{dir: dir}, class, nil, nil)
+	if err != nil {
+		t.Fatalf("loadGradebookFiles() error = %v", err)
+	}
+
+	grades := make(map[string]string)
+	for _, gbf := range gbFiles {
+		for _, ar := range gbf.AssignmentRecords {
+			grade := "unscored"
+			if ar.Grade != nil {
+				grade = formatScore(*ar.Grade)
+			}
+			grades[gbf.AssignmentName+" "+ar.Email] = grade
+		}
+	}
+	want := map[string]string{
+		"quiz-2-fractions alice@example.com": "85",
+		"quiz-2-fractions bob@example.com":   "90",
+		"unit-test alice@example.com":        "unscored",
+		"unit-test bob@example.com":          "75",
+	}
+	for key, grade := range want {
+		if grades[key] != grade {
+			t.Errorf("grade for %s = %q; want %q", key, grades[key], grade)
+		}
+	}
+}
+
+func TestPublicGradebookClassroomErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	untyped := filepath.Join(dir, "untyped.csv")
+	mustWriteFixtureFile(t, untyped, "Email Address,Essay draft\nbob@example.com,80\n")
+	existing := filepath.Join(dir, "existing.csv")
+	mustWriteFixtureFile(t, existing, "Email Address,Quiz 1\nbob@example.com,80\n")
+
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"missing file": {
+			args: []string{"import", "-dir", dir},
+			want: "gradebook-classroom: -file is required",
+		},
+		"type not inferred": {
+			args: []string{"import", "-dir", dir, "-file", untyped, "-yes"},
+			want: `gradebook-classroom: cannot infer the type of "Essay draft"; use -type`,
+		},
+		"invalid type": {
+			args: []string{"import", "-dir", dir, "-file", untyped, "-type", "essay"},
+			want: `gradebook-classroom: invalid argument for -type: "essay"`,
+		},
+		"file exists": {
+			args: []string{"import", "-dir", dir, "-file", existing, "-date", "20240319", "-yes"},
+			want: "quiz-quiz-1-20240319.gradebook\" already exists",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exitCode, _, stderr := runPublicCommand(t, GradebookClassroom, tc.args)
+			if exitCode != exitFailure {
+				t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+			}
+			if !strings.Contains(stderr, tc.want) {
+				t.Fatalf("stderr = %q; want it to contain %q", stderr, tc.want)
+			}
+		})
+	}
+}
+
+func TestClassroomName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"Quiz 3: Fractions": "quiz-3-fractions",
+		"  Lab #2 (v1.1) ":  "lab-2-v1.1",
+		"Essay_draft":       "essay_draft",
+		"???":               "",
+	}
+	for title, want := range tests {
+		if got := classroomName(title); got != want {
+			t.Errorf("classroomName(%q) = %q; want %q", title, got, want)
+		}
+	}
+}
+
+func TestInferAndAskType(t *testing.T) {
+	t.Parallel()
+
+	class := &gradebook.Class{CategoriesByAssignmentType: map[string]string{"quiz": "minor", "test": "major", "cp": "cp"}}
+
+	var stdout, stderr bytes.Buffer
+	cmd := cmdFromWithWriters("gradebook-classroom", classroomUsage, &stdout, &stderr)
+	cmd.stdin = strings.NewReader("cp\ny\n")
+
+	for title, want := range map[string]string{
+		"Quizzes":              "",
+		"Pop Quiz":             "quiz",
+		"Test and quiz review": "",
+		"Unit tests":           "test",
+	} {
+		if got := cmd.inferType(class, title); got != want {
+			t.Errorf("inferType(%q) = %q; want %q", title, got, want)
+		}
+	}
+
+	if got := cmd.askType(class, "Discussion", false); got != "cp" {
+		t.Fatalf("askType() = %q; want %q (stderr: %q)", got, "cp", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `Type for "Discussion" (cp, quiz, test): `) {
+		t.Fatalf("stdout = %q; want a prompt listing types", stdout.String())
+	}
+	// The answer to the type question must not swallow later answers.
+	if !cmd.confirm("Write?", false) {
+		t.Fatal("confirm() = false; want true")
+	}
+}
@@ -2,11 +2,17 @@
 package cli
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/telemachus/gradebook"
 	"github.com/telemachus/opts"
@@ -23,21 +29,35 @@ const (
 var invalidGbNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]`)
 
 type cmdEnv struct {
+	stdin         io.Reader
+	stdinLines    *bufio.Reader
 	stdout        io.Writer
 	stderr        io.Writer
+	extras        *classExtras
+	store         classStore
+	keys          *keyring
 	name          string
 	classFile     string
 	directory     string
+	storeKind     string
 	usage         string
 	version       string
+	section       string
+	nameStyle     string
+	today         string
 	exitValue     int
+	asOf          bool
 	lastFirst     bool
 	helpWanted    bool
 	versionWanted bool
+	watchWanted   bool
 }
 
 type parseOpts struct {
 	lastFirst bool
+	section   bool
+	store     bool
+	watch     bool
 }
 
 type noArgs struct{}
@@ -58,6 +78,8 @@ func cmdFromWithWriters(name, usage string, stdout, stderr io.Writer) *cmdEnv {
 		name:      name,
 		usage:     usage,
 		version:   suiteVersion,
+		today:     time.Now().Format(dateLayout),
+		stdin:     os.Stdin,
 		stdout:    stdout,
 		stderr:    stderr,
 	}
@@ -67,7 +89,12 @@ func runCommand[T any](cmd *cmdEnv, args []string, runCfg commandRun[T]) int {
 	parsed := runCfg.parse(cmd, args)
 	cmd.printHelpOrVersion()
 	if runCfg.loadClass {
+		if cmd.watchWanted && !cmd.noOp() {
+			return watchCommand(cmd, parsed, runCfg)
+		}
+		cmd.loadKeys("")
 		cmd.resolvePaths()
+		cmd.openStore()
 		class := cmd.unmarshalClass()
 		runCfg.action(cmd, class, parsed)
 
@@ -84,7 +111,14 @@ func (cmd *cmdEnv) parse(args []string) {
 }
 
 func (cmd *cmdEnv) parseNames(args []string) noArgs {
-	cmd.parseWithOpts(args, parseOpts{lastFirst: true})
+	cmd.parseWithOpts(args, parseOpts{lastFirst: true, section: true, store: true})
+	cmd.checkNameStyle()
+
+	return noArgs{}
+}
+
+func (cmd *cmdEnv) parseSection(args []string) noArgs {
+	cmd.parseWithOpts(args, parseOpts{section: true, store: true})
 
 	return noArgs{}
 }
@@ -95,6 +129,34 @@ func (cmd *cmdEnv) parseNoArgs(args []string) noArgs {
 	return noArgs{}
 }
 
+// splitSubcommand separates a leading subcommand (e.g., "import" in
+// "gradebook-roster import -file roster.csv") from the options that follow it.
+// If args does not begin with a subcommand, splitSubcommand returns "" and
+// args unchanged.
+func splitSubcommand(args []string) (string, []string) {
+	if len(args) == 0 || args[0] == "" || strings.HasPrefix(args[0], "-") {
+		return "", args
+	}
+
+	return args[0], args[1:]
+}
+
+func (cmd *cmdEnv) checkSubcommand(sub string, valid ...string) {
+	if cmd.noOp() {
+		return
+	}
+
+	if !slices.Contains(valid, sub) {
+		cmd.exitValue = exitFailure
+		if sub == "" {
+			fmt.Fprintf(cmd.stderr, "%s: missing subcommand\n", cmd.name)
+		} else {
+			fmt.Fprintf(cmd.stderr, "%s: unknown subcommand: %q\n", cmd.name, sub)
+		}
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+	}
+}
+
 func (cmd *cmdEnv) parseWithOpts(args []string, parseCfg parseOpts) {
 	og := cmd.commonOptsGroup(parseCfg)
 
@@ -115,6 +177,16 @@ func (cmd *cmdEnv) commonOptsGroup(parseCfg parseOpts) *opts.Group {
 
 	if parseCfg.lastFirst {
 		og.Bool(&cmd.lastFirst, "last-first")
+		og.String(&cmd.nameStyle, "name-style", nameStyleLegal)
+	}
+	if parseCfg.section {
+		og.StringZero(&cmd.section, "section")
+	}
+	if parseCfg.store {
+		og.String(&cmd.storeKind, "store", storeDir)
+	}
+	if parseCfg.watch {
+		og.Bool(&cmd.watchWanted, "watch")
 	}
 
 	return og
@@ -146,6 +218,19 @@ func (cmd *cmdEnv) resolvePaths() {
 		return
 	}
 
+	if cmd.storeKind == storeDir {
+		if info, err := os.Stat(absDirectory); err == nil && info.Mode().IsRegular() {
+			cmd.storeKind = storeArchive
+		}
+	}
+	if cmd.storeKind != "" && cmd.storeKind != storeDir {
+		// In an archive or a database, the class file is a name inside -dir.
+		cmd.directory = absDirectory
+		cmd.classFile = path.Clean(filepath.ToSlash(cmd.classFile))
+
+		return
+	}
+
 	absClassFile := filepath.Join(absDirectory, cmd.classFile)
 	absClassFile, err = filepath.Abs(absClassFile)
 	if err != nil {
@@ -164,7 +249,7 @@ func (cmd *cmdEnv) unmarshalClass() *gradebook.Class {
 		return nil
 	}
 
-	class, err := gradebook.UnmarshalClass(cmd.classFile)
+	class, data, err := readClass(cmd.classStore(), cmd.classFile)
 	if err != nil {
 		cmd.exitValue = exitFailure
 		fmt.Fprintf(cmd.stderr, "%s: problem unmarshaling class: %s\n", cmd.name, err)
@@ -178,9 +263,107 @@ func (cmd *cmdEnv) unmarshalClass() *gradebook.Class {
 		return nil
 	}
 
+	extras, err := unmarshalClassExtras(data, cmd.classFile)
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem unmarshaling class: %s\n", cmd.name, err)
+
+		return nil
+	}
+	if err = extras.validate(class); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem validating class: %s\n", cmd.name, err)
+
+		return nil
+	}
+	cmd.extras = extras
+
 	return class
 }
 
+func (cmd *cmdEnv) checkNameStyle() {
+	if cmd.noOp() {
+		return
+	}
+
+	if cmd.lastFirst {
+		cmd.nameStyle = nameStyleLastFirst
+	}
+
+	switch cmd.nameStyle {
+	case nameStyleLegal, nameStylePreferred, nameStyleLastFirst:
+	default:
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: invalid argument for -name-style: %q\n", cmd.name, cmd.nameStyle)
+	}
+}
+
+// findSection checks that cmd.section, if set, names a section that has at
+// least one student.
+func (cmd *cmdEnv) findSection() {
+	if cmd.noOp() || cmd.section == "" {
+		return
+	}
+
+	if !cmd.extras.hasSection(cmd.section) {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %q is not a valid section\n", cmd.name, cmd.section)
+	}
+}
+
+// emailsInSection returns student emails sorted by student name. If
+// cmd.section is set, only students in that section are included.
+func (cmd *cmdEnv) emailsInSection(class *gradebook.Class) []string {
+	emails := class.EmailsSortedByStudentName()
+	if cmd.section == "" {
+		return emails
+	}
+
+	return slices.DeleteFunc(emails, func(email string) bool {
+		return cmd.extras.student(email).Section != cmd.section
+	})
+}
+
+// confirm asks the user a yes-or-no question on stdout and reads the answer
+// from stdin. Only "y" or "yes" count as agreement. If yes is true, confirm
+// agrees without asking.
+func (cmd *cmdEnv) confirm(question string, yes bool) bool {
+	if cmd.noOp() {
+		return false
+	}
+	if yes {
+		return true
+	}
+
+	fmt.Fprintf(cmd.stdout, "%s [y/N] ", question)
+	answer, err := cmd.readLine()
+	if err != nil && !errors.Is(err, io.EOF) {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem reading confirmation: %s\n", cmd.name, err)
+
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		fmt.Fprintln(cmd.stdout, "No changes written")
+
+		return false
+	}
+}
+
+// readLine reads one line of input from stdin. Every question that a command
+// asks shares one buffered reader, so answers typed ahead are not lost.
+func (cmd *cmdEnv) readLine() (string, error) {
+	if cmd.stdinLines == nil {
+		cmd.stdinLines = bufio.NewReader(cmd.stdin)
+	}
+
+	return cmd.stdinLines.ReadString('\n')
+}
+
 func (cmd *cmdEnv) minNoOp() bool {
 	return cmd.exitValue != exitSuccess
 }
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// An encrypted file begins with a header: sealMagic, a version byte, a byte
+// for the kind of key, a salt, and a nonce. The rest is the file's contents
+// sealed with AES-256-GCM, with the header as additional data. A passphrase
+// is stretched into a key with PBKDF2 and the salt; a key file holds the key
+// itself as 64 hex digits.
+const (
+	sealMagic      = "GBENC"
+	sealVersion    = 1
+	sealSaltSize   = 16
+	sealNonceSize  = 12
+	sealHeaderSize = len(sealMagic) + 2 + sealSaltSize + sealNonceSize
+	sealKeySize    = 32
+	sealIterations = 600_000
+
+	sealKindPassphrase byte = 1
+	sealKindKeyFile    byte = 2
+
+	envPassphrase = "GRADEBOOK_PASSPHRASE"
+	envKeyFile    = "GRADEBOOK_KEYFILE"
+)
+
+// keyring holds the key that opens and seals a class's files: either
+// a passphrase or the key from a key file. A nil keyring has no key, so it
+// can read only files that are not encrypted.
+type keyring struct {
+	passphrase string
+	key        []byte
+	salt       []byte
+	derived    map[string][]byte
+}
+
+// newKeyring returns a keyring for keyFile or, if keyFile is empty, for
+// passphrase. If both are empty, it returns nil.
+func newKeyring(keyFile, passphrase string) (*keyring, error) {
+	switch {
+	case keyFile != "":
+		data, err := os.ReadFile(filepath.Clean(keyFile))
+		if err != nil {
+			return nil, fmt.Errorf("read key file %q: %w", keyFile, err)
+		}
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != sealKeySize {
+			return nil, fmt.Errorf("key file %q must hold %d hex digits", keyFile, 2*sealKeySize)
+		}
+
+		return &keyring{key: key}, nil
+	case passphrase != "":
+		return &keyring{passphrase: passphrase, derived: make(map[string][]byte)}, nil
+	default:
+		return nil, nil //nolint:nilnil // No key is not an error until a file is encrypted.
+	}
+}
+
+// isSealed reports whether data is an encrypted file.
+func isSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(sealMagic))
+}
+
+func (k *keyring) kind() byte {
+	if k.key != nil {
+		return sealKindKeyFile
+	}
+
+	return sealKindPassphrase
+}
+
+// aead returns the cipher for a file with the given salt. A key stretched
+// from a passphrase is kept for each salt, since stretching is slow on
+// purpose.
+func (k *keyring) aead(salt []byte) (cipher.AEAD, error) {
+	key := k.key
+	if key == nil {
+		key = k.derived[string(salt)]
+	}
+	if key == nil {
+		var err error
+		key, err = pbkdf2.Key(sha256.New, k.passphrase, salt, sealIterations, sealKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("derive key: %w", err)
+		}
+		k.derived[string(salt)] = key
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// open returns the contents of the file name, decrypting them if they are
+// encrypted.
+func (k *keyring) open(name string, data []byte) ([]byte, error) {
+	if !isSealed(data) {
+		return data, nil
+	}
+	if k == nil {
+		return nil, fmt.Errorf("%q is encrypted; set %s or %s", name, envPassphrase, envKeyFile)
+	}
+	if len(data) < sealHeaderSize {
+		return nil, fmt.Errorf("%q is not a valid encrypted file", name)
+	}
+
+	header := data[:sealHeaderSize]
+	version, kind := header[len(sealMagic)], header[len(sealMagic)+1]
+	if version != sealVersion {
+		return nil, fmt.Errorf("%q is encrypted with unsupported version %d", name, version)
+	}
+	switch {
+	case kind == sealKindPassphrase && k.kind() == sealKindKeyFile:
+		return nil, fmt.Errorf("%q is encrypted with a passphrase, not a key file; set %s", name, envPassphrase)
+	case kind == sealKindKeyFile && k.kind() == sealKindPassphrase:
+		return nil, fmt.Errorf("%q is encrypted with a key file, not a passphrase; set %s", name, envKeyFile)
+	case kind != sealKindPassphrase && kind != sealKindKeyFile:
+		return nil, fmt.Errorf("%q is not a valid encrypted file", name)
+	}
+
+	salt := header[len(sealMagic)+2 : len(sealMagic)+2+sealSaltSize]
+	nonce := header[len(sealMagic)+2+sealSaltSize:]
+	aead, err := k.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := aead.Open(nil, nonce, data[sealHeaderSize:], header)
+	if err != nil {
+		if kind == sealKindKeyFile {
+			return nil, fmt.Errorf("wrong key file for %q, or the file is damaged", name)
+		}
+
+		return nil, fmt.Errorf("wrong passphrase for %q, or the file is damaged", name)
+	}
+
+	return plain, nil
+}
+
+// seal encrypts data. Every file that a keyring seals with a passphrase
+// shares one salt, so the passphrase is stretched only once.
+func (k *keyring) seal(data []byte) ([]byte, error) {
+	if k == nil {
+		return nil, fmt.Errorf("cannot encrypt without a key; set %s or %s", envPassphrase, envKeyFile)
+	}
+
+	if k.salt == nil {
+		k.salt = make([]byte, sealSaltSize)
+		_, _ = rand.Read(k.salt) // crypto/rand.Read never returns an error.
+	}
+	aead, err := k.aead(k.salt)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, sealHeaderSize+len(data)+aead.Overhead())
+	header = append(header, sealMagic...)
+	header = append(header, sealVersion, k.kind())
+	header = append(header, k.salt...)
+	nonce := make([]byte, sealNonceSize)
+	_, _ = rand.Read(nonce) // crypto/rand.Read never returns an error.
+	header = append(header, nonce...)
+
+	return aead.Seal(header, nonce, data, header), nil
+}
+
+// readFile reads and decrypts a file on disk. It also reports whether the
+// file was encrypted, so that it can be written back the same way.
+func (k *keyring) readFile(name string) ([]byte, bool, error) {
+	data, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, false, err
+	}
+
+	sealed := isSealed(data)
+	data, err = k.open(name, data)
+
+	return data, sealed, err
+}
+
+// replaceFile replaces a file on disk, encrypting data first if sealed is
+// true.
+func (k *keyring) replaceFile(name string, data []byte, sealed bool) error {
+	if sealed {
+		var err error
+		if data, err = k.seal(data); err != nil {
+			return err
+		}
+	}
+
+	return replaceFile(name, data)
+}
+
+// sealedStore decrypts the encrypted files in a store as they are read.
+type sealedStore struct {
+	classStore
+	keys *keyring
+}
+
+func (ss sealedStore) readFile(name string) ([]byte, error) {
+	data, err := ss.classStore.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.keys.open(name, data)
+}
+
+// loadKeys sets up the key for encrypted files: keyFile if it is not empty,
+// or else the key file or passphrase in the environment.
+func (cmd *cmdEnv) loadKeys(keyFile string) {
+	if cmd.noOp() {
+		return
+	}
+
+	keyFile = cmp.Or(keyFile, os.Getenv(envKeyFile))
+	keys, err := newKeyring(keyFile, os.Getenv(envPassphrase))
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return
+	}
+	cmd.keys = keys
+}
+
+// sealLikeClass encrypts data for a new file if the class file is encrypted,
+// so that new files in an encrypted directory are encrypted too.
+func (cmd *cmdEnv) sealLikeClass(data []byte) ([]byte, error) {
+	classData, err := os.ReadFile(filepath.Clean(cmd.classFile))
+	if err != nil {
+		return nil, fmt.Errorf("read class file %q: %w", cmd.classFile, err)
+	}
+	if !isSealed(classData) {
+		return data, nil
+	}
+
+	return cmd.keys.seal(data)
+}
@@ -0,0 +1,384 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	curveAdd   = "add"
+	curveMean  = "mean"
+	curveScale = "scale"
+	curveSqrt  = "sqrt"
+	curveMax   = 100
+)
+
+// curve records a transformation of the grades in a gradebook file. The
+// grades themselves stay as they were entered, and the curve is applied when
+// grades are calculated. Method is one of the following.
+//
+//   - "add": add Points to every grade
+//   - "scale": multiply every grade by Factor
+//   - "sqrt": take the square root of every grade and multiply by 10
+//   - "mean": add Points to every grade, where Points moved the mean to Target
+//     when the curve was made
+//
+// A curve never raises a grade above 100, but it leaves a grade that was
+// already above 100 alone.
+type curve struct {
+	Method string  `json:"method"`
+	Points float64 `json:"points,omitempty"`
+	Factor float64 `json:"factor,omitempty"`
+	Target float64 `json:"target,omitempty"`
+}
+
+func (c *curve) apply(grade float64) float64 {
+	if c == nil {
+		return grade
+	}
+
+	curved := grade
+	switch c.Method {
+	case curveAdd, curveMean:
+		curved = grade + c.Points
+	case curveScale:
+		curved = grade * c.Factor
+	case curveSqrt:
+		curved = math.Sqrt(max(grade, 0)) * 10
+	}
+
+	return max(min(curved, max(curveMax, grade)), 0)
+}
+
+func (c *curve) check() error {
+	switch c.Method {
+	case curveAdd, curveMean, curveSqrt:
+		return nil
+	case curveScale:
+		if c.Factor <= 0 {
+			return errors.New("scale curve must have a positive factor")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown curve method %q", c.Method)
+	}
+}
+
+func (c *curve) String() string {
+	if c == nil {
+		return "no curve"
+	}
+
+	switch c.Method {
+	case curveAdd:
+		return fmt.Sprintf("add %s points", formatScore(c.Points))
+	case curveScale:
+		return fmt.Sprintf("scale by %s", formatScore(c.Factor))
+	case curveMean:
+		return fmt.Sprintf("move mean to %s (add %s points)", formatScore(c.Target), formatScore(c.Points))
+	default:
+		return "square root"
+	}
+}
+
+// GradebookCurve records a curve in a gradebook file after previewing its
+// effect on the distribution of grades.
+func GradebookCurve(args []string) int {
+	cmd := cmdFrom("gradebook-curve", curveUsage)
+
+	return runCommand(cmd, args, commandRun[curveCfg]{
+		parse:     (*cmdEnv).parseCurve,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg curveCfg) {
+			gbf := cmd.readCurveGradebook(class, cfg)
+			newCurve := cmd.resolveCurve(gbf, cfg)
+			cmd.printCurvePreview(gbf, newCurve)
+			if cfg.preview || !cmd.confirm(fmt.Sprintf("Write curve to %s?", cfg.file), cfg.yes) {
+				return
+			}
+			cmd.writeCurve(gbf, newCurve)
+		},
+	})
+}
+
+type curveCfg struct {
+	file    string
+	add     float64
+	mean    float64
+	scale   bool
+	sqrt    bool
+	remove  bool
+	preview bool
+	yes     bool
+}
+
+func (cmd *cmdEnv) parseCurve(args []string) curveCfg {
+	og := cmd.commonOptsGroup(parseOpts{})
+
+	var cfg curveCfg
+	og.String(&cfg.file, "file", "")
+	og.Float64(&cfg.add, "add", math.NaN())
+	og.Float64(&cfg.mean, "mean", math.NaN())
+	og.Bool(&cfg.scale, "scale")
+	og.Bool(&cfg.sqrt, "sqrt")
+	og.Bool(&cfg.remove, "remove")
+	og.Bool(&cfg.preview, "preview")
+	og.Bool(&cfg.yes, "yes")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkCurveCfg(cfg)
+
+	return cfg
+}
+
+func (cmd *cmdEnv) checkCurveCfg(cfg curveCfg) {
+	if cmd.noOp() {
+		return
+	}
+
+	methods := 0
+	for _, chosen := range []bool{!math.IsNaN(cfg.add), !math.IsNaN(cfg.mean), cfg.scale, cfg.sqrt, cfg.remove} {
+		if chosen {
+			methods++
+		}
+	}
+
+	switch {
+	case cfg.file == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -file is required\n", cmd.name)
+	case methods != 1:
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: use exactly one of -add, -mean, -scale, -sqrt, or -remove\n", cmd.name)
+	}
+}
+
+func (cmd *cmdEnv) readCurveGradebook(class *gradebook.Class, cfg curveCfg) *gradebookFile {
+	if cmd.noOp() {
+		return nil
+	}
+
+	gbPath := cfg.file
+	if !filepath.IsAbs(gbPath) {
+		gbPath = filepath.Join(cmd.directory, gbPath)
+	}
+
+	gbf, err := readGradebookFile(cmd.classStore(), gbPath)
+	if err == nil {
+		err = gbf.check(class, cmd.extras)
+	}
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return nil
+	}
+
+	return gbf
+}
+
+// resolveCurve turns the chosen method into a curve. Curves that depend on the
+// grades, such as scaling the top grade to 100, are resolved against the
+// grades as they are now, so later changes to the grades do not move the
+// curve.
+func (cmd *cmdEnv) resolveCurve(gbf *gradebookFile, cfg curveCfg) *curve {
+	if cmd.noOp() {
+		return nil
+	}
+
+	grades := cmd.extras.rawGrades(gbf)
+
+	switch {
+	case cfg.remove:
+		return nil
+	case cfg.sqrt:
+		return &curve{Method: curveSqrt}
+	case !math.IsNaN(cfg.add):
+		return &curve{Method: curveAdd, Points: cfg.add}
+	}
+
+	if len(grades) == 0 {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %q has no grades to curve\n", cmd.name, gbf.path)
+
+		return nil
+	}
+
+	if cfg.scale {
+		top := slices.Max(grades)
+		if top <= 0 {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: cannot scale when the top grade is %s\n", cmd.name, formatScore(top))
+
+			return nil
+		}
+
+		return &curve{Method: curveScale, Factor: curveMax / top}
+	}
+
+	return &curve{Method: curveMean, Target: cfg.mean, Points: cfg.mean - fmeanScores(grades)}
+}
+
+// rawGrades returns the grades in a gradebook file before any curve or other
+// policy applies.
+func (ce *classExtras) rawGrades(gbf *gradebookFile) []float64 {
+	grades := make([]float64, 0, len(gbf.AssignmentRecords))
+	for _, ar := range gbf.AssignmentRecords {
+		if grade, ok := ce.grade(gbf, ar); ok {
+			grades = append(grades, grade)
+		}
+	}
+
+	return grades
+}
+
+func (cmd *cmdEnv) printCurvePreview(gbf *gradebookFile, newCurve *curve) {
+	if cmd.noOp() {
+		return
+	}
+
+	raw := cmd.extras.rawGrades(gbf)
+	before := make([]float64, 0, len(raw))
+	after := make([]float64, 0, len(raw))
+	for _, grade := range raw {
+		before = append(before, gbf.Curve.apply(grade))
+		after = append(after, newCurve.apply(grade))
+	}
+
+	fmt.Fprintf(cmd.stdout, "%s: %s -> %s\n", gbf.AssignmentName, gbf.Curve, newCurve)
+
+	tw := tabwriter.NewWriter(cmd.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tBefore\tAfter")
+	for _, row := range distributionRows(before, after) {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+	}
+}
+
+// distributionRows summarizes two sets of grades side by side: summary
+// statistics followed by counts in grade bands.
+func distributionRows(before, after []float64) [][]string {
+	stats := func(label string, stat func([]float64) float64) []string {
+		row := []string{label, "-", "-"}
+		if len(before) > 0 {
+			row[1] = formatScore(stat(before))
+			row[2] = formatScore(stat(after))
+		}
+
+		return row
+	}
+
+	rows := [][]string{
+		{"Scored", fmt.Sprint(len(before)), fmt.Sprint(len(after))},
+		stats("Mean", fmeanScores),
+		stats("Median", medianScores),
+		stats("Low", slices.Min[[]float64]),
+		stats("High", slices.Max[[]float64]),
+	}
+
+	bands := []struct {
+		label string
+		low   float64
+	}{
+		{"90+", 90},
+		{"80-89", 80},
+		{"70-79", 70},
+		{"60-69", 60},
+		{"0-59", math.Inf(-1)},
+	}
+	for i, band := range bands {
+		high := math.Inf(1)
+		if i > 0 {
+			high = bands[i-1].low
+		}
+		count := func(grades []float64) string {
+			n := 0
+			for _, grade := range grades {
+				if grade >= band.low && grade < high {
+					n++
+				}
+			}
+
+			return fmt.Sprint(n)
+		}
+		rows = append(rows, []string{band.label, count(before), count(after)})
+	}
+
+	return rows
+}
+
+func fmeanScores(scores []float64) float64 {
+	var sum float64
+	for _, score := range scores {
+		sum += score
+	}
+
+	return sum / float64(len(scores))
+}
+
+func medianScores(scores []float64) float64 {
+	sorted := slices.Sorted(slices.Values(scores))
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+func (cmd *cmdEnv) writeCurve(gbf *gradebookFile, newCurve *curve) {
+	if cmd.noOp() {
+		return
+	}
+
+	if err := rewriteGradebookCurve(cmd.keys, gbf.path, newCurve); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem writing curve: %s\n", cmd.name, err)
+	}
+}
+
+// rewriteGradebookCurve sets or removes the curve in a gradebook file. Every
+// other field in the file is carried over unchanged.
+func rewriteGradebookCurve(keys *keyring, gbPath string, newCurve *curve) error {
+	data, sealed, err := keys.readFile(gbPath)
+	if err != nil {
+		return fmt.Errorf("read gradebook file %q: %w", gbPath, err)
+	}
+
+	raw := newJSONObject()
+	if err = json.Unmarshal(data, raw); err != nil {
+		return fmt.Errorf("unmarshal gradebook file %q: %w", gbPath, err)
+	}
+
+	if newCurve == nil {
+		raw.delete("curve")
+	} else if err = raw.setValue("curve", newCurve); err != nil {
+		return fmt.Errorf("marshal curve: %w", err)
+	}
+
+	out, err := marshalJSONFile(raw)
+	if err != nil {
+		return err
+	}
+
+	return keys.replaceFile(gbPath, out, sealed)
+}
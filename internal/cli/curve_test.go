@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const curveGradebookFile = "quiz-quiz-1-20240319.gradebook"
+
+const curveGradebookJSON = `{
+    "assignment_category": "minor",
+    "assignment_date": "20240319",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 80
+        },
+        {
+            "email": "alice@example.com",
+            "grade": 60
+        }
+    ],
+    "assignment_name": "quiz-1",
+    "assignment_type": "quiz"
+}`
+
+func writeCurveFixture(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	mustWriteFixtureFile(t, filepath.Join(dir, suiteClassFile), classFixtureJSON)
+	mustWriteFixtureFile(t, filepath.Join(dir, curveGradebookFile), curveGradebookJSON)
+
+	return dir
+}
+
+func TestCurveApply(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		c     *curve
+		grade float64
+		want  float64
+	}{
+		"no curve":           {c: nil, grade: 72, want: 72},
+		"add":                {c: &curve{Method: curveAdd, Points: 5}, grade: 72, want: 77},
+		"add capped":         {c: &curve{Method: curveAdd, Points: 5}, grade: 98, want: 100},
+		"add over 100 stays": {c: &curve{Method: curveAdd, Points: 5}, grade: 103, want: 103},
+		"scale":              {c: &curve{Method: curveScale, Factor: 1.25}, grade: 60, want: 75},
+		"sqrt":               {c: &curve{Method: curveSqrt}, grade: 64, want: 80},
+		"negative points":    {c: &curve{Method: curveMean, Points: -10}, grade: 5, want: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.c.apply(tc.grade); got != tc.want {
+				t.Fatalf("apply(%v) = %v; want %v", tc.grade, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPublicGradebookCurveAddWritesCurve(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCurveFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCurve, []string{
+		"-dir", dir,
+		"-file", curveGradebookFile,
+		"-add", "10",
+		"-yes",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if !strings.Contains(stdout, "quiz-1: no curve -> add 10 points") {
+		t.Fatalf("stdout = %q; want curve summary", stdout)
+	}
+
+	gbf, err := readGradebookFile(dirStore{}, filepath.Join(dir, curveGradebookFile))
+	if err != nil {
+		t.Fatalf("readGradebookFile() returned error: %v", err)
+	}
+	if gbf.Curve == nil || gbf.Curve.Method != curveAdd || gbf.Curve.Points != 10 {
+		t.Fatalf("Curve = %+v; want add 10 points", gbf.Curve)
+	}
+	if *gbf.AssignmentRecords[0].Grade != 80 {
+		t.Fatalf("Grade = %v; want raw grade 80 left alone", *gbf.AssignmentRecords[0].Grade)
+	}
+
+	exitCode, stdout, stderr = runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitSuccess {
+		t.Fatalf("calc exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if !strings.Contains(stdout, "Bob Young\n\tOverall average: 90\n") {
+		t.Fatalf("calc stdout = %q; want curved average 90 for Bob", stdout)
+	}
+	if !strings.Contains(stdout, "Alice Zephyr\n\tOverall average: 70\n") {
+		t.Fatalf("calc stdout = %q; want curved average 70 for Alice", stdout)
+	}
+}
+
+func TestPublicGradebookCurveMeanPreview(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCurveFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCurve, []string{
+		"-dir", dir,
+		"-file", curveGradebookFile,
+		"-mean", "75",
+		"-preview",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	for _, want := range []string{
+		"move mean to 75 (add 5 points)",
+		"Mean    70      75",
+		"High    80      85",
+		"80-89   1       1",
+		"60-69   1       1",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("stdout = %q; want it to contain %q", stdout, want)
+		}
+	}
+
+	gbf, err := readGradebookFile(dirStore{}, filepath.Join(dir, curveGradebookFile))
+	if err != nil {
+		t.Fatalf("readGradebookFile() returned error: %v", err)
+	}
+	if gbf.Curve != nil {
+		t.Fatalf("Curve = %+v; want nil after -preview", gbf.Curve)
+	}
+}
+
+func TestPublicGradebookCurveScaleThenRemove(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCurveFixture(t)
+	gbPath := filepath.Join(dir, curveGradebookFile)
+
+	exitCode, _, stderr := runPublicCommand(t, GradebookCurve, []string{
+		"-dir", dir, "-file", curveGradebookFile, "-scale", "-yes",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	gbf, err := readGradebookFile(dirStore{}, gbPath)
+	if err != nil {
+		t.Fatalf("readGradebookFile() returned error: %v", err)
+	}
+	if gbf.Curve == nil || gbf.Curve.Factor != 1.25 {
+		t.Fatalf("Curve = %+v; want scale by 1.25", gbf.Curve)
+	}
+
+	exitCode, _, stderr = runPublicCommand(t, GradebookCurve, []string{
+		"-dir", dir, "-file", curveGradebookFile, "-remove", "-yes",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	gbf, err = readGradebookFile(dirStore{}, gbPath)
+	if err != nil {
+		t.Fatalf("readGradebookFile() returned error: %v", err)
+	}
+	if gbf.Curve != nil {
+		t.Fatalf("Curve = %+v; want nil after -remove", gbf.Curve)
+	}
+
+	// Adding and removing a curve leaves the rest of the file as it was.
+	data, err := os.ReadFile(gbPath)
+	if err != nil {
+		t.Fatalf("failed to read gradebook file: %v", err)
+	}
+	if want := curveGradebookJSON + "\n"; string(data) != want {
+		t.Errorf("gradebook file = %s; want %s", data, want)
+	}
+}
+
+func TestPublicGradebookCurveRequiresOneMethod(t *testing.T) {
+	t.Parallel()
+
+	dir := writeCurveFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookCurve, []string{
+		"-dir", dir, "-file", curveGradebookFile, "-sqrt", "-add", "5",
+	})
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.Contains(stderr, "exactly one of") {
+		t.Fatalf("stderr = %q; want method error", stderr)
+	}
+}
+
+func TestLoadGradebookFilesRejectsUnknownCurve(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mustWriteFixtureFile(t, filepath.Join(dir, suiteClassFile), classFixtureJSON)
+	mustWriteFixtureFile(t, filepath.Join(dir, curveGradebookFile), strings.Replace(
+		curveGradebookJSON,
+		`"assignment_type": "quiz"`,
+		`"assignment_type": "quiz", "curve": {"method": "bell"}`,
+		1,
+	))
+
+	exitCode, _, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.Contains(stderr, `unknown curve method "bell"`) {
+		t.Fatalf("stderr = %q; want curve error", stderr)
+	}
+}
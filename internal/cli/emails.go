@@ -12,9 +12,10 @@ func GradebookEmails(args []string) int {
 	cmd := cmdFrom("gradebook-emails", emailsUsage)
 
 	return runCommand(cmd, args, commandRun[noArgs]{
-		parse:     (*cmdEnv).parseNoArgs,
+		parse:     (*cmdEnv).parseSection,
 		loadClass: true,
 		action: func(cmd *cmdEnv, class *gradebook.Class, _ noArgs) {
+			cmd.findSection()
 			cmd.printEmails(class)
 		},
 	})
@@ -25,6 +26,6 @@ func (cmd *cmdEnv) printEmails(class *gradebook.Class) {
 		return
 	}
 
-	emails := class.EmailsSortedByStudentName()
+	emails := cmd.emailsInSection(class)
 	fmt.Fprintln(cmd.stdout, strings.Join(emails, "\n"))
 }
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/telemachus/gradebook"
+	"golang.org/x/term"
+)
+
+// GradebookEncrypt encrypts a class file and its gradebook files in place.
+func GradebookEncrypt(args []string) int {
+	cmd := cmdFrom("gradebook-encrypt", encryptUsage)
+
+	return runCommand(cmd, args, commandRun[string]{
+		parse: (*cmdEnv).parseCrypt,
+		action: func(cmd *cmdEnv, _ *gradebook.Class, keyFile string) {
+			cmd.convertClassFiles(keyFile, true)
+		},
+	})
+}
+
+// GradebookDecrypt decrypts a class file and its gradebook files in place.
+func GradebookDecrypt(args []string) int {
+	cmd := cmdFrom("gradebook-decrypt", decryptUsage)
+
+	return runCommand(cmd, args, commandRun[string]{
+		parse: (*cmdEnv).parseCrypt,
+		action: func(cmd *cmdEnv, _ *gradebook.Class, keyFile string) {
+			cmd.convertClassFiles(keyFile, false)
+		},
+	})
+}
+
+func (cmd *cmdEnv) parseCrypt(args []string) string {
+	og := cmd.commonOptsGroup(parseOpts{})
+
+	keyFile := ""
+	og.String(&keyFile, "keyfile", "")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return ""
+	}
+
+	return keyFile
+}
+
+// convertClassFiles encrypts (if seal is true) or decrypts the class file and
+// every gradebook file in the directory. Every file is converted before any
+// is written, so a wrong key leaves the directory as it was.
+func (cmd *cmdEnv) convertClassFiles(keyFile string, seal bool) {
+	cmd.resolvePaths()
+	cmd.loadKeys(keyFile)
+	cmd.askPassphrase(seal)
+	names := cmd.classFileNames()
+	converted := cmd.convertFiles(names, seal)
+	cmd.writeConverted(converted, seal, len(names))
+}
+
+// askPassphrase asks for a passphrase on the terminal if no key was given.
+// When encrypting, it asks twice, to guard against typos.
+func (cmd *cmdEnv) askPassphrase(seal bool) {
+	if cmd.noOp() || cmd.keys != nil {
+		return
+	}
+
+	fh, ok := cmd.stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(fh.Fd())) {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: no key; set %s or %s, or use -keyfile\n", cmd.name, envPassphrase, envKeyFile)
+
+		return
+	}
+
+	passphrase := cmd.readPassphrase(fh, "Passphrase: ")
+	if seal && cmd.readPassphrase(fh, "Repeat passphrase: ") != passphrase {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: passphrases do not match\n", cmd.name)
+
+		return
+	}
+	if cmd.noOp() {
+		return
+	}
+	if passphrase == "" {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: empty passphrase\n", cmd.name)
+
+		return
+	}
+
+	cmd.keys, _ = newKeyring("", passphrase)
+}
+
+func (cmd *cmdEnv) readPassphrase(fh *os.File, prompt string) string {
+	if cmd.noOp() {
+		return ""
+	}
+
+	fmt.Fprint(cmd.stderr, prompt)
+	passphrase, err := term.ReadPassword(int(fh.Fd()))
+	fmt.Fprintln(cmd.stderr)
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem reading passphrase: %s\n", cmd.name, err)
+
+		return ""
+	}
+
+	return string(passphrase)
+}
+
+// classFileNames returns the class file and the gradebook files in the
+// directory.
+func (cmd *cmdEnv) classFileNames() []string {
+	if cmd.noOp() {
+		return nil
+	}
+
+	names, err := dirStore{dir: cmd.directory}.gradebookFiles()
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return nil
+	}
+
+	return slices.Insert(names, 0, cmd.classFile)
+}
+
+// convertFiles returns the new contents of each file that needs converting,
+// keyed by name. Files that are already encrypted are checked against the
+// key before encrypting the rest, so that one directory never mixes keys.
+func (cmd *cmdEnv) convertFiles(names []string, seal bool) map[string][]byte {
+	if cmd.noOp() {
+		return nil
+	}
+
+	converted := make(map[string][]byte, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Clean(name))
+		if err != nil {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+			return nil
+		}
+
+		sealed := isSealed(data)
+		plain, err := cmd.keys.open(name, data)
+		switch {
+		case err != nil:
+		case seal && !sealed:
+			converted[name], err = cmd.keys.seal(plain)
+		case !seal && sealed:
+			converted[name] = plain
+		}
+		if err != nil {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+			return nil
+		}
+	}
+
+	return converted
+}
+
+func (cmd *cmdEnv) writeConverted(converted map[string][]byte, seal bool, total int) {
+	if cmd.noOp() {
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(converted)) {
+		if err := replaceFile(name, converted[name]); err != nil {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: problem writing %q: %s\n", cmd.name, name, err)
+
+			return
+		}
+	}
+
+	verb, state := "Decrypted", "not encrypted"
+	if seal {
+		verb, state = "Encrypted", "already encrypted"
+	}
+	fmt.Fprintf(cmd.stdout, "%s %d file(s) in %s", verb, len(converted), cmd.directory)
+	if skipped := total - len(converted); skipped > 0 {
+		fmt.Fprintf(cmd.stdout, " (%d %s)", skipped, state)
+	}
+	fmt.Fprintln(cmd.stdout)
+}
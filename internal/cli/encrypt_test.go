@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKeyFile(t *testing.T, hexKey string) string {
+	t.Helper()
+
+	keyFile := filepath.Join(t.TempDir(), "gradebook.key")
+	mustWriteFixtureFile(t, keyFile, hexKey+"\n")
+
+	return keyFile
+}
+
+const (
+	testKeyHex      = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	testOtherKeyHex = "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+)
+
+func TestKeyringSealOpen(t *testing.T) {
+	t.Parallel()
+
+	keyFileKeys, err := newKeyring(writeKeyFile(t, testKeyHex), "")
+	if err != nil {
+		t.Fatalf("newKeyring() error = %v", err)
+	}
+	otherKeys, err := newKeyring(writeKeyFile(t, testOtherKeyHex), "")
+	if err != nil {
+		t.Fatalf("newKeyring() error = %v", err)
+	}
+	passKeys, err := newKeyring("", "correct horse")
+	if err != nil {
+		t.Fatalf("newKeyring() error = %v", err)
+	}
+	wrongPassKeys, err := newKeyring("", "battery staple")
+	if err != nil {
+		t.Fatalf("newKeyring() error = %v", err)
+	}
+
+	plain := []byte(classFixtureJSON)
+	byKeyFile, err := keyFileKeys.seal(plain)
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	byPassphrase, err := passKeys.seal(plain)
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	if !isSealed(byKeyFile) || !isSealed(byPassphrase) || isSealed(plain) {
+		t.Fatal("isSealed() does not tell encrypted files from plain ones")
+	}
+	if bytes.Contains(byPassphrase, []byte("alice@example.com")) {
+		t.Error("encrypted file contains plain text")
+	}
+
+	for name, keys := range map[string]*keyring{"key file": keyFileKeys, "passphrase": passKeys} {
+		sealed := byKeyFile
+		if name == "passphrase" {
+			sealed = byPassphrase
+		}
+		got, err := keys.open("class.json", sealed)
+		if err != nil {
+			t.Fatalf("open() with %s error = %v", name, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("open() with %s = %q; want %q", name, got, plain)
+		}
+	}
+
+	tampered := bytes.Clone(byKeyFile)
+	tampered[len(tampered)-1] ^= 1
+
+	testCases := map[string]struct {
+		keys *keyring
+		data []byte
+		want string
+	}{
+		"no key": {
+			keys: nil,
+			data: byKeyFile,
+			want: `"class.json" is encrypted; set GRADEBOOK_PASSPHRASE or GRADEBOOK_KEYFILE`,
+		},
+		"wrong key file": {
+			keys: otherKeys,
+			data: byKeyFile,
+			want: `wrong key file for "class.json"`,
+		},
+		"wrong passphrase": {
+			keys: wrongPassKeys,
+			data: byPassphrase,
+			want: `wrong passphrase for "class.json"`,
+		},
+		"passphrase for key file": {
+			keys: passKeys,
+			data: byKeyFile,
+			want: "encrypted with a key file, not a passphrase",
+		},
+		"key file for passphrase": {
+			keys: keyFileKeys,
+			data: byPassphrase,
+			want: "encrypted with a passphrase, not a key file",
+		},
+		"damaged file": {
+			keys: keyFileKeys,
+			data: tampered,
+			want: "or the file is damaged",
+		},
+		"truncated header": {
+			keys: keyFileKeys,
+			data: []byte(sealMagic),
+			want: "not a valid encrypted file",
+		},
+	}
+
+	for msg, tc := range testCases {
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tc.keys.open("class.json", tc.data)
+			if err == nil {
+				t.Fatal("open() error = nil; want an error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("open() error = %q; want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+// Tests that set GRADEBOOK_KEYFILE cannot run in parallel.
+func TestPublicGradebookEncryptDecrypt(t *testing.T) {
+	dir := writeTrendFixture(t)
+	keyFile := writeKeyFile(t, testKeyHex)
+	classFile := filepath.Join(dir, suiteClassFile)
+	gbFile := filepath.Join(dir, "quiz-quiz-1-20240319.gradebook")
+
+	_, wantCalc, _ := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookEncrypt, []string{"-dir", dir, "-keyfile", keyFile})
+	if exitCode != exitSuccess {
+		t.Fatalf("encrypt exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if want := "Encrypted 3 file(s) in " + dir + "\n"; stdout != want {
+		t.Errorf("encrypt stdout = %q; want %q", stdout, want)
+	}
+	for _, name := range []string{classFile, gbFile} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if !isSealed(data) {
+			t.Errorf("%s is not encrypted", name)
+		}
+	}
+
+	t.Setenv(envKeyFile, "")
+	exitCode, _, stderr = runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitFailure || !strings.Contains(stderr, "is encrypted; set GRADEBOOK_PASSPHRASE or GRADEBOOK_KEYFILE") {
+		t.Errorf("calc without key: exitCode = %d, stderr = %q; want a missing key error", exitCode, stderr)
+	}
+
+	t.Setenv(envKeyFile, writeKeyFile(t, testOtherKeyHex))
+	exitCode, _, stderr = runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitFailure || !strings.Contains(stderr, "wrong key file for") {
+		t.Errorf("calc with wrong key: exitCode = %d, stderr = %q; want a wrong key error", exitCode, stderr)
+	}
+
+	t.Setenv(envKeyFile, keyFile)
+	exitCode, gotCalc, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitSuccess {
+		t.Fatalf("calc exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if gotCalc != wantCalc {
+		t.Errorf("calc stdout = %q; want %q", gotCalc, wantCalc)
+	}
+
+	exitCode, _, stderr = runPublicCommand(t, GradebookNew, []string{
+		"-dir", dir, "-name", "quiz-2", "-type", "quiz", "-date", "20240402",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("new exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	newFile := filepath.Join(dir, "quiz-quiz-2-20240402.gradebook")
+	if data, err := os.ReadFile(newFile); err != nil || !isSealed(data) {
+		t.Errorf("new gradebook file in an encrypted directory is not encrypted (err: %v)", err)
+	}
+
+	exitCode, stdout, stderr = runPublicCommand(t, GradebookDecrypt, []string{"-dir", dir})
+	if exitCode != exitSuccess {
+		t.Fatalf("decrypt exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if want := "Decrypted 4 file(s) in " + dir + "\n"; stdout != want {
+		t.Errorf("decrypt stdout = %q; want %q", stdout, want)
+	}
+	data, err := os.ReadFile(gbFile)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", gbFile, err)
+	}
+	if string(data) != gradebookFixtureJSON {
+		t.Errorf("decrypted gradebook file = %q; want %q", data, gradebookFixtureJSON)
+	}
+}
+
+func TestPublicGradebookDecryptWrongKeyChangesNothing(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookEncrypt, []string{
+		"-dir", dir, "-keyfile", writeKeyFile(t, testKeyHex),
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("encrypt exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	before, err := os.ReadFile(filepath.Join(dir, suiteClassFile))
+	if err != nil {
+		t.Fatalf("failed to read class file: %v", err)
+	}
+
+	exitCode, _, stderr = runPublicCommand(t, GradebookDecrypt, []string{
+		"-dir", dir, "-keyfile", writeKeyFile(t, testOtherKeyHex),
+	})
+	if exitCode != exitFailure {
+		t.Fatalf("decrypt exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.Contains(stderr, "wrong key file for") {
+		t.Errorf("stderr = %q; want a wrong key error", stderr)
+	}
+	after, err := os.ReadFile(filepath.Join(dir, suiteClassFile))
+	if err != nil {
+		t.Fatalf("failed to read class file: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("decrypt with a wrong key changed the class file")
+	}
+}
+
+func TestGradebookEncryptErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"bad key file": {
+			args: []string{"-dir", dir, "-keyfile", writeKeyFile(t, "abc")},
+			want: "must hold 64 hex digits",
+		},
+		"missing key file": {
+			args: []string{"-dir", dir, "-keyfile", filepath.Join(dir, "missing.key")},
+			want: "read key file",
+		},
+		"no key": {
+			args: []string{"-dir", dir},
+			want: "no key; set GRADEBOOK_PASSPHRASE or GRADEBOOK_KEYFILE, or use -keyfile",
+		},
+	}
+
+	for msg, tc := range testCases {
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			cmd := cmdFromWithWriters("gradebook-encrypt", encryptUsage, &stdout, &stderr)
+			cmd.stdin = strings.NewReader("")
+			keyFile := cmd.parseCrypt(tc.args)
+			cmd.convertClassFiles(keyFile, true)
+
+			if cmd.exitValue != exitFailure {
+				t.Fatalf("exitValue = %d; want %d", cmd.exitValue, exitFailure)
+			}
+			if !strings.Contains(stderr.String(), tc.want) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr.String(), tc.want)
+			}
+		})
+	}
+}
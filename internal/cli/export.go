@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/telemachus/gradebook"
+	"github.com/telemachus/gradebook-suite/internal/spreadsheet"
+)
+
+const (
+	formatXLSX = "xlsx"
+	formatODS  = "ods"
+)
+
+// GradebookExport writes a class's grades to a spreadsheet file.
+func GradebookExport(args []string) int {
+	cmd := cmdFrom("gradebook-export", exportUsage)
+
+	return runCommand(cmd, args, commandRun[exportCfg]{
+		parse:     (*cmdEnv).parseExport,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg exportCfg) {
+			cmd.findTerm(class, cfg.term)
+			cmd.findSection()
+			gbFiles := cmd.loadGrades(class, cfg.term)
+			wb := cmd.gradesWorkbook(class, gbFiles)
+			cmd.writeWorkbook(wb, cfg)
+		},
+	})
+}
+
+type exportCfg struct {
+	term   string
+	out    string
+	format string
+}
+
+func (cmd *cmdEnv) parseExport(args []string) exportCfg {
+	og := cmd.commonOptsGroup(parseOpts{lastFirst: true, section: true, store: true})
+
+	var cfg exportCfg
+	og.String(&cfg.term, "term", "")
+	og.String(&cfg.out, "out", "")
+	og.String(&cfg.format, "format", "")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkNameStyle()
+	cfg.format = cmd.exportFormat(cfg)
+
+	return cfg
+}
+
+// exportFormat returns the spreadsheet format to write. Without -format, the
+// format comes from the extension of the output file.
+func (cmd *cmdEnv) exportFormat(cfg exportCfg) string {
+	if cmd.noOp() {
+		return cfg.format
+	}
+
+	if cfg.out == "" {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -out is required\n", cmd.name)
+
+		return cfg.format
+	}
+
+	format := cfg.format
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(cfg.out), "."))
+	}
+	if format != formatXLSX && format != formatODS {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: unknown format %q (want %q or %q)\n", cmd.name, format, formatXLSX, formatODS)
+	}
+
+	return format
+}
+
+// gradesWorkbook builds a workbook with a summary sheet of each student's
+// averages, a sheet for each category with each student's score on each
+// assignment, and a roster sheet. Unscored records and averages without
+// results are left empty.
+func (cmd *cmdEnv) gradesWorkbook(class *gradebook.Class, gbFiles []*gradebookFile) *spreadsheet.Workbook {
+	if cmd.noOp() {
+		return nil
+	}
+
+	wb := &spreadsheet.Workbook{}
+	emails := cmd.emailsInSection(class)
+	cats := class.AssignmentCategoriesSortedByLabel()
+
+	cmd.addSummarySheet(wb, class, gbFiles, emails, cats)
+	for _, cat := range cats {
+		cmd.addCategorySheet(wb, class, gbFiles, emails, cat)
+	}
+	cmd.addRosterSheet(wb, class, emails)
+
+	return wb
+}
+
+func (cmd *cmdEnv) addSummarySheet(
+	wb *spreadsheet.Workbook,
+	class *gradebook.Class,
+	gbFiles []*gradebookFile,
+	emails, cats []string,
+) {
+	sh := wb.AddSheet("Summary")
+	bonusByEmail := cmd.extras.extraCreditPoints(gbFiles)
+
+	header := []spreadsheet.Cell{spreadsheet.Text("Student"), spreadsheet.Text("Email"), spreadsheet.Text("Overall")}
+	if cmd.extras.hasExtraCredit() {
+		header = append(header, spreadsheet.Text("Extra credit"), spreadsheet.Text("Overall with extra credit"))
+	}
+	for _, cat := range cats {
+		header = append(header, spreadsheet.Text(class.LabelsByAssignmentCategory[cat]))
+	}
+	sh.AddRow(header...)
+
+	for _, email := range emails {
+		s := class.StudentsByEmail[email]
+		total := s.TotalAverage(class.WeightsByAssignmentCategory)
+		row := []spreadsheet.Cell{
+			spreadsheet.Text(cmd.studentName(s, email)),
+			spreadsheet.Text(email),
+			averageCell(total),
+		}
+		if cmd.extras.hasExtraCredit() {
+			row = append(row, spreadsheet.Number(bonusByEmail[email]), averageCell(withBonus(total, bonusByEmail[email])))
+		}
+		for _, cat := range cats {
+			row = append(row, averageCell(s.Average(cat)))
+		}
+		sh.AddRow(row...)
+	}
+}
+
+func averageCell(ar gradebook.AverageResult) spreadsheet.Cell {
+	if !ar.Valid {
+		return spreadsheet.Empty()
+	}
+
+	return spreadsheet.Number(ar.Value)
+}
+
+// addCategorySheet adds a sheet with a row for each student and a column for
+// each assignment in cat, in date order. Each cell is the score that counts
+// toward the student's average.
+func (cmd *cmdEnv) addCategorySheet(
+	wb *spreadsheet.Workbook,
+	class *gradebook.Class,
+	gbFiles []*gradebookFile,
+	emails []string,
+	cat string,
+) {
+	sh := wb.AddSheet(class.LabelsByAssignmentCategory[cat])
+
+	assignments := make([]*gradebookFile, 0, len(gbFiles))
+	for _, gbf := range sortedByDate(gbFiles) {
+		if gbf.category(class) == cat {
+			assignments = append(assignments, gbf)
+		}
+	}
+
+	header := []spreadsheet.Cell{spreadsheet.Text("Student"), spreadsheet.Text("Email")}
+	for _, gbf := range assignments {
+		header = append(header, spreadsheet.Text(fmt.Sprintf("%s (%s)", gbf.AssignmentName, gbf.AssignmentDate)))
+	}
+	sh.AddRow(header...)
+
+	byEmail := make([]map[string]*assignmentRecord, 0, len(assignments))
+	for _, gbf := range assignments {
+		byEmail = append(byEmail, recordsByEmail(gbf))
+	}
+
+	for _, email := range emails {
+		row := []spreadsheet.Cell{
+			spreadsheet.Text(cmd.studentName(class.StudentsByEmail[email], email)),
+			spreadsheet.Text(email),
+		}
+		for i, gbf := range assignments {
+			cell := spreadsheet.Empty()
+			if ar, ok := byEmail[i][email]; ok {
+				if score, ok := cmd.extras.score(cat, gbf, ar); ok {
+					cell = spreadsheet.Number(score)
+				}
+			}
+			row = append(row, cell)
+		}
+		sh.AddRow(row...)
+	}
+}
+
+func (cmd *cmdEnv) addRosterSheet(wb *spreadsheet.Workbook, class *gradebook.Class, emails []string) {
+	sh := wb.AddSheet("Roster")
+	sh.AddRow(
+		spreadsheet.Text("Email"),
+		spreadsheet.Text("First Name"),
+		spreadsheet.Text("Last Name"),
+		spreadsheet.Text("Preferred Name"),
+		spreadsheet.Text("Pronouns"),
+		spreadsheet.Text("Section"),
+		spreadsheet.Text("Student ID"),
+	)
+
+	for _, email := range emails {
+		s := class.StudentsByEmail[email]
+		se := cmd.extras.student(email)
+		sh.AddRow(
+			spreadsheet.Text(email),
+			spreadsheet.Text(s.FirstName),
+			spreadsheet.Text(s.LastName),
+			spreadsheet.Text(se.PreferredName),
+			spreadsheet.Text(se.Pronouns),
+			spreadsheet.Text(se.Section),
+			spreadsheet.Text(se.StudentID),
+		)
+	}
+}
+
+func (cmd *cmdEnv) writeWorkbook(wb *spreadsheet.Workbook, cfg exportCfg) {
+	if cmd.noOp() {
+		return
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if cfg.format == formatODS {
+		err = spreadsheet.WriteODS(&buf, wb)
+	} else {
+		err = spreadsheet.WriteXLSX(&buf, wb)
+	}
+	if err == nil {
+		err = replaceFile(cfg.out, buf.Bytes())
+	}
+
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem writing %s: %s\n", cmd.name, cfg.out, err)
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readZipEntry(t *testing.T, path, name string) string {
+	t.Helper()
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s in %s: %v", name, path, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("failed to read %s in %s: %v", name, path, err)
+		}
+
+		return string(data)
+	}
+	t.Fatalf("%s has no %s", path, name)
+
+	return ""
+}
+
+func TestPublicGradebookExportXLSX(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	out := filepath.Join(t.TempDir(), "grades.xlsx")
+	exitCode, _, stderr := runPublicCommand(t, GradebookExport, []string{"-dir", dir, "-out", out})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	book := readZipEntry(t, out, "xl/workbook.xml")
+	for _, name := range []string{"Summary", "Major", "Minor", "Participation", "Roster"} {
+		if !strings.Contains(book, `name="`+name+`"`) {
+			t.Errorf("workbook has no %q sheet\n%s", name, book)
+		}
+	}
+
+	summary := readZipEntry(t, out, "xl/worksheets/sheet1.xml")
+	for _, want := range []string{
+		`<c r="C2"><v>71.25</v></c>`,
+		`<c r="D2"><v>60</v></c>`,
+		`<c r="E2"><v>90</v></c>`,
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary sheet does not contain %q\n%s", want, summary)
+		}
+	}
+	// Alice has no minor grades, so her minor average is left empty.
+	if strings.Contains(summary, `r="E3"`) {
+		t.Errorf("summary sheet has a value for an average without results\n%s", summary)
+	}
+
+	minor := readZipEntry(t, out, "xl/worksheets/sheet3.xml")
+	if !strings.Contains(minor, "quiz-1 (20240319)") {
+		t.Errorf("minor sheet has no quiz-1 column\n%s", minor)
+	}
+	if strings.Contains(minor, `r="C3"`) {
+		t.Errorf("minor sheet has a value for an unscored record\n%s", minor)
+	}
+}
+
+func TestPublicGradebookExportODS(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	out := filepath.Join(t.TempDir(), "grades.ods")
+	exitCode, _, stderr := runPublicCommand(t, GradebookExport, []string{"-dir", dir, "-out", out})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	content := readZipEntry(t, out, "content.xml")
+	for _, want := range []string{
+		`<table:table table:name="Summary">`,
+		`<table:table table:name="Roster">`,
+		`office:value="71.25"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content.xml does not contain %q", want)
+		}
+	}
+}
+
+func TestPublicGradebookExportIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	tmp := t.TempDir()
+	var outputs [][]byte
+	for _, name := range []string{"first.xlsx", "second.xlsx"} {
+		out := filepath.Join(tmp, name)
+		if exitCode, _, stderr := runPublicCommand(t, GradebookExport, []string{"-dir", dir, "-out", out}); exitCode != exitSuccess {
+			t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", out, err)
+		}
+		outputs = append(outputs, data)
+	}
+
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Fatal("gradebook-export output differs between runs")
+	}
+}
+
+func TestPublicGradebookExportErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"missing out": {
+			args: []string{"-dir", dir},
+			want: "gradebook-export: -out is required",
+		},
+		"unknown extension": {
+			args: []string{"-dir", dir, "-out", filepath.Join(t.TempDir(), "grades.csv")},
+			want: `gradebook-export: unknown format "csv"`,
+		},
+		"unknown format": {
+			args: []string{"-dir", dir, "-out", filepath.Join(t.TempDir(), "grades.xlsx"), "-format", "numbers"},
+			want: `gradebook-export: unknown format "numbers"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exitCode, _, stderr := runPublicCommand(t, GradebookExport, tc.args)
+			if exitCode != exitFailure {
+				t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+			}
+			if !strings.Contains(stderr, tc.want) {
+				t.Fatalf("stderr = %q; want it to contain %q", stderr, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/telemachus/gradebook"
+)
+
+const extraCreditCategory = "extra_credit"
+
+// extraCredit describes assignments that add points on top of a student's
+// weighted overall average instead of counting toward a category. Gradebook
+// files with one of the AssignmentTypes hold extra credit, and each record's
+// grade is a number of bonus points. A student's bonus is capped at
+// MaxPoints; a MaxPoints of zero means that there is no cap.
+type extraCredit struct {
+	AssignmentTypes []string `json:"assignment_types"`
+	MaxPoints       float64  `json:"max_points"`
+}
+
+func (ce *classExtras) hasExtraCredit() bool {
+	return ce != nil && ce.ExtraCredit != nil
+}
+
+func (ce *classExtras) isExtraCreditType(assignmentType string) bool {
+	return ce.hasExtraCredit() && slices.Contains(ce.ExtraCredit.AssignmentTypes, assignmentType)
+}
+
+func (ce *classExtras) checkExtraCredit(class *gradebook.Class) error {
+	if !ce.hasExtraCredit() {
+		return nil
+	}
+
+	ec := ce.ExtraCredit
+	errs := make([]error, 0, len(ec.AssignmentTypes)+2)
+	if len(ec.AssignmentTypes) == 0 {
+		errs = append(errs, errors.New("extra_credit must have at least one assignment type"))
+	}
+	if ec.MaxPoints < 0 {
+		errs = append(errs, errors.New("extra_credit must not have a negative max_points"))
+	}
+	for _, assignmentType := range ec.AssignmentTypes {
+		if _, ok := class.CategoriesByAssignmentType[assignmentType]; ok {
+			errs = append(errs, fmt.Errorf(
+				"extra credit assignment type %q must not be in categories_by_assignment_type",
+				assignmentType,
+			))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// extraCreditPoints returns each student's capped bonus points from the extra
+// credit assignments in gbFiles.
+func (ce *classExtras) extraCreditPoints(gbFiles []*gradebookFile) map[string]float64 {
+	if !ce.hasExtraCredit() {
+		return map[string]float64{}
+	}
+
+	bonusByEmail := make(map[string]float64)
+	for _, gbf := range gbFiles {
+		if !ce.isExtraCreditType(gbf.AssignmentType) {
+			continue
+		}
+
+		for _, ar := range gbf.AssignmentRecords {
+			points, ok := ce.grade(gbf, ar)
+			if !ok {
+				continue
+			}
+			bonusByEmail[ar.Email] += points
+		}
+	}
+
+	if ce.ExtraCredit.MaxPoints > 0 {
+		for email, points := range bonusByEmail {
+			bonusByEmail[email] = min(points, ce.ExtraCredit.MaxPoints)
+		}
+	}
+
+	return bonusByEmail
+}
+
+// withBonus adds bonus points to an overall average. A student without an
+// average has no average with extra credit either.
+func withBonus(total gradebook.AverageResult, bonus float64) gradebook.AverageResult {
+	if !total.Valid {
+		return total
+	}
+
+	return gradebook.AverageResult{Value: total.Value + bonus, Valid: true}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/telemachus/gradebook"
+)
+
+const extraCreditClassJSON = `{
+    "extra_credit": {
+        "assignment_types": ["bonus"],
+        "max_points": 5
+    }
+}`
+
+const extraCreditGradebook1JSON = `{
+    "assignment_category": "extra_credit",
+    "assignment_date": "20240320",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 3
+        },
+        {
+            "email": "alice@example.com",
+            "grade": 2
+        }
+    ],
+    "assignment_name": "museum",
+    "assignment_type": "bonus"
+}`
+
+const extraCreditGradebook2JSON = `{
+    "assignment_category": "extra_credit",
+    "assignment_date": "20240321",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 4
+        },
+        {
+            "email": "alice@example.com",
+            "grade": null
+        }
+    ],
+    "assignment_name": "lecture",
+    "assignment_type": "bonus"
+}`
+
+func writeExtraCreditFixture(t *testing.T) string {
+	t.Helper()
+
+	return writeFixtureDir(t, extraCreditClassJSON, map[string]string{
+		"quiz-quiz-1-20240319.gradebook":   gradebookFixtureJSON,
+		"bonus-museum-20240320.gradebook":  extraCreditGradebook1JSON,
+		"bonus-lecture-20240321.gradebook": extraCreditGradebook2JSON,
+	})
+}
+
+func TestPublicGradebookCalcExtraCredit(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtraCreditFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	// Bob earns 7 bonus points, but the cap is 5. Alice has bonus points but
+	// no average to add them to.
+	want := "" +
+		"Bob Young\n" +
+		"\tOverall average: 90\n" +
+		"\tExtra credit: +5\n" +
+		"\tOverall with extra credit: 95\n" +
+		"\tMajor: No results\n" +
+		"\tMinor: 90\n" +
+		"\tParticipation: No results\n" +
+		"Alice Zephyr\n" +
+		"\tOverall average: No results\n" +
+		"\tExtra credit: +2\n" +
+		"\tOverall with extra credit: No results\n" +
+		"\tMajor: No results\n" +
+		"\tMinor: No results\n" +
+		"\tParticipation: No results\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookUnscoredIgnoresExtraCredit(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtraCreditFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookUnscored, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	got, err := parseUnscoredOutput(stdout)
+	if err != nil {
+		t.Fatalf("parseUnscoredOutput() returned error: %v", err)
+	}
+
+	want := map[string]map[string]int{
+		"Bob Young": {
+			"Major":         0,
+			"Minor":         0,
+			"Participation": 0,
+		},
+		"Alice Zephyr": {
+			"Major":         0,
+			"Minor":         1,
+			"Participation": 0,
+		},
+	}
+
+	assertUnscoredCounts(t, got, want)
+}
+
+func TestPublicGradebookNewExtraCredit(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtraCreditFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookNew, []string{
+		"-dir", dir,
+		"-name", "concert",
+		"-type", "bonus",
+		"-date", "20240401",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	gb, err := gradebook.UnmarshalGradebook(filepath.Join(dir, "bonus-concert-20240401.gradebook"))
+	if err != nil {
+		t.Fatalf("failed to unmarshal created gradebook: %v", err)
+	}
+	if gb.AssignmentCategory != extraCreditCategory {
+		t.Fatalf("AssignmentCategory = %q; want %q", gb.AssignmentCategory, extraCreditCategory)
+	}
+}
+
+func TestUnmarshalClassRejectsWeightedExtraCreditType(t *testing.T) {
+	t.Parallel()
+
+	classData := classFixtureWith(t, `{
+    "extra_credit": {
+        "assignment_types": ["quiz"]
+    }
+}`)
+	classFile := writeClassFixture(t, classData)
+	cmd, stderr := newValidationCmd(classFile)
+
+	if class := cmd.unmarshalClass(); class != nil {
+		t.Fatal("unmarshalClass() returned non-nil class for weighted extra credit type")
+	}
+	if !strings.Contains(stderr.String(), `extra credit assignment type "quiz"`) {
+		t.Fatalf("stderr = %q; want extra credit error", stderr.String())
+	}
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	nameStyleLegal     = "legal"
+	nameStylePreferred = "preferred"
+	nameStyleLastFirst = "last-first"
+)
+
+// classExtras holds the parts of class.json that the gradebook package does
+// not model. The gradebook package ignores these fields, so a class.json that
+// uses them remains valid for the gradebook package.
+type classExtras struct {
+	StudentsByEmail                     map[string]*studentExtras `json:"students_by_email"`
+	LatePoliciesByAssignmentCategory    map[string]*latePolicy    `json:"late_policies_by_assignment_category"`
+	LabelsByStandard                    map[string]string         `json:"labels_by_standard"`
+	StandardsCalculation                *standardsCalculation     `json:"standards_calculation"`
+	RubricsByName                       map[string]*rubric        `json:"rubrics_by_name"`
+	ExtraCredit                         *extraCredit              `json:"extra_credit"`
+	MissingPoliciesByAssignmentCategory map[string]*missingPolicy `json:"missing_policies_by_assignment_category"`
+	Alerts                              *alertThresholds          `json:"alerts"`
+}
+
+// studentExtras holds optional information about a student beyond first and
+// last name.
+type studentExtras struct {
+	StudentID     string `json:"student_id,omitempty"`
+	PreferredName string `json:"preferred_name,omitempty"`
+	Pronouns      string `json:"pronouns,omitempty"`
+	Section       string `json:"section,omitempty"`
+}
+
+func unmarshalClassExtras(data []byte, classFile string) (*classExtras, error) {
+	var extras classExtras
+	if err := json.Unmarshal(data, &extras); err != nil {
+		return nil, fmt.Errorf("unmarshal class file %q: %w", classFile, err)
+	}
+
+	return &extras, nil
+}
+
+// validate checks the optional fields against class.
+func (ce *classExtras) validate(class *gradebook.Class) error {
+	return errors.Join(
+		ce.checkStudents(),
+		ce.checkLatePolicies(class),
+		ce.checkStandards(),
+		ce.checkRubrics(),
+		ce.checkExtraCredit(class),
+		ce.checkMissingPolicies(class),
+		ce.checkAlerts(),
+	)
+}
+
+// checkStudents checks the optional student fields. Student IDs must be
+// unique, and no optional field may have leading or trailing whitespace.
+func (ce *classExtras) checkStudents() error {
+	errs := make([]error, 0, len(ce.StudentsByEmail))
+	emailsByID := make(map[string]string, len(ce.StudentsByEmail))
+
+	for _, email := range slices.Sorted(maps.Keys(ce.StudentsByEmail)) {
+		se := ce.StudentsByEmail[email]
+		if se == nil {
+			continue
+		}
+
+		fields := [][2]string{
+			{"student_id", se.StudentID},
+			{"preferred_name", se.PreferredName},
+			{"pronouns", se.Pronouns},
+			{"section", se.Section},
+		}
+		for _, field := range fields {
+			if strings.TrimSpace(field[1]) != field[1] {
+				errs = append(errs, fmt.Errorf("student %q %s has leading or trailing whitespace", email, field[0]))
+			}
+		}
+
+		if se.StudentID == "" {
+			continue
+		}
+		if other, ok := emailsByID[se.StudentID]; ok {
+			errs = append(errs, fmt.Errorf("students %q and %q share student_id %q", other, email, se.StudentID))
+
+			continue
+		}
+		emailsByID[se.StudentID] = email
+	}
+
+	return errors.Join(errs...)
+}
+
+// student returns the optional information for the student with the given
+// email. It never returns nil.
+func (ce *classExtras) student(email string) *studentExtras {
+	if ce == nil || ce.StudentsByEmail[email] == nil {
+		return &studentExtras{}
+	}
+
+	return ce.StudentsByEmail[email]
+}
+
+// hasSection reports whether any student belongs to section.
+func (ce *classExtras) hasSection(section string) bool {
+	if ce == nil {
+		return false
+	}
+
+	for _, se := range ce.StudentsByEmail {
+		if se != nil && se.Section == section {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sections returns the sorted, distinct sections of all students. Students
+// without a section do not contribute to the result.
+func (ce *classExtras) sections() []string {
+	if ce == nil {
+		return []string{}
+	}
+
+	sections := make([]string, 0, len(ce.StudentsByEmail))
+	for _, se := range ce.StudentsByEmail {
+		if se == nil || se.Section == "" {
+			continue
+		}
+		sections = append(sections, se.Section)
+	}
+	slices.Sort(sections)
+
+	return slices.Compact(sections)
+}
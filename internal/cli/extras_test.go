@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const extrasClassFixtureJSON = `{
+    "name": "Characterization Test Class",
+    "terms_by_id": {
+        "q1": {
+            "start": "20240101",
+            "end": "20241231"
+        }
+    },
+    "assignment_categories": ["major", "minor", "cp"],
+    "labels_by_assignment_category": {
+        "major": "Major",
+        "minor": "Minor",
+        "cp": "Participation"
+    },
+    "weights_by_assignment_category": {
+        "major": 50,
+        "minor": 30,
+        "cp": 20
+    },
+    "categories_by_assignment_type": {
+        "quiz": "minor",
+        "test": "major",
+        "cp": "cp"
+    },
+    "students_by_email": {
+        "alice@example.com": {
+            "first_name": "Alice",
+            "last_name": "Zephyr",
+            "preferred_name": "Ali",
+            "pronouns": "she/her",
+            "student_id": "1001",
+            "section": "A"
+        },
+        "bob@example.com": {
+            "first_name": "Bob",
+            "last_name": "Young",
+            "student_id": "1002",
+            "section": "B"
+        },
+        "carol@example.com": {
+            "first_name": "Carol",
+            "last_name": "Xu",
+            "student_id": "1003",
+            "section": "A"
+        }
+    }
+}`
+
+func writeExtrasFixture(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	mustWriteFixtureFile(t, filepath.Join(dir, suiteClassFile), extrasClassFixtureJSON)
+	mustWriteFixtureFile(t, filepath.Join(dir, "quiz-quiz-1-20240319.gradebook"), gradebookFixtureJSON)
+
+	return dir
+}
+
+var extrasNamesCases = map[string]struct {
+	args []string
+	want string
+}{
+	"legal names": {
+		args: []string{},
+		want: "Carol Xu\nBob Young\nAlice Zephyr\n",
+	},
+	"preferred names": {
+		args: []string{"-name-style", "preferred"},
+		want: "Carol Xu\nBob Young\nAli Zephyr\n",
+	},
+	"last-first names": {
+		args: []string{"-name-style", "last-first"},
+		want: "Xu, Carol\nYoung, Bob\nZephyr, Alice\n",
+	},
+	"section A": {
+		args: []string{"-section", "A", "-name-style", "preferred"},
+		want: "Carol Xu\nAli Zephyr\n",
+	},
+}
+
+func TestPublicGradebookNamesWithExtras(t *testing.T) {
+	t.Parallel()
+
+	for testName, tt := range extrasNamesCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeExtrasFixture(t)
+			exitCode, stdout, stderr := runPublicCommand(t, GradebookNames, append([]string{"-dir", dir}, tt.args...))
+
+			if exitCode != exitSuccess {
+				t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+			}
+			if stdout != tt.want {
+				t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", tt.want, stdout)
+			}
+		})
+	}
+}
+
+func TestPublicGradebookEmailsSection(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtrasFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookEmails, []string{"-dir", dir, "-section", "B"})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if want := "bob@example.com\n"; stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookEmailsInvalidSection(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtrasFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookEmails, []string{"-dir", dir, "-section", "Z"})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if stdout != "" {
+		t.Fatalf("stdout = %q; want empty", stdout)
+	}
+	if want := "gradebook-emails: \"Z\" is not a valid section\n"; stderr != want {
+		t.Fatalf("stderr mismatch:\nwant:\n%q\ngot:\n%q", want, stderr)
+	}
+}
+
+func TestPublicGradebookNamesInvalidNameStyle(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtrasFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookNames, []string{"-dir", dir, "-name-style", "nickname"})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if want := "gradebook-names: invalid argument for -name-style: \"nickname\"\n"; stderr != want {
+		t.Fatalf("stderr mismatch:\nwant:\n%q\ngot:\n%q", want, stderr)
+	}
+}
+
+func TestUnmarshalClassRejectsDuplicateStudentIDs(t *testing.T) {
+	t.Parallel()
+
+	classData := strings.Replace(extrasClassFixtureJSON, `"student_id": "1002"`, `"student_id": "1001"`, 1)
+	classFile := writeClassFixture(t, classData)
+	cmd, stderr := newValidationCmd(classFile)
+
+	if class := cmd.unmarshalClass(); class != nil {
+		t.Fatal("unmarshalClass() returned non-nil class for duplicate student IDs")
+	}
+	if !strings.Contains(stderr.String(), `share student_id "1001"`) {
+		t.Fatalf("stderr = %q; want duplicate student_id error", stderr.String())
+	}
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// replaceFile atomically replaces fileName with data. The data is written to
+// a temporary file in the same directory, synced, and then renamed over
+// fileName, so a crash or a full disk never leaves a half-written file behind.
+func replaceFile(fileName string, data []byte) (err error) {
+	perm := os.FileMode(0o644)
+	if info, statErr := os.Stat(fileName); statErr == nil {
+		perm = info.Mode().Perm()
+	}
+
+	fh, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+"-*")
+	if err != nil {
+		return fmt.Errorf("create temporary file for %q: %w", fileName, err)
+	}
+	tmpName := fh.Name()
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		if removeErr := os.Remove(tmpName); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			err = errors.Join(err, fmt.Errorf("remove temporary file %q: %w", tmpName, removeErr))
+		}
+	}()
+
+	if _, err = fh.Write(data); err != nil {
+		_ = fh.Close()
+
+		return fmt.Errorf("write temporary file %q: %w", tmpName, err)
+	}
+	if err = fh.Sync(); err != nil {
+		_ = fh.Close()
+
+		return fmt.Errorf("sync temporary file %q: %w", tmpName, err)
+	}
+	if err = fh.Close(); err != nil {
+		return fmt.Errorf("close temporary file %q: %w", tmpName, err)
+	}
+	if err = os.Chmod(tmpName, perm); err != nil {
+		return fmt.Errorf("chmod temporary file %q: %w", tmpName, err)
+	}
+	if err = os.Rename(tmpName, fileName); err != nil {
+		return fmt.Errorf("rename %q to %q: %w", tmpName, fileName, err)
+	}
+
+	return nil
+}
+
+// marshalJSONFile marshals v the way the suite writes JSON files: indented
+// with four spaces, without HTML escaping, and with a trailing newline.
+func marshalJSONFile(v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("marshal json: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshalJSONValue marshals v without HTML escaping, for a value that goes
+// into a jsonObject.
+func marshalJSONValue(v any) (json.RawMessage, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("marshal json: %w", err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// jsonObject is a JSON object that keeps its keys in the order they were
+// read. Commands that rewrite part of a file read it into jsonObjects, so that
+// the fields they leave alone come back out as they went in.
+type jsonObject struct {
+	values map[string]json.RawMessage
+	keys   []string
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: make(map[string]json.RawMessage)}
+}
+
+// get returns the raw value of key, or nil if the object lacks it.
+func (obj *jsonObject) get(key string) json.RawMessage {
+	return obj.values[key]
+}
+
+// set gives key a raw value. A new key goes at the end of the object.
+func (obj *jsonObject) set(key string, value json.RawMessage) {
+	if _, ok := obj.values[key]; !ok {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.values[key] = value
+}
+
+// setValue marshals v and gives it to key.
+func (obj *jsonObject) setValue(key string, v any) error {
+	value, err := marshalJSONValue(v)
+	if err != nil {
+		return err
+	}
+	obj.set(key, value)
+
+	return nil
+}
+
+func (obj *jsonObject) delete(key string) {
+	if _, ok := obj.values[key]; !ok {
+		return
+	}
+	delete(obj.values, key)
+	obj.keys = slices.DeleteFunc(obj.keys, func(k string) bool { return k == key })
+}
+
+// UnmarshalJSON reads an object, keeping the order of its keys. If a key
+// appears twice, the last value wins, as with encoding/json.
+func (obj *jsonObject) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return errors.New("not a JSON object")
+	}
+
+	*obj = *newJSONObject()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("read key: %w", err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("object key %v is not a string", tok)
+		}
+
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return fmt.Errorf("read value of %q: %w", key, err)
+		}
+		obj.set(key, value)
+	}
+
+	return nil
+}
+
+// MarshalJSON writes the object with its keys in order.
+func (obj *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	for i, key := range obj.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := marshalJSONValue(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(obj.values[key])
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONObject(t *testing.T) {
+	t.Parallel()
+
+	obj := newJSONObject()
+	if err := json.Unmarshal([]byte(`{"b": 1, "a": "x & y", "c": [1, 2], "b": 3}`), obj); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	obj.delete("c")
+	if err := obj.setValue("d", "<tag>"); err != nil {
+		t.Fatalf("setValue() error = %v", err)
+	}
+
+	got, err := obj.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if want := `{"b":3,"a":"x & y","d":"<tag>"}`; string(got) != want {
+		t.Errorf("MarshalJSON() = %s; want %s", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`[1]`), newJSONObject()); err == nil {
+		t.Error("Unmarshal() of an array error = nil; want an error")
+	}
+}
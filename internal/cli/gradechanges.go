@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/telemachus/gradebook"
+)
+
+// gradeChange is a new grade for one student on one assignment. Old is nil if
+// the record was unscored or did not exist, and grade is nil if the record
+// becomes unscored. Comment is the record's new comment, or nil if the comment
+// stays as it was; an empty comment removes it.
+type gradeChange struct {
+	email      string
+	old        *float64
+	grade      *float64
+	comment    *string
+	oldComment string
+}
+
+// gradeChanged reports whether the change alters the grade, rather than only
+// the comment.
+func (change gradeChange) gradeChanged() bool {
+	return !sameGrade(change.old, change.grade)
+}
+
+// recordEdit is what rewriting a gradebook file does to one student's record.
+type recordEdit struct {
+	grade    *float64
+	setGrade bool
+	comment  *string
+}
+
+// gradeChanges collects the grade changes that an import would make, along
+// with anything the import had to skip, grouped under a heading.
+type gradeChanges struct {
+	byFile   map[*gradebookFile][]gradeChange
+	skipped  map[string][]string
+	headings []string
+}
+
+func newGradeChanges() *gradeChanges {
+	return &gradeChanges{
+		byFile:  make(map[*gradebookFile][]gradeChange),
+		skipped: make(map[string][]string),
+	}
+}
+
+func (gc *gradeChanges) add(gbf *gradebookFile, change gradeChange) {
+	gc.byFile[gbf] = append(gc.byFile[gbf], change)
+}
+
+func (gc *gradeChanges) skip(heading, item string) {
+	if _, ok := gc.skipped[heading]; !ok {
+		gc.headings = append(gc.headings, heading)
+	}
+	gc.skipped[heading] = append(gc.skipped[heading], item)
+}
+
+func (gc *gradeChanges) empty() bool {
+	return len(gc.byFile) == 0
+}
+
+// gbFiles returns the changed gradebook files, sorted by date.
+func (gc *gradeChanges) gbFiles() []*gradebookFile {
+	return sortedByDate(slices.Collect(maps.Keys(gc.byFile)))
+}
+
+func (cmd *cmdEnv) printGradeChanges(class *gradebook.Class, changes *gradeChanges) {
+	if cmd.noOp() {
+		return
+	}
+
+	for _, heading := range changes.headings {
+		fmt.Fprintf(cmd.stdout, "%s:\n", heading)
+		for _, item := range changes.skipped[heading] {
+			fmt.Fprintf(cmd.stdout, "\t%s\n", item)
+		}
+	}
+
+	if changes.empty() {
+		fmt.Fprintln(cmd.stdout, "No grade changes")
+
+		return
+	}
+
+	describe := func(grade *float64) string {
+		if grade == nil {
+			return "unscored"
+		}
+
+		return formatScore(*grade)
+	}
+
+	for _, gbf := range changes.gbFiles() {
+		fmt.Fprintf(cmd.stdout, "%s:\n", filepath.Base(gbf.path))
+		for _, change := range changes.byFile[gbf] {
+			var parts []string
+			if change.gradeChanged() {
+				parts = append(parts, describe(change.old)+" -> "+describe(change.grade))
+			}
+			if change.comment != nil {
+				parts = append(parts, fmt.Sprintf("comment %q -> %q", change.oldComment, *change.comment))
+			}
+
+			s := class.StudentsByEmail[change.email]
+			fmt.Fprintf(cmd.stdout, "\t%s %s <%s>: %s\n", s.FirstName, s.LastName, change.email, strings.Join(parts, ", "))
+		}
+	}
+}
+
+func (cmd *cmdEnv) confirmGradeChanges(changes *gradeChanges, yes bool) bool {
+	if cmd.noOp() || changes.empty() {
+		return false
+	}
+
+	return cmd.confirm(fmt.Sprintf("Rewrite %d gradebook file(s)?", len(changes.byFile)), yes)
+}
+
+func (cmd *cmdEnv) rewriteGrades(changes *gradeChanges) {
+	if cmd.noOp() {
+		return
+	}
+
+	if err := writeGradeChanges(cmd.keys, changes); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem rewriting grades: %s\n", cmd.name, err)
+	}
+}
+
+// writeGradeChanges rewrites each changed gradebook file. When a student's
+// grade or comment changed more than once, the last change wins.
+func writeGradeChanges(keys *keyring, changes *gradeChanges) error {
+	for _, gbf := range changes.gbFiles() {
+		edits := make(map[string]recordEdit, len(changes.byFile[gbf]))
+		for _, change := range changes.byFile[gbf] {
+			edit := edits[change.email]
+			if change.gradeChanged() {
+				edit.grade = change.grade
+				edit.setGrade = true
+			}
+			if change.comment != nil {
+				edit.comment = change.comment
+			}
+			edits[change.email] = edit
+		}
+
+		if err := rewriteGradebookGrades(keys, gbf.path, edits); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewriteGradebookGrades applies the edit of each student in editsByEmail to
+// a gradebook file, adding a record for any student who lacks one. A nil
+// grade makes the record unscored. Every other field in the file and in its
+// records is carried over unchanged.
+func rewriteGradebookGrades(keys *keyring, gbPath string, editsByEmail map[string]recordEdit) error {
+	data, sealed, err := keys.readFile(gbPath)
+	if err != nil {
+		return fmt.Errorf("read gradebook file %q: %w", gbPath, err)
+	}
+
+	raw := newJSONObject()
+	if err = json.Unmarshal(data, raw); err != nil {
+		return fmt.Errorf("unmarshal gradebook file %q: %w", gbPath, err)
+	}
+
+	var records []*jsonObject
+	if err = json.Unmarshal(raw.get("assignment_records"), &records); err != nil {
+		return fmt.Errorf("unmarshal assignment records in %q: %w", gbPath, err)
+	}
+
+	seen := make(map[string]bool, len(records))
+	for _, rec := range records {
+		var email string
+		if err = json.Unmarshal(rec.get("email"), &email); err != nil {
+			return fmt.Errorf("unmarshal email in %q: %w", gbPath, err)
+		}
+		seen[email] = true
+
+		if edit, ok := editsByEmail[email]; ok {
+			if err = edit.apply(rec); err != nil {
+				return err
+			}
+		}
+	}
+	for _, email := range slices.Sorted(maps.Keys(editsByEmail)) {
+		if seen[email] {
+			continue
+		}
+		rec := newJSONObject()
+		if err = rec.setValue("email", email); err != nil {
+			return err
+		}
+		edit := editsByEmail[email]
+		edit.setGrade = true
+		if err = edit.apply(rec); err != nil {
+			return err
+		}
+		records = append(records, rec)
+	}
+
+	if err = raw.setValue("assignment_records", records); err != nil {
+		return fmt.Errorf("marshal assignment records: %w", err)
+	}
+
+	out, err := marshalJSONFile(raw)
+	if err != nil {
+		return err
+	}
+
+	return keys.replaceFile(gbPath, out, sealed)
+}
+
+// apply makes the edit to a record. An empty comment removes the record's
+// comment, since a record without one leaves the field out.
+func (edit recordEdit) apply(rec *jsonObject) error {
+	if edit.setGrade {
+		if err := rec.setValue("grade", edit.grade); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case edit.comment == nil:
+	case *edit.comment == "":
+		rec.delete("comment")
+	default:
+		return rec.setValue("comment", *edit.comment)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRewriteGradebookGradesKeepsRecords(t *testing.T) {
+	t.Parallel()
+
+	// Keys are out of alphabetical order, and the comment has characters
+	// that encoding/json escapes by default.
+	const before = `{
+    "assignment_name": "quiz-1",
+    "assignment_type": "quiz",
+    "assignment_date": "20240319",
+    "assignment_category": "minor",
+    "assignment_records": [
+        {
+            "grade": 90,
+            "email": "bob@example.com",
+            "comment": "Q&A <see me>"
+        },
+        {
+            "email": "alice@example.com",
+            "grade": null,
+            "submitted": "20240320"
+        }
+    ]
+}
+`
+	gbPath := filepath.Join(t.TempDir(), "quiz-quiz-1-20240319.gradebook")
+	mustWriteFixtureFile(t, gbPath, before)
+
+	alice := 88.5
+	if err := rewriteGradebookGrades(nil, gbPath, map[string]recordEdit{
+		"alice@example.com": {grade: &alice, setGrade: true},
+		"carol@example.com": {},
+	}); err != nil {
+		t.Fatalf("rewriteGradebookGrades() error = %v", err)
+	}
+
+	got, err := os.ReadFile(gbPath)
+	if err != nil {
+		t.Fatalf("failed to read gradebook file: %v", err)
+	}
+	want := strings.Replace(before, `"grade": null,`, `"grade": 88.5,`, 1)
+	want = strings.Replace(want, `            "submitted": "20240320"
+        }
+    ]`, `            "submitted": "20240320"
+        },
+        {
+            "email": "carol@example.com",
+            "grade": null
+        }
+    ]`, 1)
+	if string(got) != want {
+		t.Errorf("rewritten file = %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,321 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	dateLayout      = "20060102"
+	gradebookSuffix = ".gradebook"
+)
+
+// gradebookFile represents a single gradebook file, including the optional
+// fields that the gradebook package does not model. The gradebook package
+// ignores those fields, so files that use them remain valid for it.
+type gradebookFile struct {
+	path               string
+	AssignmentDate     string              `json:"assignment_date"`
+	AssignmentName     string              `json:"assignment_name"`
+	AssignmentType     string              `json:"assignment_type"`
+	AssignmentCategory string              `json:"assignment_category"`
+	DueDate            string              `json:"due_date,omitempty"`
+	Rubric             string              `json:"rubric,omitempty"`
+	Curve              *curve              `json:"curve,omitempty"`
+	AssignmentRecords  []*assignmentRecord `json:"assignment_records"`
+}
+
+// assignmentRecord represents a grade for a particular student on
+// a particular assignment. Submitted is the YYYYMMDD date the work came in;
+// if it is empty, the work is treated as on time. Comment is optional
+// feedback for the student. ScoresByStandard holds optional mastery scores
+// for the learning standards that the assignment assesses. ScoresByCriterion
+// holds points for each criterion of the assignment's rubric, if it has one.
+//
+//nolint:govet // JSON field order matters more here than memory alignment.
+type assignmentRecord struct {
+	Email     string   `json:"email"`
+	Grade     *float64 `json:"grade"`
+	Submitted string   `json:"submitted,omitempty"`
+	Comment   string   `json:"comment,omitempty"`
+
+	ScoresByStandard  map[string]float64 `json:"scores_by_standard,omitempty"`
+	ScoresByCriterion map[string]float64 `json:"scores_by_criterion,omitempty"`
+}
+
+// dueDate returns the due date of the assignment. Without an explicit
+// due_date, work is due on the assignment date.
+func (gbf *gradebookFile) dueDate() string {
+	return cmp.Or(gbf.DueDate, gbf.AssignmentDate)
+}
+
+func readGradebookFile(store classStore, gbPath string) (*gradebookFile, error) {
+	data, err := store.readFile(gbPath)
+	if err != nil {
+		return nil, fmt.Errorf("read gradebook file %q: %w", gbPath, err)
+	}
+
+	var gbf gradebookFile
+	if err = json.Unmarshal(data, &gbf); err != nil {
+		return nil, fmt.Errorf("unmarshal gradebook file %q: %w", gbPath, err)
+	}
+	gbf.path = gbPath
+
+	return &gbf, nil
+}
+
+// loadGradebookFiles reads every gradebook file in store. If term is not nil,
+// only files whose names end in a date within the term are read. The files are
+// checked against class and extras: every assignment type must be known, every
+// record must belong to a student in the class, and so on.
+func loadGradebookFiles(
+	store classStore,
+	class *gradebook.Class,
+	extras *classExtras,
+	term *gradebook.Term,
+) ([]*gradebookFile, error) {
+	names, err := store.gradebookFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	gbFiles := make([]*gradebookFile, 0, len(names))
+	for _, name := range names {
+		if term != nil {
+			dateStr, err := fileNameDate(name)
+			if err != nil {
+				return nil, err
+			}
+			if !term.Includes(dateStr) {
+				continue
+			}
+		}
+
+		gbf, err := readGradebookFile(store, name)
+		if err != nil {
+			return nil, err
+		}
+		if err = gbf.check(class, extras); err != nil {
+			return nil, err
+		}
+
+		gbFiles = append(gbFiles, gbf)
+	}
+
+	return gbFiles, nil
+}
+
+// fileNameDate gets the YYYYMMDD date from the end of a gradebook file name.
+func fileNameDate(name string) (string, error) {
+	stem := strings.TrimSuffix(filepath.Base(name), gradebookSuffix)
+	if len(stem) < len(dateLayout) {
+		return "", fmt.Errorf("invalid yyyymmdd date in gradebook file name %q", filepath.Base(name))
+	}
+
+	dateStr := stem[len(stem)-len(dateLayout):]
+	if _, err := time.Parse(dateLayout, dateStr); err != nil {
+		return "", fmt.Errorf("invalid yyyymmdd date in gradebook file name %q", filepath.Base(name))
+	}
+
+	return dateStr, nil
+}
+
+func (gbf *gradebookFile) check(class *gradebook.Class, extras *classExtras) error {
+	_, ok := class.CategoriesByAssignmentType[gbf.AssignmentType]
+	if !ok && !extras.isExtraCreditType(gbf.AssignmentType) {
+		return fmt.Errorf("unrecognized assignment type %q in %q", gbf.AssignmentType, gbf.path)
+	}
+	if gbf.DueDate != "" {
+		if _, err := time.Parse(dateLayout, gbf.DueDate); err != nil {
+			return fmt.Errorf("invalid due_date %q in %q", gbf.DueDate, gbf.path)
+		}
+	}
+	if gbf.Rubric != "" && extras.rubric(gbf.Rubric) == nil {
+		return fmt.Errorf("unknown rubric %q in %q", gbf.Rubric, gbf.path)
+	}
+	if gbf.Curve != nil {
+		if err := gbf.Curve.check(); err != nil {
+			return fmt.Errorf("%w in %q", err, gbf.path)
+		}
+	}
+
+	return gbf.checkRecords(class, extras)
+}
+
+// checkRecords checks each record in the file. Every record must belong to
+// a student in the class, and no student may have two records, since both
+// would count toward the student's averages.
+func (gbf *gradebookFile) checkRecords(class *gradebook.Class, extras *classExtras) error {
+	seen := make(map[string]bool, len(gbf.AssignmentRecords))
+	for i, ar := range gbf.AssignmentRecords {
+		if ar == nil {
+			return fmt.Errorf("nil assignment record at index %d in %q", i, gbf.path)
+		}
+		if seen[ar.Email] {
+			return fmt.Errorf("more than one record for %q in %q", ar.Email, gbf.path)
+		}
+		seen[ar.Email] = true
+
+		student, ok := class.StudentsByEmail[ar.Email]
+		if !ok {
+			return fmt.Errorf("no student with email %q in %q", ar.Email, gbf.path)
+		}
+		if student == nil {
+			return fmt.Errorf("student with email %q is nil", ar.Email)
+		}
+
+		if ar.Submitted != "" {
+			if _, err := time.Parse(dateLayout, ar.Submitted); err != nil {
+				return fmt.Errorf("invalid submitted date %q for %q in %q", ar.Submitted, ar.Email, gbf.path)
+			}
+		}
+		if err := extras.checkStandardScores(ar); err != nil {
+			return fmt.Errorf("%w in %q", err, gbf.path)
+		}
+		if err := extras.checkCriterionScores(gbf.Rubric, ar); err != nil {
+			return fmt.Errorf("%w in %q", err, gbf.path)
+		}
+	}
+
+	return nil
+}
+
+// category returns the assignment category of the file's assignment type.
+// Extra credit assignments have no category.
+func (gbf *gradebookFile) category(class *gradebook.Class) string {
+	return class.CategoriesByAssignmentType[gbf.AssignmentType]
+}
+
+// addGrades adds the scored records in gbFiles to each student's
+// GradesByCategory. Each score is adjusted by the class's policies (e.g.,
+// late penalties) before it is added, and unscored records that are missing
+// on the YYYYMMDD date today are added as zeros.
+func addGrades(class *gradebook.Class, extras *classExtras, gbFiles []*gradebookFile, today string) {
+	for _, s := range class.StudentsByEmail {
+		s.GradesByCategory = make(map[string][]float64, len(class.AssignmentCategories))
+		for _, cat := range class.AssignmentCategories {
+			s.GradesByCategory[cat] = make([]float64, 0, len(gbFiles))
+		}
+	}
+
+	for _, gbf := range gbFiles {
+		cat := gbf.category(class)
+		if cat == "" {
+			continue
+		}
+
+		missing := extras.isMissing(cat, gbf, today)
+		for _, ar := range gbf.AssignmentRecords {
+			score, ok := extras.score(cat, gbf, ar)
+			if !ok && !missing {
+				continue
+			}
+
+			s := class.StudentsByEmail[ar.Email]
+			s.GradesByCategory[cat] = append(s.GradesByCategory[cat], score)
+		}
+	}
+}
+
+// addUnscored counts the unscored records in gbFiles in each student's
+// UnscoredByCategory.
+func addUnscored(class *gradebook.Class, extras *classExtras, gbFiles []*gradebookFile) {
+	for _, s := range class.StudentsByEmail {
+		s.UnscoredByCategory = make(map[string]int, len(class.AssignmentCategories))
+		for _, cat := range class.AssignmentCategories {
+			s.UnscoredByCategory[cat] = 0
+		}
+	}
+
+	for _, gbf := range gbFiles {
+		cat := gbf.category(class)
+		if cat == "" {
+			continue
+		}
+
+		for _, ar := range gbf.AssignmentRecords {
+			if _, ok := extras.grade(gbf, ar); ok {
+				continue
+			}
+			class.StudentsByEmail[ar.Email].UnscoredByCategory[cat]++
+		}
+	}
+}
+
+// grade returns a record's grade before any of the class's policies apply,
+// and false if the record is unscored. On an assignment with a rubric,
+// a record with criterion scores gets its grade from them.
+func (ce *classExtras) grade(gbf *gradebookFile, ar *assignmentRecord) (float64, bool) {
+	if gbf.Rubric != "" && len(ar.ScoresByCriterion) > 0 {
+		return ce.rubric(gbf.Rubric).grade(ar)
+	}
+	if ar.Grade == nil {
+		return 0, false
+	}
+
+	return *ar.Grade, true
+}
+
+// curvedGrade returns a record's grade after the file's curve, if any, and
+// false if the record is unscored.
+func (ce *classExtras) curvedGrade(gbf *gradebookFile, ar *assignmentRecord) (float64, bool) {
+	grade, ok := ce.grade(gbf, ar)
+	if !ok {
+		return 0, false
+	}
+
+	return gbf.Curve.apply(grade), true
+}
+
+// score returns the score that counts toward a student's average for
+// a record, and false if the record has no score. The file's curve applies
+// before any late penalty.
+func (ce *classExtras) score(cat string, gbf *gradebookFile, ar *assignmentRecord) (float64, bool) {
+	score, ok := ce.curvedGrade(gbf, ar)
+	if !ok {
+		return 0, false
+	}
+
+	if penalty := ce.latePenalty(cat, gbf, ar); penalty > 0 {
+		score = max(score-penalty, 0)
+	}
+
+	return score, true
+}
+
+// sortedByDate returns a copy of gbFiles sorted by assignment date and then by
+// assignment name.
+func sortedByDate(gbFiles []*gradebookFile) []*gradebookFile {
+	sorted := slices.Clone(gbFiles)
+	slices.SortStableFunc(sorted, func(gbfA, gbfB *gradebookFile) int {
+		return cmp.Or(
+			cmp.Compare(gbfA.AssignmentDate, gbfB.AssignmentDate),
+			cmp.Compare(gbfA.AssignmentName, gbfB.AssignmentName),
+		)
+	})
+
+	return sorted
+}
+
+func (cmd *cmdEnv) readGradebooks(class *gradebook.Class, term string) []*gradebookFile {
+	if cmd.noOp() {
+		return nil
+	}
+
+	gbFiles, err := loadGradebookFiles(cmd.classStore(), class, cmd.extras, class.TermsByID[term])
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return nil
+	}
+
+	return gbFiles
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/telemachus/gradebook"
+)
+
+const commentGradebookFixtureJSON = `{
+    "assignment_category": "minor",
+    "assignment_date": "20240319",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 90,
+            "comment": "Clear & well organized."
+        },
+        {
+            "email": "alice@example.com",
+            "grade": null
+        }
+    ],
+    "assignment_name": "quiz-1",
+    "assignment_type": "quiz"
+}`
+
+func TestLoadGradebookFilesKeepsComments(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	gbPath := filepath.Join(dir, "quiz-quiz-1-20240319.gradebook")
+	mustWriteFixtureFile(t, gbPath, commentGradebookFixtureJSON)
+
+	class, err := gradebook.UnmarshalClass(filepath.Join(dir, suiteClassFile))
+	if err != nil {
+		t.Fatalf("failed to unmarshal class: %v", err)
+	}
+
+	gbFiles, err := loadGradebookFiles(dirStore{dir: dir}, class, nil, nil)
+	if err != nil {
+		t.Fatalf("loadGradebookFiles() returned error: %v", err)
+	}
+	if len(gbFiles) != 1 {
+		t.Fatalf("len(gbFiles) = %d; want 1", len(gbFiles))
+	}
+
+	recs := gbFiles[0].AssignmentRecords
+	if got, want := recs[0].Comment, "Clear & well organized."; got != want {
+		t.Fatalf("Comment = %q; want %q", got, want)
+	}
+	if recs[1].Comment != "" {
+		t.Fatalf("Comment = %q; want empty", recs[1].Comment)
+	}
+
+	// The gradebook package must still accept files with comments.
+	if _, err = gradebook.UnmarshalGradebook(gbPath); err != nil {
+		t.Fatalf("gradebook.UnmarshalGradebook() returned error: %v", err)
+	}
+}
+
+func TestAssignmentRecordOmitsEmptyOptionalFields(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(&assignmentRecord{Email: "bob@example.com"})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	if want := `{"email":"bob@example.com","grade":null}`; string(data) != want {
+		t.Fatalf("json.Marshal() = %s; want %s", data, want)
+	}
+}
+
+func TestLoadGradebookFilesUnknownStudent(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	gbData := strings.Replace(gradebookFixtureJSON, "alice@example.com", "carol@example.com", 1)
+	mustWriteFixtureFile(t, filepath.Join(dir, "quiz-quiz-1-20240319.gradebook"), gbData)
+
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if stdout != "" {
+		t.Fatalf("stdout = %q; want empty", stdout)
+	}
+	if !strings.Contains(stderr, `no student with email "carol@example.com"`) {
+		t.Fatalf("stderr = %q; want unknown student error", stderr)
+	}
+}
+
+func TestLoadGradebookFilesDuplicateRecord(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	gbFile := filepath.Join(dir, "quiz-quiz-1-20240319.gradebook")
+	gbData := strings.Replace(gradebookFixtureJSON, "alice@example.com", "bob@example.com", 1)
+	mustWriteFixtureFile(t, gbFile, gbData)
+
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if stdout != "" {
+		t.Fatalf("stdout = %q; want empty", stdout)
+	}
+	if want := `more than one record for "bob@example.com" in "` + gbFile + `"`; !strings.Contains(stderr, want) {
+		t.Fatalf("stderr = %q; want it to contain %q", stderr, want)
+	}
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/telemachus/gradebook"
+)
+
+// latePolicy describes how late work in a category is penalized. Each day
+// that work is late, after the first GraceDays days, costs PercentPerDay
+// points, up to MaxPercent points in total. A MaxPercent of zero means that
+// there is no cap. Scores never drop below zero.
+type latePolicy struct {
+	PercentPerDay float64 `json:"percent_per_day"`
+	MaxPercent    float64 `json:"max_percent"`
+	GraceDays     int     `json:"grace_days"`
+}
+
+// GradebookLate lists late submissions for each student in a class.
+func GradebookLate(args []string) int {
+	cmd := cmdFrom("gradebook-late", lateUsage)
+
+	return runCommand(cmd, args, commandRun[string]{
+		parse:     (*cmdEnv).parseCalculate,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, term string) {
+			cmd.findTerm(class, term)
+			cmd.findSection()
+			gbFiles := cmd.readGradebooks(class, term)
+			cmd.printLate(class, gbFiles)
+		},
+	})
+}
+
+func (ce *classExtras) checkLatePolicies(class *gradebook.Class) error {
+	if ce == nil {
+		return nil
+	}
+
+	errs := make([]error, 0, len(ce.LatePoliciesByAssignmentCategory))
+	for _, cat := range slices.Sorted(maps.Keys(ce.LatePoliciesByAssignmentCategory)) {
+		lp := ce.LatePoliciesByAssignmentCategory[cat]
+
+		switch {
+		case !slices.Contains(class.AssignmentCategories, cat):
+			errs = append(errs, fmt.Errorf("late policy for unknown assignment category %q", cat))
+		case lp == nil:
+			errs = append(errs, fmt.Errorf("late policy for %q is nil", cat))
+		case lp.PercentPerDay <= 0:
+			errs = append(errs, fmt.Errorf("late policy for %q must have a positive percent_per_day", cat))
+		case lp.MaxPercent < 0:
+			errs = append(errs, fmt.Errorf("late policy for %q must not have a negative max_percent", cat))
+		case lp.GraceDays < 0:
+			errs = append(errs, fmt.Errorf("late policy for %q must not have negative grace_days", cat))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// latePenalty returns the number of points to take off a record's grade
+// under the late policy for cat.
+func (ce *classExtras) latePenalty(cat string, gbf *gradebookFile, ar *assignmentRecord) float64 {
+	if ce == nil || ce.LatePoliciesByAssignmentCategory[cat] == nil {
+		return 0
+	}
+
+	lp := ce.LatePoliciesByAssignmentCategory[cat]
+	days := daysLate(gbf.dueDate(), ar.Submitted) - lp.GraceDays
+	if days <= 0 {
+		return 0
+	}
+
+	penalty := float64(days) * lp.PercentPerDay
+	if lp.MaxPercent > 0 {
+		penalty = min(penalty, lp.MaxPercent)
+	}
+
+	return penalty
+}
+
+// daysLate returns how many days after due work was submitted. Both dates
+// are YYYYMMDD strings that have already been validated. Work without
+// a submitted date is on time.
+func daysLate(due, submitted string) int {
+	if submitted == "" {
+		return 0
+	}
+
+	dueDate, err := time.Parse(dateLayout, due)
+	if err != nil {
+		return 0
+	}
+	submittedDate, err := time.Parse(dateLayout, submitted)
+	if err != nil {
+		return 0
+	}
+
+	return max(int(submittedDate.Sub(dueDate).Hours()/24), 0)
+}
+
+type lateSubmission struct {
+	gbf *gradebookFile
+	ar  *assignmentRecord
+	cat string
+}
+
+func (cmd *cmdEnv) printLate(class *gradebook.Class, gbFiles []*gradebookFile) {
+	if cmd.noOp() {
+		return
+	}
+
+	lateByEmail := make(map[string][]lateSubmission)
+	for _, gbf := range gbFiles {
+		for _, ar := range gbf.AssignmentRecords {
+			if daysLate(gbf.dueDate(), ar.Submitted) == 0 {
+				continue
+			}
+			lateByEmail[ar.Email] = append(lateByEmail[ar.Email], lateSubmission{
+				gbf: gbf,
+				ar:  ar,
+				cat: gbf.category(class),
+			})
+		}
+	}
+
+	found := false
+	for _, email := range cmd.emailsInSection(class) {
+		late := lateByEmail[email]
+		if len(late) == 0 {
+			continue
+		}
+		found = true
+
+		slices.SortFunc(late, func(a, b lateSubmission) int {
+			return cmp.Or(
+				cmp.Compare(a.gbf.dueDate(), b.gbf.dueDate()),
+				cmp.Compare(a.gbf.AssignmentName, b.gbf.AssignmentName),
+			)
+		})
+
+		s := class.StudentsByEmail[email]
+		fmt.Fprintf(cmd.stdout, "%s %s\n", s.FirstName, s.LastName)
+		for _, ls := range late {
+			fmt.Fprintf(cmd.stdout, "\t%s\n", cmd.extras.describeLate(ls))
+		}
+	}
+
+	if !found {
+		fmt.Fprintln(cmd.stdout, "No late submissions")
+	}
+}
+
+func (ce *classExtras) describeLate(ls lateSubmission) string {
+	days := daysLate(ls.gbf.dueDate(), ls.ar.Submitted)
+	word := "days"
+	if days == 1 {
+		word = "day"
+	}
+	desc := fmt.Sprintf("%s (due %s): %d %s late", ls.gbf.AssignmentName, ls.gbf.dueDate(), days, word)
+
+	grade, ok := ce.curvedGrade(ls.gbf, ls.ar)
+	if !ok {
+		return desc + ", unscored"
+	}
+
+	penalty := ce.latePenalty(ls.cat, ls.gbf, ls.ar)
+	if penalty == 0 {
+		return desc + ", no penalty"
+	}
+
+	score, _ := ce.score(ls.cat, ls.gbf, ls.ar)
+
+	return fmt.Sprintf(
+		"%s, -%s (%s -> %s)",
+		desc,
+		formatScore(penalty),
+		formatScore(grade),
+		formatScore(score),
+	)
+}
+
+// formatScore formats a score rounded to two decimal places and without
+// trailing zeros.
+func formatScore(score float64) string {
+	return strconv.FormatFloat(roundScore(score), 'f', -1, 64)
+}
+
+// roundScore rounds a score to two decimal places, the precision that
+// formatScore prints.
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
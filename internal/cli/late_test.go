@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+const latePoliciesJSON = `{
+    "late_policies_by_assignment_category": {
+        "minor": {
+            "percent_per_day": 10,
+            "max_percent": 25,
+            "grace_days": 1
+        }
+    }
+}`
+
+const lateGradebookFixtureJSON = `{
+    "assignment_category": "minor",
+    "assignment_date": "20240319",
+    "due_date": "20240320",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 90,
+            "submitted": "20240323"
+        },
+        {
+            "email": "alice@example.com",
+            "grade": 80,
+            "submitted": "20240321"
+        }
+    ],
+    "assignment_name": "quiz-1",
+    "assignment_type": "quiz"
+}`
+
+func writeLateFixture(t *testing.T) string {
+	t.Helper()
+
+	return writeFixtureDir(t, latePoliciesJSON, map[string]string{
+		"quiz-quiz-1-20240319.gradebook": lateGradebookFixtureJSON,
+	})
+}
+
+func TestPublicGradebookCalcAppliesLatePenalty(t *testing.T) {
+	t.Parallel()
+
+	dir := writeLateFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	// Bob is three days late with one grace day: 90 - 2*10 = 70.
+	// Alice is one day late, which the grace period covers.
+	want := "" +
+		"Bob Young\n" +
+		"\tOverall average: 70\n" +
+		"\tMajor: No results\n" +
+		"\tMinor: 70\n" +
+		"\tParticipation: No results\n" +
+		"Alice Zephyr\n" +
+		"\tOverall average: 80\n" +
+		"\tMajor: No results\n" +
+		"\tMinor: 80\n" +
+		"\tParticipation: No results\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookLate(t *testing.T) {
+	t.Parallel()
+
+	dir := writeLateFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookLate, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "" +
+		"Bob Young\n" +
+		"\tquiz-1 (due 20240320): 3 days late, -20 (90 -> 70)\n" +
+		"Alice Zephyr\n" +
+		"\tquiz-1 (due 20240320): 1 day late, no penalty\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookLateNone(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookLate, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if want := "No late submissions\n"; stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+var latePenaltyCases = map[string]struct {
+	policy    *latePolicy
+	submitted string
+	want      float64
+}{
+	"on time": {
+		policy:    &latePolicy{PercentPerDay: 10},
+		submitted: "20240320",
+		want:      0,
+	},
+	"early": {
+		policy:    &latePolicy{PercentPerDay: 10},
+		submitted: "20240318",
+		want:      0,
+	},
+	"no submitted date": {
+		policy: &latePolicy{PercentPerDay: 10},
+		want:   0,
+	},
+	"two days late": {
+		policy:    &latePolicy{PercentPerDay: 10},
+		submitted: "20240322",
+		want:      20,
+	},
+	"within grace period": {
+		policy:    &latePolicy{PercentPerDay: 10, GraceDays: 2},
+		submitted: "20240322",
+		want:      0,
+	},
+	"capped": {
+		policy:    &latePolicy{PercentPerDay: 10, MaxPercent: 30},
+		submitted: "20240401",
+		want:      30,
+	},
+	"no policy": {
+		submitted: "20240401",
+		want:      0,
+	},
+}
+
+func TestLatePenalty(t *testing.T) {
+	t.Parallel()
+
+	gbf := &gradebookFile{AssignmentDate: "20240319", DueDate: "20240320"}
+
+	for testName, tt := range latePenaltyCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			extras := &classExtras{
+				LatePoliciesByAssignmentCategory: map[string]*latePolicy{"minor": tt.policy},
+			}
+			ar := &assignmentRecord{Email: "bob@example.com", Submitted: tt.submitted}
+
+			if got := extras.latePenalty("minor", gbf, ar); got != tt.want {
+				t.Fatalf("latePenalty() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalClassRejectsInvalidLatePolicy(t *testing.T) {
+	t.Parallel()
+
+	classData := classFixtureWith(t, `{
+    "late_policies_by_assignment_category": {
+        "homework": {"percent_per_day": 10}
+    }
+}`)
+	classFile := writeClassFixture(t, classData)
+	cmd, stderr := newValidationCmd(classFile)
+
+	if class := cmd.unmarshalClass(); class != nil {
+		t.Fatal("unmarshalClass() returned non-nil class for invalid late policy")
+	}
+	if !strings.Contains(stderr.String(), `late policy for unknown assignment category "homework"`) {
+		t.Fatalf("stderr = %q; want late policy error", stderr.String())
+	}
+}
@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/telemachus/gradebook"
+)
+
+// Canvas gradebook CSV files begin with these columns. Every other column is
+// an assignment or a score that Canvas calculates.
+const (
+	canvasStudent        = "Student"
+	canvasID             = "ID"
+	canvasSISUserID      = "SIS User ID"
+	canvasSISLoginID     = "SIS Login ID"
+	canvasSection        = "Section"
+	canvasPointsPossible = "Points Possible"
+)
+
+// canvasFixedColumns lists the columns of a Canvas gradebook CSV that
+// describe students rather than assignments.
+var canvasFixedColumns = []string{
+	canvasStudent,
+	canvasID,
+	canvasSISUserID,
+	canvasSISLoginID,
+	"Integration ID",
+	canvasSection,
+	"Root Account",
+}
+
+// canvasAssignmentID matches the Canvas assignment ID at the end of an
+// assignment column's header, as in "Quiz 1 (12345)".
+var canvasAssignmentID = regexp.MustCompile(`\s*\(\d+\)$`)
+
+// GradebookLMS translates between gradebook files and the gradebook CSV files
+// that learning management systems (LMSs) import and export.
+func GradebookLMS(args []string) int {
+	cmd := cmdFrom("gradebook-lms", lmsUsage)
+
+	return runCommand(cmd, args, commandRun[lmsCfg]{
+		parse:     (*cmdEnv).parseLMS,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg lmsCfg) {
+			switch cfg.subcommand {
+			case "export":
+				cmd.findTerm(class, cfg.term)
+				cmd.findSection()
+				gbFiles := cmd.loadGrades(class, cfg.term)
+				cmd.exportLMS(cmd.lmsGrades(class, gbFiles), cfg.format)
+			default:
+				gbFiles := cmd.readGradebooks(class, "")
+				sheet := cmd.readCanvas(cfg.file)
+				changes := cmd.matchCanvas(class, gbFiles, sheet)
+				cmd.printGradeChanges(class, changes)
+				if !cmd.confirmGradeChanges(changes, cfg.yes) {
+					return
+				}
+				cmd.rewriteGrades(changes)
+			}
+		},
+	})
+}
+
+type lmsCfg struct {
+	subcommand string
+	file       string
+	format     string
+	term       string
+	yes        bool
+}
+
+func (cmd *cmdEnv) parseLMS(args []string) lmsCfg {
+	var cfg lmsCfg
+	cfg.subcommand, args = splitSubcommand(args)
+
+	og := cmd.commonOptsGroup(parseOpts{section: true})
+	og.String(&cfg.file, "file", "")
+	og.String(&cfg.format, "format", lmsFormatCanvas)
+	og.String(&cfg.term, "term", "")
+	og.Bool(&cfg.yes, "yes")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkSubcommand(cfg.subcommand, "export", "import")
+	if cmd.noOp() {
+		return cfg
+	}
+
+	switch {
+	case cfg.subcommand == "import" && cfg.file == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -file is required\n", cmd.name)
+	case cfg.subcommand == "import" && cfg.format != lmsFormatCanvas:
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: import supports only -format %q\n", cmd.name, lmsFormatCanvas)
+	case lmsExporters[cfg.format] == nil:
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: invalid argument for -format: %q (want one of %q)\n", cmd.name, cfg.format, lmsFormats())
+	}
+
+	return cfg
+}
+
+// exportLMS prints grades in the layout of the given LMS format.
+func (cmd *cmdEnv) exportLMS(grades *lmsGrades, format string) {
+	if cmd.noOp() {
+		return
+	}
+
+	if err := lmsExporters[format].export(cmd.stdout, grades); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+	}
+}
+
+// canvasSheet is the content of a Canvas gradebook CSV.
+type canvasSheet struct {
+	header []string
+	points []string
+	rows   [][]string
+}
+
+func (cmd *cmdEnv) readCanvas(file string) *canvasSheet {
+	if cmd.noOp() {
+		return nil
+	}
+
+	sheet, err := readCanvasCSV(file)
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return nil
+	}
+
+	return sheet
+}
+
+func readCanvasCSV(file string) (sheet *canvasSheet, err error) {
+	fh, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return nil, fmt.Errorf("open Canvas CSV %q: %w", file, err)
+	}
+	defer func() {
+		if closeErr := fh.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close Canvas CSV %q: %w", file, closeErr))
+		}
+	}()
+
+	rdr := csv.NewReader(fh)
+	rdr.FieldsPerRecord = -1
+
+	header, err := rdr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header of Canvas CSV %q: %w", file, err)
+	}
+	for i, h := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+	if !slices.Contains(header, canvasStudent) {
+		return nil, fmt.Errorf("missing column %q in Canvas CSV %q", canvasStudent, file)
+	}
+
+	sheet = &canvasSheet{header: header}
+	for {
+		row, err := rdr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read Canvas CSV %q: %w", file, err)
+		}
+
+		if strings.TrimSpace(row[0]) == canvasPointsPossible {
+			sheet.points = row
+
+			continue
+		}
+		sheet.rows = append(sheet.rows, row)
+	}
+
+	return sheet, nil
+}
+
+// field returns the trimmed value of the named column in row, or "" if the
+// sheet has no such column.
+func (cs *canvasSheet) field(row []string, column string) string {
+	idx := slices.Index(cs.header, column)
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(row[idx])
+}
+
+// pointsPossible returns the points possible for column idx. Without
+// a Points Possible row, every assignment is out of 100 points. Columns that
+// Canvas calculates are marked "(read only)" and have no points possible.
+func (cs *canvasSheet) pointsPossible(idx int) (float64, bool) {
+	if cs.points == nil {
+		return 100, true
+	}
+	if idx >= len(cs.points) {
+		return 0, false
+	}
+
+	points, err := strconv.ParseFloat(strings.TrimSpace(cs.points[idx]), 64)
+	if err != nil || points <= 0 {
+		return 0, false
+	}
+
+	return points, true
+}
+
+// matchCanvas compares the grades in a Canvas gradebook CSV with the grades
+// in gbFiles. Students match by SIS Login ID (their email) or by SIS User ID
+// (their student_id), and assignment columns match gradebook files by
+// assignment name. Scores are converted to percentages of the column's points
+// possible. Blank and excused ("EX") scores are ignored.
+func (cmd *cmdEnv) matchCanvas(class *gradebook.Class, gbFiles []*gradebookFile, sheet *canvasSheet) *gradeChanges {
+	if cmd.noOp() {
+		return nil
+	}
+
+	changes := newGradeChanges()
+
+	emailsByID := make(map[string]string)
+	for email := range class.StudentsByEmail {
+		if id := cmd.extras.student(email).StudentID; id != "" {
+			emailsByID[id] = email
+		}
+	}
+
+	emails := make([]string, len(sheet.rows))
+	for i, row := range sheet.rows {
+		login, id := sheet.field(row, canvasSISLoginID), sheet.field(row, canvasSISUserID)
+		switch {
+		case class.StudentsByEmail[login] != nil:
+			emails[i] = login
+		case emailsByID[id] != "":
+			emails[i] = emailsByID[id]
+		default:
+			changes.skip("Skipped students not in class", sheet.field(row, canvasStudent))
+		}
+	}
+
+	for idx, column := range sheet.header {
+		if slices.Contains(canvasFixedColumns, column) {
+			continue
+		}
+
+		gbf, err := findByAssignmentName(gbFiles, canvasAssignmentID.ReplaceAllString(column, ""))
+		points, ok := sheet.pointsPossible(idx)
+		if err != nil || !ok || cmd.extras.isExtraCreditType(gbf.AssignmentType) {
+			changes.skip("Skipped columns", column)
+
+			continue
+		}
+
+		records := recordsByEmail(gbf)
+		for i, row := range sheet.rows {
+			if emails[i] == "" || idx >= len(row) {
+				continue
+			}
+
+			value := strings.TrimSpace(row[idx])
+			if value == "" || value == "-" || strings.EqualFold(value, "EX") {
+				continue
+			}
+			score, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				cmd.exitValue = exitFailure
+				fmt.Fprintf(cmd.stderr, "%s: invalid score %q for %q in column %q\n", cmd.name, value, emails[i], column)
+
+				return nil
+			}
+
+			grade := math.Round(score/points*100*100) / 100
+			ar := records[emails[i]]
+			switch {
+			case ar != nil && gbf.Rubric != "" && len(ar.ScoresByCriterion) > 0:
+				changes.skip("Skipped records", fmt.Sprintf("%s <%s> (scored by rubric)", gbf.AssignmentName, emails[i]))
+			case ar == nil:
+				changes.add(gbf, gradeChange{email: emails[i], grade: &grade})
+			case ar.Grade == nil || *ar.Grade != grade:
+				changes.add(gbf, gradeChange{email: emails[i], old: ar.Grade, grade: &grade})
+			}
+		}
+	}
+
+	return changes
+}
+
+// findByAssignmentName returns the one gradebook file with the given
+// assignment name.
+func findByAssignmentName(gbFiles []*gradebookFile, name string) (*gradebookFile, error) {
+	var found *gradebookFile
+	for _, gbf := range gbFiles {
+		if gbf.AssignmentName != name {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("more than one gradebook file for assignment %q", name)
+		}
+		found = gbf
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no gradebook file for assignment %q", name)
+	}
+
+	return found, nil
+}
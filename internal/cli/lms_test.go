@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const lmsCanvasCSV = "\ufeffStudent,ID,SIS User ID,SIS Login ID,Section,quiz-1 (555),Current Score\n" +
+	"    Points Possible,,,,,20,(read only)\n" +
+	"\"Zephyr, Alice\",11,1001,,A,17,85\n" +
+	"\"Young, Bob\",12,,bob@example.com,B,18,90\n" +
+	"\"Xu, Carol\",13,1003,,A,10,50\n" +
+	"\"Student, Test\",14,,,,5,25\n"
+
+func TestPublicGradebookLMSExport(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtrasFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookLMS, []string{"export", "-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "Student,ID,SIS User ID,SIS Login ID,Section,quiz-1\n" +
+		"\"    Points Possible\",,,,,100\n" +
+		"\"Xu, Carol\",,1003,carol@example.com,A,\n" +
+		"\"Young, Bob\",,1002,bob@example.com,B,90\n" +
+		"\"Zephyr, Alice\",,1001,alice@example.com,A,\n"
+	if stdout != want {
+		t.Fatalf("stdout = %q; want %q", stdout, want)
+	}
+}
+
+func TestPublicGradebookLMSImport(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtrasFixture(t)
+	csvFile := filepath.Join(dir, "canvas.csv")
+	mustWriteFixtureFile(t, csvFile, lmsCanvasCSV)
+
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookLMS, []string{"import", "-dir", dir, "-file", csvFile, "-yes"})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	for _, want := range []string{
+		"Skipped columns:\n\tCurrent Score\n",
+		"Skipped students not in class:\n\tStudent, Test\n",
+		"quiz-quiz-1-20240319.gradebook:\n",
+		"\tAlice Zephyr <alice@example.com>: unscored -> 85\n",
+		"\tCarol Xu <carol@example.com>: unscored -> 50\n",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q; want it to contain %q", stdout, want)
+		}
+	}
+	if strings.Contains(stdout, "bob@example.com") {
+		t.Errorf("stdout = %q; want no change for an unchanged grade", stdout)
+	}
+
+	gbf, err := readGradebookFile(dirStore{}, filepath.Join(dir, "quiz-quiz-1-20240319.gradebook"))
+	if err != nil {
+		t.Fatalf("failed to read gradebook: %v", err)
+	}
+	got := make(map[string]float64)
+	for email, ar := range recordsByEmail(gbf) {
+		if ar.Grade != nil {
+			got[email] = *ar.Grade
+		}
+	}
+	want := map[string]float64{"alice@example.com": 85, "bob@example.com": 90, "carol@example.com": 50}
+	for email, grade := range want {
+		if got[email] != grade {
+			t.Errorf("grade for %s = %v; want %v", email, got[email], grade)
+		}
+	}
+}
+
+func TestPublicGradebookLMSErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := writeExtrasFixture(t)
+	badCSV := filepath.Join(dir, "bad.csv")
+	mustWriteFixtureFile(t, badCSV, "Student,SIS User ID,quiz-1\n\"Zephyr, Alice\",1001,great\n")
+
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"missing file": {
+			args: []string{"import", "-dir", dir},
+			want: "gradebook-lms: -file is required",
+		},
+		"invalid score": {
+			args: []string{"import", "-dir", dir, "-file", badCSV},
+			want: `gradebook-lms: invalid score "great" for "alice@example.com" in column "quiz-1"`,
+		},
+		"unknown format": {
+			args: []string{"export", "-dir", dir, "-format", "sakai"},
+			want: `gradebook-lms: invalid argument for -format: "sakai"`,
+		},
+		"import other format": {
+			args: []string{"import", "-dir", dir, "-file", badCSV, "-format", "moodle"},
+			want: `gradebook-lms: import supports only -format "canvas"`,
+		},
+		"unknown subcommand": {
+			args: []string{"sync", "-dir", dir},
+			want: `gradebook-lms: unknown subcommand: "sync"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exitCode, _, stderr := runPublicCommand(t, GradebookLMS, tc.args)
+			if exitCode != exitFailure {
+				t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+			}
+			if !strings.Contains(stderr, tc.want) {
+				t.Fatalf("stderr = %q; want it to contain %q", stderr, tc.want)
+			}
+		})
+	}
+}
+
+func TestPublicGradebookLMSExportFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"moodle": "First name,Last name,ID number,Email address," +
+			"Assignment: quiz-1 (Real),Assignment: test-1 (Real)," +
+			"Category total: Major (Real),Category total: Minor (Real),Category total: Participation (Real)," +
+			"Course total (Real)\n" +
+			"Bob,Young,,bob@example.com,90,60,60,90,-,71.25\n" +
+			"Alice,Zephyr,,alice@example.com,-,70,70,-,-,70\n",
+		"blackboard": "Last Name,First Name,Username,Student ID," +
+			"quiz-1 [Total Pts: 100 Score],test-1 [Total Pts: 100 Score]," +
+			"Major [Total Pts: 100 Percentage],Minor [Total Pts: 100 Percentage]," +
+			"Participation [Total Pts: 100 Percentage],Overall [Total Pts: 100 Percentage]\n" +
+			"Young,Bob,bob@example.com,,90,60,60,90,,71.25\n" +
+			"Zephyr,Alice,alice@example.com,,,70,70,,,70\n",
+	}
+
+	for format, want := range tests {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeTrendFixture(t)
+			exitCode, stdout, stderr := runPublicCommand(t, GradebookLMS, []string{"export", "-dir", dir, "-format", format})
+			if exitCode != exitSuccess {
+				t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+			}
+			if stdout != want {
+				t.Fatalf("stdout = %q; want %q", stdout, want)
+			}
+		})
+	}
+}
+
+func TestPublicGradebookLMSExportMissingWork(t *testing.T) {
+	t.Parallel()
+
+	// Alice's quiz-1 is past due under a zero_after_days policy, so its cell
+	// is 0, which agrees with her totals. Her quiz-2 is not yet due, so its
+	// cell stays blank.
+	tests := map[string]string{
+		"moodle":     "Alice,Zephyr,,alice@example.com,0,-,-,0,-,0\n",
+		"blackboard": "Zephyr,Alice,alice@example.com,,0,,,0,,0\n",
+	}
+
+	for format, want := range tests {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeMissingFixture(t)
+			exitCode, stdout, stderr := runPublicCommand(t, GradebookLMS, []string{"export", "-dir", dir, "-format", format})
+			if exitCode != exitSuccess {
+				t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+			}
+			if !strings.HasSuffix(stdout, want) {
+				t.Fatalf("stdout = %q; want it to end with %q", stdout, want)
+			}
+		})
+	}
+}
+
+func TestLMSExportersAreRegistered(t *testing.T) {
+	t.Parallel()
+
+	got := strings.Join(lmsFormats(), " ")
+	if want := "blackboard canvas moodle"; got != want {
+		t.Fatalf("lmsFormats() = %q; want %q", got, want)
+	}
+}
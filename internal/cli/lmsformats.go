@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	lmsFormatCanvas     = "canvas"
+	lmsFormatMoodle     = "moodle"
+	lmsFormatBlackboard = "blackboard"
+)
+
+// lmsExporter writes grades in the layout that one LMS imports. To support
+// another LMS, write an lmsExporter and add it to lmsExporters.
+type lmsExporter interface {
+	export(w io.Writer, grades *lmsGrades) error
+}
+
+// lmsExporters holds the exporter for each value of gradebook-lms -format.
+var lmsExporters = map[string]lmsExporter{
+	lmsFormatCanvas:     canvasExporter{},
+	lmsFormatMoodle:     moodleExporter{},
+	lmsFormatBlackboard: blackboardExporter{},
+}
+
+func lmsFormats() []string {
+	return slices.Sorted(maps.Keys(lmsExporters))
+}
+
+// lmsGrades holds what an exporter needs: the same per-assignment scores and
+// per-category averages that gradebook-calc uses. Extra credit assignments are
+// not listed, since their grades are points rather than percentages, but the
+// overall average includes extra credit.
+type lmsGrades struct {
+	categories  []string
+	labels      []string
+	assignments []*gradebookFile
+	students    []*lmsStudent
+}
+
+// lmsStudent holds one student's grades. Grades and scores line up with
+// lmsGrades.assignments: a grade is recorded before any of the class's
+// policies apply, and a score is what counts toward the student's average,
+// which is zero for missing work. Either is nil if the record is unscored and
+// does not count. Averages line up with
+// lmsGrades.categories.
+type lmsStudent struct {
+	email     string
+	firstName string
+	lastName  string
+	studentID string
+	section   string
+	grades    []*float64
+	scores    []*float64
+	averages  []gradebook.AverageResult
+	overall   gradebook.AverageResult
+}
+
+// lmsGrades collects grades for the students in cmd's section. The grades in
+// gbFiles must already be added to class.
+func (cmd *cmdEnv) lmsGrades(class *gradebook.Class, gbFiles []*gradebookFile) *lmsGrades {
+	if cmd.noOp() {
+		return nil
+	}
+
+	grades := &lmsGrades{categories: class.AssignmentCategoriesSortedByLabel()}
+	for _, cat := range grades.categories {
+		grades.labels = append(grades.labels, class.LabelsByAssignmentCategory[cat])
+	}
+	for _, gbf := range sortedByDate(gbFiles) {
+		if !cmd.extras.isExtraCreditType(gbf.AssignmentType) {
+			grades.assignments = append(grades.assignments, gbf)
+		}
+	}
+
+	byEmail := make([]map[string]*assignmentRecord, 0, len(grades.assignments))
+	for _, gbf := range grades.assignments {
+		byEmail = append(byEmail, recordsByEmail(gbf))
+	}
+
+	bonusByEmail := cmd.extras.extraCreditPoints(gbFiles)
+	for _, email := range cmd.emailsInSection(class) {
+		s := class.StudentsByEmail[email]
+		se := cmd.extras.student(email)
+		ls := &lmsStudent{
+			email:     email,
+			firstName: s.FirstName,
+			lastName:  s.LastName,
+			studentID: se.StudentID,
+			section:   se.Section,
+			overall:   withBonus(s.TotalAverage(class.WeightsByAssignmentCategory), bonusByEmail[email]),
+		}
+		for i, gbf := range grades.assignments {
+			var grade, score *float64
+			if ar, ok := byEmail[i][email]; ok {
+				cat := gbf.category(class)
+				if g, ok := cmd.extras.grade(gbf, ar); ok {
+					grade = &g
+				}
+				if sc, ok := cmd.extras.score(cat, gbf, ar); ok || cmd.extras.isMissing(cat, gbf, cmd.today) {
+					score = &sc
+				}
+			}
+			ls.grades = append(ls.grades, grade)
+			ls.scores = append(ls.scores, score)
+		}
+		for _, cat := range grades.categories {
+			ls.averages = append(ls.averages, s.Average(cat))
+		}
+		grades.students = append(grades.students, ls)
+	}
+
+	return grades
+}
+
+// lmsScore formats a grade or score, or returns blank if it is nil.
+func lmsScore(score *float64, blank string) string {
+	if score == nil {
+		return blank
+	}
+
+	return formatScore(*score)
+}
+
+// lmsAverage formats an average, or returns blank if it has no result.
+func lmsAverage(ar gradebook.AverageResult, blank string) string {
+	if !ar.Valid {
+		return blank
+	}
+
+	return formatScore(ar.Value)
+}
+
+// canvasExporter writes a Canvas gradebook CSV with a column for each
+// assignment and a Points Possible row. Grades are written as recorded, so
+// that gradebook-lms import can read them back.
+type canvasExporter struct{}
+
+func (canvasExporter) export(w io.Writer, grades *lmsGrades) error {
+	header := []string{canvasStudent, canvasID, canvasSISUserID, canvasSISLoginID, canvasSection}
+	points := []string{"    " + canvasPointsPossible, "", "", "", ""}
+	for _, gbf := range grades.assignments {
+		header = append(header, gbf.AssignmentName)
+		points = append(points, "100")
+	}
+
+	rows := [][]string{header, points}
+	for _, ls := range grades.students {
+		row := []string{ls.lastName + ", " + ls.firstName, "", ls.studentID, ls.email, ls.section}
+		for _, grade := range ls.grades {
+			row = append(row, lmsScore(grade, ""))
+		}
+		rows = append(rows, row)
+	}
+
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// moodleExporter writes a CSV for Moodle's grade import, with students
+// identified by email address. Each assignment, each category, and the course
+// total has a column of scores out of 100, and "-" marks a missing score, as
+// in Moodle's own exports.
+type moodleExporter struct{}
+
+func (moodleExporter) export(w io.Writer, grades *lmsGrades) error {
+	const blank = "-"
+
+	header := []string{"First name", "Last name", "ID number", "Email address"}
+	for _, gbf := range grades.assignments {
+		header = append(header, fmt.Sprintf("Assignment: %s (Real)", gbf.AssignmentName))
+	}
+	for _, label := range grades.labels {
+		header = append(header, fmt.Sprintf("Category total: %s (Real)", label))
+	}
+	header = append(header, "Course total (Real)")
+
+	rows := [][]string{header}
+	for _, ls := range grades.students {
+		row := []string{ls.firstName, ls.lastName, ls.studentID, ls.email}
+		for _, score := range ls.scores {
+			row = append(row, lmsScore(score, blank))
+		}
+		for _, avg := range ls.averages {
+			row = append(row, lmsAverage(avg, blank))
+		}
+		row = append(row, lmsAverage(ls.overall, blank))
+		rows = append(rows, row)
+	}
+
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// blackboardExporter writes a CSV for Blackboard's Grade Center upload, with
+// students identified by username, which is their email. Columns without
+// a Blackboard column ID become new Grade Center columns on upload.
+type blackboardExporter struct{}
+
+func (blackboardExporter) export(w io.Writer, grades *lmsGrades) error {
+	header := []string{"Last Name", "First Name", "Username", "Student ID"}
+	for _, gbf := range grades.assignments {
+		header = append(header, fmt.Sprintf("%s [Total Pts: 100 Score]", gbf.AssignmentName))
+	}
+	for _, label := range grades.labels {
+		header = append(header, fmt.Sprintf("%s [Total Pts: 100 Percentage]", label))
+	}
+	header = append(header, "Overall [Total Pts: 100 Percentage]")
+
+	rows := [][]string{header}
+	for _, ls := range grades.students {
+		row := []string{ls.lastName, ls.firstName, ls.email, ls.studentID}
+		for _, score := range ls.scores {
+			row = append(row, lmsScore(score, ""))
+		}
+		for _, avg := range ls.averages {
+			row = append(row, lmsAverage(avg, ""))
+		}
+		row = append(row, lmsAverage(ls.overall, ""))
+		rows = append(rows, row)
+	}
+
+	return csv.NewWriter(w).WriteAll(rows)
+}
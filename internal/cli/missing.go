@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	missingIgnore        = "ignore"
+	missingZero          = "zero"
+	missingZeroAfterDays = "zero_after_days"
+)
+
+// missingPolicy describes how unscored records in a category count toward
+// a student's average. Policy is one of the following.
+//
+//   - "ignore": unscored records do not count (the default)
+//   - "zero": unscored records count as zero
+//   - "zero_after_days": unscored records count as zero once the assignment
+//     is more than Days days past due
+type missingPolicy struct {
+	Policy string `json:"policy"`
+	Days   int    `json:"days"`
+}
+
+func (ce *classExtras) checkMissingPolicies(class *gradebook.Class) error {
+	if ce == nil {
+		return nil
+	}
+
+	errs := make([]error, 0, len(ce.MissingPoliciesByAssignmentCategory))
+	for _, cat := range slices.Sorted(maps.Keys(ce.MissingPoliciesByAssignmentCategory)) {
+		mp := ce.MissingPoliciesByAssignmentCategory[cat]
+
+		switch {
+		case !slices.Contains(class.AssignmentCategories, cat):
+			errs = append(errs, fmt.Errorf("missing policy for unknown assignment category %q", cat))
+		case mp == nil:
+			errs = append(errs, fmt.Errorf("missing policy for %q is nil", cat))
+		case mp.Policy != missingIgnore && mp.Policy != missingZero && mp.Policy != missingZeroAfterDays:
+			errs = append(errs, fmt.Errorf("missing policy for %q has unknown policy %q", cat, mp.Policy))
+		case mp.Days < 0:
+			errs = append(errs, fmt.Errorf("missing policy for %q must not have negative days", cat))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// countsMissing reports whether unscored records in cat can ever count as
+// zero under the class's missing policy.
+func (ce *classExtras) countsMissing(cat string) bool {
+	if ce == nil || ce.MissingPoliciesByAssignmentCategory[cat] == nil {
+		return false
+	}
+
+	return ce.MissingPoliciesByAssignmentCategory[cat].Policy != missingIgnore
+}
+
+// isMissing reports whether an unscored record on gbf counts as zero on the
+// YYYYMMDD date today. An unscored record that does not count as zero yet is
+// not yet due.
+func (ce *classExtras) isMissing(cat string, gbf *gradebookFile, today string) bool {
+	if !ce.countsMissing(cat) {
+		return false
+	}
+
+	mp := ce.MissingPoliciesByAssignmentCategory[cat]
+	if mp.Policy == missingZero {
+		return true
+	}
+
+	return daysLate(gbf.dueDate(), today) > mp.Days
+}
+
+// countMissing counts each student's missing records by category on the
+// YYYYMMDD date today.
+func countMissing(
+	class *gradebook.Class,
+	extras *classExtras,
+	gbFiles []*gradebookFile,
+	today string,
+) map[string]map[string]int {
+	missingByEmail := make(map[string]map[string]int, len(class.StudentsByEmail))
+	for email := range class.StudentsByEmail {
+		missingByEmail[email] = make(map[string]int, len(class.AssignmentCategories))
+	}
+
+	for _, gbf := range gbFiles {
+		cat := gbf.category(class)
+		if !extras.isMissing(cat, gbf, today) {
+			continue
+		}
+
+		for _, ar := range gbf.AssignmentRecords {
+			if _, ok := extras.grade(gbf, ar); !ok {
+				missingByEmail[ar.Email][cat]++
+			}
+		}
+	}
+
+	return missingByEmail
+}
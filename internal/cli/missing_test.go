@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+const missingClassJSON = `{
+    "missing_policies_by_assignment_category": {
+        "minor": {
+            "policy": "zero_after_days",
+            "days": 3
+        },
+        "major": {
+            "policy": "zero"
+        }
+    }
+}`
+
+// The second quiz is far enough in the future that it is never past due
+// when the tests run.
+const missingFutureGradebookJSON = `{
+    "assignment_category": "minor",
+    "assignment_date": "29991231",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 80
+        },
+        {
+            "email": "alice@example.com",
+            "grade": null
+        }
+    ],
+    "assignment_name": "quiz-2",
+    "assignment_type": "quiz"
+}`
+
+func writeMissingFixture(t *testing.T) string {
+	t.Helper()
+
+	return writeFixtureDir(t, missingClassJSON, map[string]string{
+		"quiz-quiz-1-20240319.gradebook": gradebookFixtureJSON,
+		"quiz-quiz-2-29991231.gradebook": missingFutureGradebookJSON,
+	})
+}
+
+func TestPublicGradebookCalcMissingPolicy(t *testing.T) {
+	t.Parallel()
+
+	dir := writeMissingFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	// Alice's first quiz is long past due, so it counts as zero. Her second
+	// quiz is not yet due, so it does not count.
+	want := "" +
+		"Bob Young\n" +
+		"\tOverall average: 85\n" +
+		"\tMajor: No results\n" +
+		"\tMinor: 85\n" +
+		"\tParticipation: No results\n" +
+		"Alice Zephyr\n" +
+		"\tOverall average: 0\n" +
+		"\tMajor: No results\n" +
+		"\tMinor: 0 (1 missing)\n" +
+		"\tParticipation: No results\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookUnscoredMissingPolicy(t *testing.T) {
+	t.Parallel()
+
+	dir := writeMissingFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookUnscored, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	if !strings.Contains(stdout, "\tMinor: 2 unscored assignments (1 missing, 1 not yet due)\n") {
+		t.Fatalf("stdout = %q; want missing and not yet due counts for Alice", stdout)
+	}
+
+	got, err := parseUnscoredOutput(stdout)
+	if err != nil {
+		t.Fatalf("parseUnscoredOutput() returned error: %v", err)
+	}
+
+	want := map[string]map[string]int{
+		"Bob Young": {
+			"Major":         0,
+			"Minor":         0,
+			"Participation": 0,
+		},
+		"Alice Zephyr": {
+			"Major":         0,
+			"Minor":         2,
+			"Participation": 0,
+		},
+	}
+
+	assertUnscoredCounts(t, got, want)
+}
+
+func TestIsMissing(t *testing.T) {
+	t.Parallel()
+
+	extras := &classExtras{
+		MissingPoliciesByAssignmentCategory: map[string]*missingPolicy{
+			"minor": {Policy: missingZeroAfterDays, Days: 3},
+			"major": {Policy: missingZero},
+			"cp":    {Policy: missingIgnore},
+		},
+	}
+	gbf := &gradebookFile{AssignmentDate: "20240301", DueDate: "20240305"}
+
+	testCases := map[string]struct {
+		cat   string
+		today string
+		want  bool
+	}{
+		"zero before due":           {cat: "major", today: "20240301", want: true},
+		"ignore long after due":     {cat: "cp", today: "20250101", want: false},
+		"no policy":                 {cat: "other", today: "20250101", want: false},
+		"after days counts due":     {cat: "minor", today: "20240308", want: false},
+		"after days once past days": {cat: "minor", today: "20240309", want: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := extras.isMissing(tc.cat, gbf, tc.today); got != tc.want {
+				t.Fatalf("isMissing(%q, %q) = %t; want %t", tc.cat, tc.today, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalClassRejectsUnknownMissingPolicy(t *testing.T) {
+	t.Parallel()
+
+	classData := classFixtureWith(t, `{
+    "missing_policies_by_assignment_category": {
+        "minor": {"policy": "sometimes"}
+    }
+}`)
+	classFile := writeClassFixture(t, classData)
+	cmd, stderr := newValidationCmd(classFile)
+
+	if class := cmd.unmarshalClass(); class != nil {
+		t.Fatal("unmarshalClass() returned non-nil class for unknown missing policy")
+	}
+	if !strings.Contains(stderr.String(), `unknown policy "sometimes"`) {
+		t.Fatalf("stderr = %q; want missing policy error", stderr.String())
+	}
+}
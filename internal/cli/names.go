@@ -8,7 +8,7 @@ import (
 
 // GradebookNames prints the names of students in a class. The default
 // output is "FirstName LastName", but the user can opt for "LastName,
-// FirstName" instead.
+// FirstName" or for the student's preferred name instead.
 func GradebookNames(args []string) int {
 	cmd := cmdFrom("gradebook-names", namesUsage)
 
@@ -16,6 +16,7 @@ func GradebookNames(args []string) int {
 		parse:     (*cmdEnv).parseNames,
 		loadClass: true,
 		action: func(cmd *cmdEnv, class *gradebook.Class, _ noArgs) {
+			cmd.findSection()
 			cmd.printNames(class)
 		},
 	})
@@ -26,13 +27,24 @@ func (cmd *cmdEnv) printNames(class *gradebook.Class) {
 		return
 	}
 
-	students := class.StudentsSortedByName()
-	for _, s := range students {
-		switch cmd.lastFirst {
-		case true:
-			fmt.Fprintf(cmd.stdout, "%s, %s\n", s.LastName, s.FirstName)
-		default:
-			fmt.Fprintf(cmd.stdout, "%s %s\n", s.FirstName, s.LastName)
+	for _, email := range cmd.emailsInSection(class) {
+		fmt.Fprintln(cmd.stdout, cmd.studentName(class.StudentsByEmail[email], email))
+	}
+}
+
+// studentName formats a student's name according to cmd.nameStyle. The
+// preferred style falls back to the first name if no preferred name is set.
+func (cmd *cmdEnv) studentName(s *gradebook.Student, email string) string {
+	switch cmd.nameStyle {
+	case nameStyleLastFirst:
+		return fmt.Sprintf("%s, %s", s.LastName, s.FirstName)
+	case nameStylePreferred:
+		if preferred := cmd.extras.student(email).PreferredName; preferred != "" {
+			return fmt.Sprintf("%s %s", preferred, s.LastName)
 		}
+
+		return fmt.Sprintf("%s %s", s.FirstName, s.LastName)
+	default:
+		return fmt.Sprintf("%s %s", s.FirstName, s.LastName)
 	}
 }
@@ -31,6 +31,7 @@ type newCfg struct {
 	gbName string
 	gbType string
 	gbDate string
+	gbDue  string
 }
 
 func (cmd *cmdEnv) parseNew(args []string) newCfg {
@@ -40,6 +41,7 @@ func (cmd *cmdEnv) parseNew(args []string) newCfg {
 	og.String(&cfg.gbName, "name", "")
 	og.String(&cfg.gbType, "type", "")
 	og.String(&cfg.gbDate, "date", "")
+	og.String(&cfg.gbDue, "due", "")
 
 	if err := og.Parse(args); err != nil {
 		cmd.exitValue = exitFailure
@@ -65,6 +67,9 @@ func (cmd *cmdEnv) checkNew(class *gradebook.Class, cfg newCfg) {
 	isValidName(cmd, cfg.gbName)
 	isValidType(cmd, cfg.gbType, class)
 	isValidDate(cmd, cfg.gbDate)
+	if cfg.gbDue != "" {
+		isValidDue(cmd, cfg.gbDue)
+	}
 }
 
 func (cmd *cmdEnv) newGradebook(class *gradebook.Class, cfg newCfg) {
@@ -73,16 +78,22 @@ func (cmd *cmdEnv) newGradebook(class *gradebook.Class, cfg newCfg) {
 	}
 
 	emails := class.EmailsSortedByStudentName()
-	recs := make(gradebook.AssignmentRecords, 0, len(emails))
+	recs := make([]*assignmentRecord, 0, len(emails))
 	for _, email := range emails {
-		recs = append(recs, &gradebook.AssignmentRecord{Email: email, Grade: nil})
+		recs = append(recs, &assignmentRecord{Email: email, Grade: nil})
+	}
+
+	category := class.CategoriesByAssignmentType[cfg.gbType]
+	if cmd.extras.isExtraCreditType(cfg.gbType) {
+		category = extraCreditCategory
 	}
 
-	newGb := &gradebook.Gradebook{
-		AssignmentCategory: class.CategoriesByAssignmentType[cfg.gbType],
+	newGb := &gradebookFile{
+		AssignmentCategory: category,
 		AssignmentDate:     cfg.gbDate,
 		AssignmentName:     cfg.gbName,
 		AssignmentType:     cfg.gbType,
+		DueDate:            cfg.gbDue,
 		AssignmentRecords:  recs,
 	}
 
@@ -97,7 +108,10 @@ func (cmd *cmdEnv) newGradebook(class *gradebook.Class, cfg newCfg) {
 	fileName := fmt.Sprintf("%s-%s-%s.gradebook", cfg.gbType, cfg.gbName, cfg.gbDate)
 	fileName = filepath.Join(cmd.directory, fileName)
 
-	err = writeFile(fileName, gbData)
+	gbData, err = cmd.sealLikeClass(gbData)
+	if err == nil {
+		err = writeFile(fileName, gbData)
+	}
 	if err != nil {
 		cmd.exitValue = exitFailure
 		if errors.Is(err, os.ErrExist) {
@@ -121,7 +135,7 @@ func isValidType(cmd *cmdEnv, gbType string, class *gradebook.Class) {
 	}
 
 	gbTypes := slices.Collect(maps.Keys(class.CategoriesByAssignmentType))
-	if !slices.Contains(gbTypes, gbType) {
+	if !slices.Contains(gbTypes, gbType) && !cmd.extras.isExtraCreditType(gbType) {
 		cmd.exitValue = exitFailure
 		fmt.Fprintf(cmd.stderr, "%s: invalid argument for -type: %q\n", cmd.name, gbType)
 	}
@@ -138,8 +152,25 @@ func isValidDate(cmd *cmdEnv, gbDate string) {
 	}
 }
 
-func writeFile(fileName string, data []byte) (err error) {
-	fh, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+func isValidDue(cmd *cmdEnv, gbDue string) {
+	if cmd.minNoOp() {
+		return
+	}
+
+	if _, err := time.Parse("20060102", gbDue); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: invalid argument for -due: %q\n", cmd.name, gbDue)
+	}
+}
+
+func writeFile(fileName string, data []byte) error {
+	return writeFileMode(fileName, data, 0o644)
+}
+
+// writeFileMode writes a new file with the given permissions. Like
+// writeFile, it never replaces an existing file.
+func writeFileMode(fileName string, data []byte, perm os.FileMode) (err error) {
+	fh, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
 	if err != nil {
 		return fmt.Errorf("open file %q: %w", fileName, err)
 	}
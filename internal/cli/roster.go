@@ -0,0 +1,553 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	nameOrderFirstLast = "first-last"
+	nameOrderLastFirst = "last-first"
+)
+
+// GradebookRoster merges a roster CSV, such as an export from an SIS or LMS,
+// into the students in class.json.
+func GradebookRoster(args []string) int {
+	cmd := cmdFrom("gradebook-roster", rosterUsage)
+
+	return runCommand(cmd, args, commandRun[rosterCfg]{
+		parse:     (*cmdEnv).parseRoster,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg rosterCfg) {
+			roster := cmd.readRoster(cfg)
+			changes := cmd.diffRoster(class, roster)
+			cmd.checkRosterIDs(cfg, changes)
+			cmd.countRemovedRecords(changes)
+			cmd.printRosterChanges(changes)
+			if !cmd.confirmRosterChanges(changes, cfg.yes) {
+				return
+			}
+			cmd.rewriteRoster(changes)
+		},
+	})
+}
+
+type rosterCfg struct {
+	subcommand  string
+	file        string
+	emailColumn string
+	nameColumn  string
+	firstColumn string
+	lastColumn  string
+	idColumn    string
+	nameOrder   string
+	yes         bool
+}
+
+type rosterName struct {
+	firstName string
+	lastName  string
+}
+
+func (rn rosterName) String() string {
+	return rn.firstName + " " + rn.lastName
+}
+
+// rosterStudent is a student's name and, if the roster has an ID column,
+// student ID.
+type rosterStudent struct {
+	name rosterName
+	id   string
+}
+
+// rosterChanges describes how a roster differs from the students in a class.
+// Records counts the gradebook records of each removed student.
+type rosterChanges struct {
+	previous map[string]rosterStudent
+	roster   map[string]rosterStudent
+	records  map[string]int
+	added    []string
+	removed  []string
+	renamed  []string
+	newIDs   []string
+}
+
+func (rc *rosterChanges) empty() bool {
+	return len(rc.added) == 0 && len(rc.removed) == 0 && len(rc.renamed) == 0 && len(rc.newIDs) == 0
+}
+
+func (cmd *cmdEnv) parseRoster(args []string) rosterCfg {
+	var cfg rosterCfg
+	cfg.subcommand, args = splitSubcommand(args)
+
+	og := cmd.commonOptsGroup(parseOpts{})
+	og.String(&cfg.file, "file", "")
+	og.String(&cfg.emailColumn, "email-column", "Email")
+	og.String(&cfg.nameColumn, "name-column", "Student Name")
+	og.String(&cfg.firstColumn, "first-column", "")
+	og.String(&cfg.lastColumn, "last-column", "")
+	og.String(&cfg.idColumn, "id-column", "")
+	og.String(&cfg.nameOrder, "name-order", nameOrderFirstLast)
+	og.Bool(&cfg.yes, "yes")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkSubcommand(cfg.subcommand, "import")
+	cmd.checkRosterCfg(cfg)
+
+	return cfg
+}
+
+func (cmd *cmdEnv) checkRosterCfg(cfg rosterCfg) {
+	if cmd.noOp() {
+		return
+	}
+
+	switch {
+	case cfg.file == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -file is required\n", cmd.name)
+	case (cfg.firstColumn == "") != (cfg.lastColumn == ""):
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -first-column and -last-column must be used together\n", cmd.name)
+	case cfg.nameOrder != nameOrderFirstLast && cfg.nameOrder != nameOrderLastFirst:
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: invalid argument for -name-order: %q\n", cmd.name, cfg.nameOrder)
+	}
+}
+
+func (cmd *cmdEnv) readRoster(cfg rosterCfg) map[string]rosterStudent {
+	if cmd.noOp() {
+		return nil
+	}
+
+	roster, err := readRosterCSV(cfg)
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return nil
+	}
+
+	return roster
+}
+
+func readRosterCSV(cfg rosterCfg) (roster map[string]rosterStudent, err error) {
+	fh, err := os.Open(filepath.Clean(cfg.file))
+	if err != nil {
+		return nil, fmt.Errorf("open roster %q: %w", cfg.file, err)
+	}
+	defer func() {
+		if closeErr := fh.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close roster %q: %w", cfg.file, closeErr))
+		}
+	}()
+
+	rdr := csv.NewReader(fh)
+	rdr.FieldsPerRecord = -1
+	rdr.TrimLeadingSpace = true
+
+	header, err := rdr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header of roster %q: %w", cfg.file, err)
+	}
+
+	cols, err := cfg.columns(header)
+	if err != nil {
+		return nil, fmt.Errorf("roster %q: %w", cfg.file, err)
+	}
+
+	roster = make(map[string]rosterStudent)
+	emailsByID := make(map[string]string)
+	for {
+		row, err := rdr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read roster %q: %w", cfg.file, err)
+		}
+
+		line, _ := rdr.FieldPos(0)
+		email, student, err := cols.student(row, cfg.nameOrder)
+		if err != nil {
+			return nil, fmt.Errorf("roster %q, line %d: %w", cfg.file, line, err)
+		}
+		if email == "" && student == (rosterStudent{}) {
+			continue
+		}
+		if _, ok := roster[email]; ok {
+			return nil, fmt.Errorf("roster %q, line %d: duplicate email %q", cfg.file, line, email)
+		}
+		if other, ok := emailsByID[student.id]; ok {
+			return nil, fmt.Errorf("roster %q, line %d: duplicate student ID %q for %q and %q", cfg.file, line, student.id, other, email)
+		}
+		if student.id != "" {
+			emailsByID[student.id] = email
+		}
+
+		roster[email] = student
+	}
+
+	return roster, nil
+}
+
+type rosterColumns struct {
+	email int
+	name  int
+	first int
+	last  int
+	id    int
+}
+
+func (cfg rosterCfg) columns(header []string) (rosterColumns, error) {
+	cols := rosterColumns{email: -1, name: -1, first: -1, last: -1, id: -1}
+
+	var missing []string
+	find := func(label string) int {
+		idx := slices.IndexFunc(header, func(h string) bool {
+			h = strings.TrimPrefix(h, "\ufeff")
+
+			return strings.EqualFold(strings.TrimSpace(h), label)
+		})
+		if idx < 0 {
+			missing = append(missing, label)
+		}
+
+		return idx
+	}
+
+	cols.email = find(cfg.emailColumn)
+	if cfg.firstColumn != "" {
+		cols.first = find(cfg.firstColumn)
+		cols.last = find(cfg.lastColumn)
+	} else {
+		cols.name = find(cfg.nameColumn)
+	}
+	if cfg.idColumn != "" {
+		cols.id = find(cfg.idColumn)
+	}
+
+	if len(missing) > 0 {
+		return cols, fmt.Errorf("missing column(s): %q", missing)
+	}
+
+	return cols, nil
+}
+
+func (cols rosterColumns) student(row []string, nameOrder string) (string, rosterStudent, error) {
+	field := func(idx int) string {
+		if idx < 0 || idx >= len(row) {
+			return ""
+		}
+
+		return strings.TrimSpace(row[idx])
+	}
+
+	email := field(cols.email)
+	name := rosterName{firstName: field(cols.first), lastName: field(cols.last)}
+	if cols.name >= 0 && field(cols.name) != "" {
+		var err error
+		name, err = splitName(field(cols.name), nameOrder)
+		if err != nil {
+			return "", rosterStudent{}, err
+		}
+	}
+	id := field(cols.id)
+
+	if email == "" && name == (rosterName{}) && id == "" {
+		return "", rosterStudent{}, nil
+	}
+
+	switch {
+	case !strings.Contains(email, "@"):
+		return "", rosterStudent{}, fmt.Errorf("invalid email %q", email)
+	case name.firstName == "" || name.lastName == "":
+		return "", rosterStudent{}, fmt.Errorf("incomplete name for %q", email)
+	}
+
+	return email, rosterStudent{name: name, id: id}, nil
+}
+
+// splitName divides a full name into first and last names. A name with
+// a comma is always read as "Last, First". Otherwise, nameOrder decides: for
+// "first-last" the last word is the last name, and for "last-first" the first
+// word is the last name.
+func splitName(fullName, nameOrder string) (rosterName, error) {
+	if last, first, ok := strings.Cut(fullName, ","); ok {
+		name := rosterName{firstName: strings.TrimSpace(first), lastName: strings.TrimSpace(last)}
+		if name.firstName == "" || name.lastName == "" {
+			return rosterName{}, fmt.Errorf("cannot split name %q", fullName)
+		}
+
+		return name, nil
+	}
+
+	words := strings.Fields(fullName)
+	if len(words) < 2 {
+		return rosterName{}, fmt.Errorf("cannot split name %q", fullName)
+	}
+
+	if nameOrder == nameOrderLastFirst {
+		return rosterName{firstName: strings.Join(words[1:], " "), lastName: words[0]}, nil
+	}
+
+	return rosterName{firstName: strings.Join(words[:len(words)-1], " "), lastName: words[len(words)-1]}, nil
+}
+
+func (cmd *cmdEnv) diffRoster(class *gradebook.Class, roster map[string]rosterStudent) *rosterChanges {
+	if cmd.noOp() {
+		return nil
+	}
+
+	changes := &rosterChanges{
+		previous: make(map[string]rosterStudent, len(class.StudentsByEmail)),
+		roster:   roster,
+	}
+	for email, s := range class.StudentsByEmail {
+		prev := rosterStudent{
+			name: rosterName{firstName: s.FirstName, lastName: s.LastName},
+			id:   cmd.extras.student(email).StudentID,
+		}
+		changes.previous[email] = prev
+
+		student, ok := roster[email]
+		if !ok {
+			changes.removed = append(changes.removed, email)
+
+			continue
+		}
+		if student.name != prev.name {
+			changes.renamed = append(changes.renamed, email)
+		}
+		if student.id != "" && student.id != prev.id {
+			changes.newIDs = append(changes.newIDs, email)
+		}
+	}
+	for email := range roster {
+		if _, ok := class.StudentsByEmail[email]; !ok {
+			changes.added = append(changes.added, email)
+		}
+	}
+
+	slices.Sort(changes.added)
+	slices.Sort(changes.removed)
+	slices.Sort(changes.renamed)
+	slices.Sort(changes.newIDs)
+
+	return changes
+}
+
+// checkRosterIDs checks that no two students would share a student ID after
+// the import. A student whose row has no ID keeps the ID in class.json, so
+// the roster's IDs are checked against those as well as each other.
+func (cmd *cmdEnv) checkRosterIDs(cfg rosterCfg, changes *rosterChanges) {
+	if cmd.noOp() {
+		return
+	}
+
+	emailsByID := make(map[string]string, len(changes.roster))
+	for _, email := range slices.Sorted(maps.Keys(changes.roster)) {
+		id := changes.roster[email].id
+		if id == "" {
+			id = changes.previous[email].id
+		}
+		if id == "" {
+			continue
+		}
+
+		if other, ok := emailsByID[id]; ok {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: roster %q: duplicate student ID %q for %q and %q\n", cmd.name, cfg.file, id, other, email)
+
+			return
+		}
+		emailsByID[id] = email
+	}
+}
+
+// countRemovedRecords counts the gradebook records of each removed student.
+// gradebook-calc refuses records for students who are not in the class, so
+// the teacher needs to know about them before agreeing to the removal.
+func (cmd *cmdEnv) countRemovedRecords(changes *rosterChanges) {
+	if cmd.noOp() || len(changes.removed) == 0 {
+		return
+	}
+
+	gbFiles, err := filepath.Glob(filepath.Join(cmd.directory, "*.gradebook"))
+	if err != nil {
+		fmt.Fprintf(cmd.stderr, "%s: warning: cannot check gradebook files: %s\n", cmd.name, err)
+
+		return
+	}
+
+	changes.records = make(map[string]int, len(changes.removed))
+	for _, gbFile := range gbFiles {
+		gbf, err := readGradebookFile(cmd.classStore(), gbFile)
+		if err != nil {
+			fmt.Fprintf(cmd.stderr, "%s: warning: %s\n", cmd.name, err)
+
+			continue
+		}
+		for _, ar := range gbf.AssignmentRecords {
+			if ar != nil && slices.Contains(changes.removed, ar.Email) {
+				changes.records[ar.Email]++
+			}
+		}
+	}
+}
+
+func (cmd *cmdEnv) printRosterChanges(changes *rosterChanges) {
+	if cmd.noOp() {
+		return
+	}
+
+	if changes.empty() {
+		fmt.Fprintln(cmd.stdout, "No roster changes")
+
+		return
+	}
+
+	printSection := func(heading string, emails []string, line func(string) string) {
+		if len(emails) == 0 {
+			return
+		}
+
+		fmt.Fprintf(cmd.stdout, "%s:\n", heading)
+		for _, email := range emails {
+			fmt.Fprintf(cmd.stdout, "\t%s\n", line(email))
+		}
+	}
+
+	printSection("Added", changes.added, func(email string) string {
+		return fmt.Sprintf("%s <%s>", changes.roster[email].name, email)
+	})
+	printSection("Removed", changes.removed, func(email string) string {
+		line := fmt.Sprintf("%s <%s>", changes.previous[email].name, email)
+		if n := changes.records[email]; n > 0 {
+			line += fmt.Sprintf(" (still has records in %d gradebook file(s))", n)
+		}
+
+		return line
+	})
+	printSection("Renamed", changes.renamed, func(email string) string {
+		return fmt.Sprintf("%s -> %s <%s>", changes.previous[email].name, changes.roster[email].name, email)
+	})
+	printSection("New student IDs", changes.newIDs, func(email string) string {
+		prev := changes.previous[email].id
+		if prev == "" {
+			prev = "none"
+		}
+
+		return fmt.Sprintf("%s <%s>: %s -> %s", changes.roster[email].name, email, prev, changes.roster[email].id)
+	})
+}
+
+func (cmd *cmdEnv) confirmRosterChanges(changes *rosterChanges, yes bool) bool {
+	if cmd.noOp() || changes.empty() {
+		return false
+	}
+
+	question := fmt.Sprintf("Rewrite %s?", cmd.classFile)
+	if n := len(changes.records); n > 0 {
+		question = fmt.Sprintf(
+			"%d removed student(s) still have gradebook records, which gradebook-calc will reject. %s",
+			n,
+			question,
+		)
+	}
+
+	return cmd.confirm(question, yes)
+}
+
+func (cmd *cmdEnv) rewriteRoster(changes *rosterChanges) {
+	if cmd.noOp() {
+		return
+	}
+
+	if err := rewriteClassStudents(cmd.keys, cmd.classFile, changes); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem rewriting class: %s\n", cmd.name, err)
+	}
+}
+
+// rewriteClassStudents applies roster changes to students_by_email in
+// classFile. Fields that the roster does not manage, both in the class and in
+// each student, are carried over unchanged.
+func rewriteClassStudents(keys *keyring, classFile string, changes *rosterChanges) error {
+	data, sealed, err := keys.readFile(classFile)
+	if err != nil {
+		return fmt.Errorf("read class file %q: %w", classFile, err)
+	}
+
+	raw := newJSONObject()
+	if err = json.Unmarshal(data, raw); err != nil {
+		return fmt.Errorf("unmarshal class file %q: %w", classFile, err)
+	}
+
+	students := newJSONObject()
+	if err = json.Unmarshal(raw.get("students_by_email"), students); err != nil {
+		return fmt.Errorf("unmarshal students in %q: %w", classFile, err)
+	}
+
+	for _, email := range changes.removed {
+		students.delete(email)
+	}
+	for _, email := range slices.Concat(changes.added, changes.renamed, changes.newIDs) {
+		student := newJSONObject()
+		if prev := students.get(email); prev != nil {
+			if err = json.Unmarshal(prev, student); err != nil {
+				return fmt.Errorf("unmarshal student %q in %q: %w", email, classFile, err)
+			}
+		}
+		if err = setRosterStudent(student, changes.roster[email]); err != nil {
+			return err
+		}
+		if err = students.setValue(email, student); err != nil {
+			return err
+		}
+	}
+
+	if err = raw.setValue("students_by_email", students); err != nil {
+		return fmt.Errorf("marshal students: %w", err)
+	}
+
+	out, err := marshalJSONFile(raw)
+	if err != nil {
+		return err
+	}
+
+	return keys.replaceFile(classFile, out, sealed)
+}
+
+// setRosterStudent gives a student record the name and, if the roster has
+// one, the student ID from the roster.
+func setRosterStudent(student *jsonObject, rs rosterStudent) error {
+	if err := student.setValue("first_name", rs.name.firstName); err != nil {
+		return err
+	}
+	if err := student.setValue("last_name", rs.name.lastName); err != nil {
+		return err
+	}
+	if rs.id == "" {
+		return nil
+	}
+
+	return student.setValue("student_id", rs.id)
+}
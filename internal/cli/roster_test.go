@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/telemachus/gradebook"
+)
+
+const rosterFixtureCSV = `Student Name,Email,ID
+"Zephyr, Alicia",alice@example.com,1001
+Carol Ann Xu,carol@example.com,1003
+`
+
+func TestPublicGradebookRosterImport(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	csvFile := filepath.Join(dir, "roster.csv")
+	mustWriteFixtureFile(t, csvFile, rosterFixtureCSV)
+
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookRoster, []string{
+		"import",
+		"-dir", dir,
+		"-file", csvFile,
+		"-id-column", "ID",
+		"-yes",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "" +
+		"Added:\n" +
+		"\tCarol Ann Xu <carol@example.com>\n" +
+		"Removed:\n" +
+		"\tBob Young <bob@example.com> (still has records in 1 gradebook file(s))\n" +
+		"Renamed:\n" +
+		"\tAlice Zephyr -> Alicia Zephyr <alice@example.com>\n" +
+		"New student IDs:\n" +
+		"\tAlicia Zephyr <alice@example.com>: none -> 1001\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+	if stderr != "" {
+		t.Fatalf("stderr = %q; want nothing", stderr)
+	}
+
+	// The students keep their order, and the class keeps its key order.
+	data, err := os.ReadFile(filepath.Join(dir, suiteClassFile))
+	if err != nil {
+		t.Fatalf("failed to read rewritten class: %v", err)
+	}
+	wantStudents := `    "students_by_email": {
+        "alice@example.com": {
+            "first_name": "Alicia",
+            "last_name": "Zephyr",
+            "student_id": "1001"
+        },
+        "carol@example.com": {
+            "first_name": "Carol Ann",
+            "last_name": "Xu",
+            "student_id": "1003"
+        }
+    }
+}
+`
+	if !strings.HasPrefix(string(data), "{\n    \"name\"") || !strings.HasSuffix(string(data), wantStudents) {
+		t.Fatalf("rewritten class = %s; want it to start with name and end with:\n%s", data, wantStudents)
+	}
+
+	class, err := gradebook.UnmarshalClass(filepath.Join(dir, suiteClassFile))
+	if err != nil {
+		t.Fatalf("failed to unmarshal rewritten class: %v", err)
+	}
+	if err = class.Validate(); err != nil {
+		t.Fatalf("rewritten class does not validate: %v", err)
+	}
+	if class.Name != "Characterization Test Class" {
+		t.Fatalf("Name = %q; want it carried over", class.Name)
+	}
+
+	got := strings.Join(class.EmailsSortedByStudentName(), " ")
+	if want := "carol@example.com alice@example.com"; got != want {
+		t.Fatalf("emails = %q; want %q", got, want)
+	}
+	if s := class.StudentsByEmail["alice@example.com"]; s.FirstName != "Alicia" {
+		t.Fatalf("FirstName = %q; want %q", s.FirstName, "Alicia")
+	}
+}
+
+func TestGradebookRosterDeclined(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	csvFile := filepath.Join(dir, "roster.csv")
+	mustWriteFixtureFile(t, csvFile, rosterFixtureCSV)
+
+	var stdout, stderr bytes.Buffer
+	cmd := cmdFromWithWriters("gradebook-roster", rosterUsage, &stdout, &stderr)
+	cmd.stdin = strings.NewReader("n\n")
+
+	exitCode := runCommand(cmd, []string{"import", "-dir", dir, "-file", csvFile}, commandRun[rosterCfg]{
+		parse:     (*cmdEnv).parseRoster,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg rosterCfg) {
+			changes := cmd.diffRoster(class, cmd.readRoster(cfg))
+			cmd.countRemovedRecords(changes)
+			if cmd.confirmRosterChanges(changes, cfg.yes) {
+				t.Error("confirmRosterChanges() = true; want false")
+			}
+		},
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr.String())
+	}
+	if !strings.HasSuffix(stdout.String(), "No changes written\n") {
+		t.Fatalf("stdout = %q; want declined message", stdout.String())
+	}
+	if want := "1 removed student(s) still have gradebook records"; !strings.Contains(stdout.String(), want) {
+		t.Fatalf("stdout = %q; want the prompt to contain %q", stdout.String(), want)
+	}
+}
+
+func TestPublicGradebookRosterMissingSubcommand(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookRoster, []string{"-dir", dir, "-file", "roster.csv"})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.HasPrefix(stderr, "gradebook-roster: missing subcommand\n") {
+		t.Fatalf("stderr = %q; want missing subcommand error", stderr)
+	}
+}
+
+var splitNameCases = map[string]struct {
+	fullName  string
+	nameOrder string
+	want      rosterName
+	wantErr   bool
+}{
+	"first last": {
+		fullName:  "Alice Zephyr",
+		nameOrder: nameOrderFirstLast,
+		want:      rosterName{firstName: "Alice", lastName: "Zephyr"},
+	},
+	"first middle last": {
+		fullName:  "Mary Ann Smith",
+		nameOrder: nameOrderFirstLast,
+		want:      rosterName{firstName: "Mary Ann", lastName: "Smith"},
+	},
+	"last first": {
+		fullName:  "Zephyr Alice",
+		nameOrder: nameOrderLastFirst,
+		want:      rosterName{firstName: "Alice", lastName: "Zephyr"},
+	},
+	"comma overrides order": {
+		fullName:  "de la Cruz, Juan",
+		nameOrder: nameOrderFirstLast,
+		want:      rosterName{firstName: "Juan", lastName: "de la Cruz"},
+	},
+	"single word": {
+		fullName:  "Cher",
+		nameOrder: nameOrderFirstLast,
+		wantErr:   true,
+	},
+	"empty after comma": {
+		fullName:  "Zephyr,",
+		nameOrder: nameOrderFirstLast,
+		wantErr:   true,
+	},
+}
+
+func TestSplitName(t *testing.T) {
+	t.Parallel()
+
+	for testName, tt := range splitNameCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := splitName(tt.fullName, tt.nameOrder)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("splitName(%q) = %v; want error", tt.fullName, got)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitName(%q) returned error: %v", tt.fullName, err)
+			}
+			if got != tt.want {
+				t.Fatalf("splitName(%q) = %+v; want %+v", tt.fullName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRosterCSVDuplicateID(t *testing.T) {
+	t.Parallel()
+
+	csvFile := filepath.Join(t.TempDir(), "roster.csv")
+	mustWriteFixtureFile(t, csvFile, rosterFixtureCSV+"Bob Young,bob@example.com,1001\n")
+
+	_, err := readRosterCSV(rosterCfg{
+		file:        csvFile,
+		emailColumn: "Email",
+		nameColumn:  "Student Name",
+		idColumn:    "ID",
+		nameOrder:   nameOrderFirstLast,
+	})
+	if err == nil || !strings.Contains(err.Error(), `duplicate student ID "1001" for "alice@example.com" and "bob@example.com"`) {
+		t.Fatalf("readRosterCSV() error = %v; want a duplicate ID error", err)
+	}
+}
+
+func TestPublicGradebookRosterDuplicateMergedID(t *testing.T) {
+	t.Parallel()
+
+	// Alice's row has no ID, so she keeps 1003, which Carol's row also uses.
+	dir := writeFixtureDir(t, `{
+    "students_by_email": {
+        "alice@example.com": {"first_name": "Alice", "last_name": "Zephyr", "student_id": "1003"}
+    }
+}`, nil)
+	classFile := filepath.Join(dir, suiteClassFile)
+	before, err := os.ReadFile(classFile)
+	if err != nil {
+		t.Fatalf("failed to read class: %v", err)
+	}
+	csvFile := filepath.Join(dir, "roster.csv")
+	mustWriteFixtureFile(t, csvFile, "Student Name,Email,ID\nAlice Zephyr,alice@example.com,\nCarol Xu,carol@example.com,1003\n")
+
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookRoster, []string{
+		"import",
+		"-dir", dir,
+		"-file", csvFile,
+		"-id-column", "ID",
+		"-yes",
+	})
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d (stdout: %q)", exitCode, exitFailure, stdout)
+	}
+	want := `duplicate student ID "1003" for "alice@example.com" and "carol@example.com"`
+	if !strings.Contains(stderr, want) {
+		t.Fatalf("stderr = %q; want it to contain %q", stderr, want)
+	}
+
+	after, err := os.ReadFile(classFile)
+	if err != nil {
+		t.Fatalf("failed to read class: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("class.json was rewritten:\n%s", after)
+	}
+}
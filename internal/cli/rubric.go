@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// rubric is a named list of criteria for scoring an assignment. A gradebook
+// file uses a rubric by naming it in its rubric field.
+type rubric struct {
+	Criteria []*rubricCriterion `json:"criteria"`
+}
+
+// rubricCriterion is one row of a rubric. Each score on the criterion must be
+// between MinPoints and MaxPoints.
+type rubricCriterion struct {
+	Name      string  `json:"name"`
+	Label     string  `json:"label"`
+	MinPoints float64 `json:"min_points"`
+	MaxPoints float64 `json:"max_points"`
+}
+
+func (ce *classExtras) rubric(name string) *rubric {
+	if ce == nil {
+		return nil
+	}
+
+	return ce.RubricsByName[name]
+}
+
+func (ce *classExtras) checkRubrics() error {
+	if ce == nil {
+		return nil
+	}
+
+	errs := make([]error, 0, len(ce.RubricsByName))
+	for _, name := range slices.Sorted(maps.Keys(ce.RubricsByName)) {
+		r := ce.RubricsByName[name]
+		if r == nil || len(r.Criteria) == 0 {
+			errs = append(errs, fmt.Errorf("rubric %q has no criteria", name))
+
+			continue
+		}
+
+		seen := make(map[string]bool, len(r.Criteria))
+		for i, crit := range r.Criteria {
+			switch {
+			case crit == nil:
+				errs = append(errs, fmt.Errorf("rubric %q criterion %d is nil", name, i))
+			case crit.Name == "":
+				errs = append(errs, fmt.Errorf("rubric %q criterion %d has no name", name, i))
+			case seen[crit.Name]:
+				errs = append(errs, fmt.Errorf("rubric %q repeats criterion %q", name, crit.Name))
+			case crit.MinPoints < 0 || crit.MaxPoints <= crit.MinPoints:
+				errs = append(errs, fmt.Errorf("rubric %q criterion %q has an invalid point range", name, crit.Name))
+			default:
+				seen[crit.Name] = true
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkCriterionScores checks a record's criterion scores against the named
+// rubric: every criterion must belong to the rubric, and every score must be
+// within the criterion's point range.
+func (ce *classExtras) checkCriterionScores(rubricName string, ar *assignmentRecord) error {
+	if len(ar.ScoresByCriterion) == 0 {
+		return nil
+	}
+	if rubricName == "" {
+		return fmt.Errorf("criterion scores for %q without a rubric", ar.Email)
+	}
+
+	r := ce.rubric(rubricName)
+	for _, name := range slices.Sorted(maps.Keys(ar.ScoresByCriterion)) {
+		crit := r.criterion(name)
+		if crit == nil {
+			return fmt.Errorf("unknown criterion %q in rubric %q for %q", name, rubricName, ar.Email)
+		}
+
+		points := ar.ScoresByCriterion[name]
+		if points < crit.MinPoints || points > crit.MaxPoints {
+			return fmt.Errorf(
+				"%v points on criterion %q for %q is outside %v-%v",
+				points,
+				name,
+				ar.Email,
+				crit.MinPoints,
+				crit.MaxPoints,
+			)
+		}
+	}
+
+	return nil
+}
+
+// label returns the criterion's label, or its name if it has no label.
+func (crit *rubricCriterion) label() string {
+	if crit.Label == "" {
+		return crit.Name
+	}
+
+	return crit.Label
+}
+
+func (r *rubric) criterion(name string) *rubricCriterion {
+	for _, crit := range r.Criteria {
+		if crit.Name == name {
+			return crit
+		}
+	}
+
+	return nil
+}
+
+// maxPoints returns the total points possible on the rubric.
+func (r *rubric) maxPoints() float64 {
+	var total float64
+	for _, crit := range r.Criteria {
+		total += crit.MaxPoints
+	}
+
+	return total
+}
+
+// grade returns a record's grade on the rubric as a percentage of the points
+// possible, and false if any criterion has not been scored yet.
+func (r *rubric) grade(ar *assignmentRecord) (float64, bool) {
+	var points float64
+	for _, crit := range r.Criteria {
+		score, ok := ar.ScoresByCriterion[crit.Name]
+		if !ok {
+			return 0, false
+		}
+		points += score
+	}
+
+	return points / r.maxPoints() * 100, true
+}
+
+// criterionStats is how a class did on one criterion of a rubric. Percent is
+// the mean as a percentage of the criterion's maximum points.
+type criterionStats struct {
+	criterion *rubricCriterion
+	scored    int
+	mean      float64
+	percent   float64
+}
+
+// breakdown returns the class's results on each criterion that any of the
+// records scored, weakest first, so that the criteria the class struggled
+// with stand out. Criteria with the same percentage keep the rubric's order.
+func (r *rubric) breakdown(records []*assignmentRecord) []criterionStats {
+	stats := make([]criterionStats, 0, len(r.Criteria))
+	for _, crit := range r.Criteria {
+		var scores []float64
+		for _, ar := range records {
+			if points, ok := ar.ScoresByCriterion[crit.Name]; ok {
+				scores = append(scores, points)
+			}
+		}
+		if len(scores) == 0 {
+			continue
+		}
+
+		mean := fmeanScores(scores)
+		stats = append(stats, criterionStats{
+			criterion: crit,
+			scored:    len(scores),
+			mean:      mean,
+			percent:   mean / crit.MaxPoints * 100,
+		})
+	}
+	slices.SortStableFunc(stats, func(a, b criterionStats) int {
+		return cmp.Compare(a.percent, b.percent)
+	})
+
+	return stats
+}
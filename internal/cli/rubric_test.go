@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const rubricClassJSON = `{
+    "rubrics_by_name": {
+        "essay": {
+            "criteria": [
+                {"name": "thesis", "label": "Thesis", "min_points": 1, "max_points": 4},
+                {"name": "evidence", "label": "Evidence", "min_points": 1, "max_points": 4}
+            ]
+        }
+    }
+}`
+
+const rubricGradebookJSON = `{
+    "assignment_category": "major",
+    "assignment_date": "20240319",
+    "rubric": "essay",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": null,
+            "scores_by_criterion": {"thesis": 4, "evidence": 3}
+        },
+        {
+            "email": "alice@example.com",
+            "grade": null,
+            "scores_by_criterion": {"thesis": 2}
+        }
+    ],
+    "assignment_name": "essay-1",
+    "assignment_type": "test"
+}`
+
+func writeRubricFixture(t *testing.T, gbData string) string {
+	t.Helper()
+
+	return writeFixtureDir(t, rubricClassJSON, map[string]string{
+		"test-essay-1-20240319.gradebook": gbData,
+	})
+}
+
+func TestPublicGradebookCalcRubricGrades(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRubricFixture(t, rubricGradebookJSON)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	// Bob has 7 of 8 points. Alice's record is not fully scored yet.
+	want := "" +
+		"Bob Young\n" +
+		"\tOverall average: 88\n" +
+		"\tMajor: 88\n" +
+		"\tMinor: No results\n" +
+		"\tParticipation: No results\n" +
+		"Alice Zephyr\n" +
+		"\tOverall average: No results\n" +
+		"\tMajor: No results\n" +
+		"\tMinor: No results\n" +
+		"\tParticipation: No results\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookUnscoredPartialRubric(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRubricFixture(t, rubricGradebookJSON)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookUnscored, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	got, err := parseUnscoredOutput(stdout)
+	if err != nil {
+		t.Fatalf("parseUnscoredOutput() returned error: %v", err)
+	}
+
+	want := map[string]map[string]int{
+		"Bob Young": {
+			"Major":         0,
+			"Minor":         0,
+			"Participation": 0,
+		},
+		"Alice Zephyr": {
+			"Major":         1,
+			"Minor":         0,
+			"Participation": 0,
+		},
+	}
+
+	assertUnscoredCounts(t, got, want)
+}
+
+var rubricErrorCases = map[string]struct {
+	gbData     string
+	wantStderr string
+}{
+	"points out of range": {
+		gbData:     strings.Replace(rubricGradebookJSON, `"evidence": 3`, `"evidence": 5`, 1),
+		wantStderr: `5 points on criterion "evidence" for "bob@example.com" is outside 1-4`,
+	},
+	"unknown criterion": {
+		gbData:     strings.Replace(rubricGradebookJSON, `"evidence": 3`, `"style": 3`, 1),
+		wantStderr: `unknown criterion "style" in rubric "essay"`,
+	},
+	"unknown rubric": {
+		gbData:     strings.Replace(rubricGradebookJSON, `"rubric": "essay"`, `"rubric": "lab"`, 1),
+		wantStderr: `unknown rubric "lab"`,
+	},
+	"criterion scores without rubric": {
+		gbData:     strings.Replace(rubricGradebookJSON, `"rubric": "essay",`, "", 1),
+		wantStderr: `criterion scores for "bob@example.com" without a rubric`,
+	},
+}
+
+func TestPublicGradebookCalcRubricErrors(t *testing.T) {
+	t.Parallel()
+
+	for testName, tt := range rubricErrorCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeRubricFixture(t, tt.gbData)
+			exitCode, _, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+
+			if exitCode != exitFailure {
+				t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+			}
+			if !strings.Contains(stderr, tt.wantStderr) {
+				t.Fatalf("stderr = %q; want it to contain %q", stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRubricBreakdown(t *testing.T) {
+	t.Parallel()
+
+	r := &rubric{Criteria: []*rubricCriterion{
+		{Name: "thesis", MinPoints: 1, MaxPoints: 4},
+		{Name: "evidence", MinPoints: 1, MaxPoints: 4},
+		{Name: "style", MinPoints: 1, MaxPoints: 4},
+		{Name: "sources", MinPoints: 1, MaxPoints: 4},
+	}}
+	records := []*assignmentRecord{
+		{Email: "alice@example.com", ScoresByCriterion: map[string]float64{"thesis": 4, "evidence": 2, "style": 3}},
+		{Email: "bob@example.com", ScoresByCriterion: map[string]float64{"thesis": 4, "evidence": 3, "style": 2}},
+		{Email: "carol@example.com"},
+	}
+
+	stats := r.breakdown(records)
+	got := make([]string, 0, len(stats))
+	for _, cs := range stats {
+		got = append(got, fmt.Sprintf("%s %d %s %s", cs.criterion.Name, cs.scored, formatScore(cs.mean), formatScore(cs.percent)))
+	}
+	// Evidence and style tie, so they keep the rubric's order, and sources
+	// is left out since no one was scored on it.
+	want := []string{"evidence 2 2.5 62.5", "style 2 2.5 62.5", "thesis 2 4 100"}
+	if !slices.Equal(got, want) {
+		t.Errorf("breakdown() = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/telemachus/gradebook"
+)
+
+const noSectionLabel = "(none)"
+
+// GradebookSections prints each section's category means side by side.
+func GradebookSections(args []string) int {
+	cmd := cmdFrom("gradebook-sections", sectionsUsage)
+
+	return runCommand(cmd, args, commandRun[string]{
+		parse:     (*cmdEnv).parseSections,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, term string) {
+			cmd.findTerm(class, term)
+			cmd.checkHasSections()
+			cmd.loadGrades(class, term)
+			cmd.printSections(class)
+		},
+	})
+}
+
+func (cmd *cmdEnv) parseSections(args []string) string {
+	og := cmd.commonOptsGroup(parseOpts{store: true})
+
+	term := ""
+	og.String(&term, "term", "")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return ""
+	}
+
+	return term
+}
+
+func (cmd *cmdEnv) checkHasSections() {
+	if cmd.noOp() {
+		return
+	}
+
+	if len(cmd.extras.sections()) == 0 {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: no students have a section\n", cmd.name)
+	}
+}
+
+func (cmd *cmdEnv) printSections(class *gradebook.Class) {
+	if cmd.noOp() {
+		return
+	}
+
+	sections := cmd.extras.sections()
+	emailsBySection := make(map[string][]string, len(sections))
+	for _, email := range class.EmailsSortedByStudentName() {
+		section := cmd.extras.student(email).Section
+		emailsBySection[section] = append(emailsBySection[section], email)
+	}
+	if _, ok := emailsBySection[""]; ok {
+		sections = append(sections, "")
+	}
+
+	tw := tabwriter.NewWriter(cmd.stdout, 0, 0, 2, ' ', 0)
+
+	header := make([]string, 0, len(sections)+1)
+	header = append(header, "Section")
+	counts := make([]string, 0, len(sections)+1)
+	counts = append(counts, "Students")
+	for _, section := range sections {
+		header = append(header, cmp.Or(section, noSectionLabel))
+		counts = append(counts, fmt.Sprint(len(emailsBySection[section])))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	fmt.Fprintln(tw, strings.Join(counts, "\t"))
+
+	printRow := func(label string, avg func(*gradebook.Student) gradebook.AverageResult) {
+		row := make([]string, 0, len(sections)+1)
+		row = append(row, label)
+		for _, section := range sections {
+			row = append(row, meanOfAverages(class, emailsBySection[section], avg).String())
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	printRow("Overall average", func(s *gradebook.Student) gradebook.AverageResult {
+		return s.TotalAverage(class.WeightsByAssignmentCategory)
+	})
+	for _, cat := range class.AssignmentCategoriesSortedByLabel() {
+		printRow(class.LabelsByAssignmentCategory[cat], func(s *gradebook.Student) gradebook.AverageResult {
+			return s.Average(cat)
+		})
+	}
+
+	if err := tw.Flush(); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+	}
+}
+
+// meanOfAverages returns the mean of the valid averages of the given
+// students. Students without a valid average do not count toward the mean.
+func meanOfAverages(
+	class *gradebook.Class,
+	emails []string,
+	avg func(*gradebook.Student) gradebook.AverageResult,
+) gradebook.AverageResult {
+	var sum float64
+	var n int
+	for _, email := range emails {
+		result := avg(class.StudentsByEmail[email])
+		if !result.Valid {
+			continue
+		}
+		sum += result.Value
+		n++
+	}
+
+	if n == 0 {
+		return gradebook.AverageResult{Valid: false}
+	}
+
+	return gradebook.AverageResult{Value: sum / float64(n), Valid: true}
+}
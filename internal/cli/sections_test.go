@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const sectionsGradebookFixtureJSON = `{
+    "assignment_category": "major",
+    "assignment_date": "20240320",
+    "assignment_records": [
+        {
+            "email": "alice@example.com",
+            "grade": 80
+        },
+        {
+            "email": "bob@example.com",
+            "grade": 70
+        },
+        {
+            "email": "carol@example.com",
+            "grade": 100
+        }
+    ],
+    "assignment_name": "test-1",
+    "assignment_type": "test"
+}`
+
+func writeSectionsFixture(t *testing.T) string {
+	t.Helper()
+
+	dir := writeExtrasFixture(t)
+	mustWriteFixtureFile(t, filepath.Join(dir, "test-test-1-20240320.gradebook"), sectionsGradebookFixtureJSON)
+
+	return dir
+}
+
+func TestPublicGradebookSections(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSectionsFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookSections, []string{"-dir", dir, "-term", "q1"})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "" +
+		"Section          A           B\n" +
+		"Students         2           1\n" +
+		"Overall average  90          78\n" +
+		"Major            90          70\n" +
+		"Minor            No results  90\n" +
+		"Participation    No results  No results\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookSectionsWithoutSections(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookSections, []string{"-dir", dir})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if stdout != "" {
+		t.Fatalf("stdout = %q; want empty", stdout)
+	}
+	if want := "gradebook-sections: no students have a section\n"; stderr != want {
+		t.Fatalf("stderr mismatch:\nwant:\n%q\ngot:\n%q", want, stderr)
+	}
+}
+
+func TestPublicGradebookCalcSection(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSectionsFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir, "-section", "B"})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "" +
+		"Bob Young\n" +
+		"\tOverall average: 78\n" +
+		"\tMajor: 70\n" +
+		"\tMinor: 90\n" +
+		"\tParticipation: No results\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookUnscoredSection(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSectionsFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookUnscored, []string{"-dir", dir, "-section", "A"})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	got, err := parseUnscoredOutput(stdout)
+	if err != nil {
+		t.Fatalf("parseUnscoredOutput() returned error: %v", err)
+	}
+
+	want := map[string]map[string]int{
+		"Carol Xu": {
+			"Major":         0,
+			"Minor":         0,
+			"Participation": 0,
+		},
+		"Alice Zephyr": {
+			"Major":         0,
+			"Minor":         1,
+			"Participation": 0,
+		},
+	}
+
+	assertUnscoredCounts(t, got, want)
+}
@@ -0,0 +1,627 @@
+package cli
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/telemachus/gradebook"
+	"github.com/telemachus/gradebook-suite/internal/chart"
+)
+
+//go:embed templates/site.html
+var siteFS embed.FS
+
+var siteTemplates = template.Must(template.ParseFS(siteFS, "templates/site.html"))
+
+const (
+	siteAssignmentsDir = "assignments"
+	siteStudentsDir    = "students"
+	siteExtraCredit    = "Extra credit"
+)
+
+// GradebookSite generates a static HTML site for a class: an index with the
+// roster and averages, a page for each assignment, and a page for each
+// student.
+func GradebookSite(args []string) int {
+	cmd := cmdFrom("gradebook-site", siteUsage)
+
+	return runCommand(cmd, args, commandRun[siteCfg]{
+		parse:     (*cmdEnv).parseSite,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg siteCfg) {
+			cmd.findTerm(class, cfg.term)
+			cmd.findSection()
+			gbFiles := cmd.readGradebooks(class, cfg.term)
+			pages := cmd.buildSite(class, gbFiles)
+			cmd.writeSite(cfg.out, pages)
+		},
+	})
+}
+
+type siteCfg struct {
+	term string
+	out  string
+	asOf string
+}
+
+func (cmd *cmdEnv) parseSite(args []string) siteCfg {
+	og := cmd.commonOptsGroup(parseOpts{lastFirst: true, section: true, store: true})
+
+	var cfg siteCfg
+	og.String(&cfg.term, "term", "")
+	og.String(&cfg.out, "out", "")
+	og.String(&cfg.asOf, "as-of", "")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkNameStyle()
+	cmd.checkSiteCfg(cfg)
+
+	return cfg
+}
+
+func (cmd *cmdEnv) checkSiteCfg(cfg siteCfg) {
+	if cmd.noOp() {
+		return
+	}
+
+	switch {
+	case cfg.out == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -out is required\n", cmd.name)
+	case cfg.asOf != "" && !isDate(cfg.asOf):
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: invalid yyyymmdd date for -as-of: %q\n", cmd.name, cfg.asOf)
+	case cfg.asOf != "":
+		// Missing work is judged as of this date instead of today, so the
+		// site does not change from one day to the next.
+		cmd.today = cfg.asOf
+		cmd.asOf = true
+	}
+}
+
+// sitePage holds what every page of the site shows. Root is the relative path
+// from the page back to the top of the site.
+type sitePage struct {
+	Title string
+	Class string
+	Root  string
+	Chart template.HTML
+}
+
+// siteField is one labeled value in a table of details or statistics.
+type siteField struct {
+	Label string
+	Value string
+}
+
+type siteIndex struct {
+	sitePage
+	Categories  []string
+	Students    []siteStudentRow
+	Assignments []siteAssignmentRow
+	HasSections bool
+}
+
+type siteStudentRow struct {
+	Name       string
+	Email      string
+	Section    string
+	Link       string
+	Overall    string
+	Categories []string
+}
+
+type siteAssignmentRow struct {
+	Date     string
+	Name     string
+	Category string
+	Link     string
+	Scored   string
+	Mean     string
+}
+
+type siteAssignment struct {
+	sitePage
+	Details  []siteField
+	Stats    []siteField
+	Criteria []siteCriterion
+	Records  []siteAssignmentRecord
+}
+
+type siteCriterion struct {
+	Label   string
+	Scored  string
+	Mean    string
+	Percent string
+}
+
+type siteAssignmentRecord struct {
+	Student string
+	Link    string
+	Grade   string
+	Score   string
+	Status  string
+	Comment string
+}
+
+type siteStudent struct {
+	sitePage
+	Details []siteField
+	Stats   []siteField
+	Records []siteStudentRecord
+}
+
+type siteStudentRecord struct {
+	Date       string
+	Assignment string
+	Link       string
+	Category   string
+	Grade      string
+	Score      string
+	Status     string
+	Comment    string
+	Criteria   []string
+}
+
+// siteBuilder holds what every page needs while the site is built.
+type siteBuilder struct {
+	cmd     *cmdEnv
+	class   *gradebook.Class
+	gbFiles []*gradebookFile
+	emails  []string
+	inPage  map[string]bool
+	trends  map[string][]trendPoint
+	pages   map[string][]byte
+}
+
+// buildSite renders every page of the site and returns them by path relative
+// to the top of the site. Pages depend only on the class and gradebook files,
+// so the same input always gives the same output.
+func (cmd *cmdEnv) buildSite(class *gradebook.Class, gbFiles []*gradebookFile) map[string][]byte {
+	if cmd.noOp() {
+		return nil
+	}
+
+	sb := &siteBuilder{
+		cmd:     cmd,
+		class:   class,
+		gbFiles: sortedByDate(gbFiles),
+		emails:  cmd.emailsInSection(class),
+		trends:  cmd.replayGrades(class, gbFiles),
+		pages:   make(map[string][]byte),
+	}
+	sb.inPage = make(map[string]bool, len(sb.emails))
+	for _, email := range sb.emails {
+		sb.inPage[email] = true
+	}
+	addGrades(class, cmd.extras, gbFiles, cmd.today)
+
+	sb.render("index.html", "index", sb.index())
+	for _, gbf := range sb.gbFiles {
+		sb.render(assignmentPage(gbf), "assignment", sb.assignment(gbf))
+	}
+	for _, email := range sb.emails {
+		sb.render(studentPage(email), "student", sb.student(email))
+	}
+
+	return sb.pages
+}
+
+func (sb *siteBuilder) render(page, name string, data any) {
+	if sb.cmd.noOp() {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := siteTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		sb.cmd.exitValue = exitFailure
+		fmt.Fprintf(sb.cmd.stderr, "%s: problem rendering %s: %s\n", sb.cmd.name, page, err)
+
+		return
+	}
+	sb.pages[page] = buf.Bytes()
+}
+
+// assignmentPage returns the path of an assignment's page, named after its
+// gradebook file.
+func assignmentPage(gbf *gradebookFile) string {
+	stem := strings.TrimSuffix(filepath.Base(gbf.path), gradebookSuffix)
+
+	return path.Join(siteAssignmentsDir, sitePageName(stem))
+}
+
+// studentPage returns the path of a student's page, named after the student's
+// email.
+func studentPage(email string) string {
+	return path.Join(siteStudentsDir, sitePageName(email))
+}
+
+// sitePageName turns name into the file name of a page. Every byte other than
+// a letter, digit, period, or hyphen becomes "_" and two hex digits, and "_"
+// is escaped too, so two names never share a page: a+b@example.com and
+// a-b@example.com become a_2bb_40example.com.html and a-b_40example.com.html.
+func sitePageName(name string) string {
+	var b strings.Builder
+	for _, c := range []byte(name) {
+		switch {
+		case c == '_' || invalidGbNameRegex.Match([]byte{c}):
+			fmt.Fprintf(&b, "_%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String() + ".html"
+}
+
+func (sb *siteBuilder) page(title, root string) sitePage {
+	return sitePage{Title: title, Class: sb.class.Name, Root: root}
+}
+
+func (sb *siteBuilder) categoryLabel(gbf *gradebookFile) string {
+	if cat := gbf.category(sb.class); cat != "" {
+		return sb.class.LabelsByAssignmentCategory[cat]
+	}
+
+	return siteExtraCredit
+}
+
+func (sb *siteBuilder) index() siteIndex {
+	cats := sb.class.AssignmentCategoriesSortedByLabel()
+	idx := siteIndex{
+		sitePage:    sb.page(sb.class.Name, ""),
+		Students:    make([]siteStudentRow, 0, len(sb.emails)),
+		Assignments: make([]siteAssignmentRow, 0, len(sb.gbFiles)),
+		HasSections: len(sb.cmd.extras.sections()) > 0,
+	}
+
+	groups := make([]chart.BoxGroup, 0, len(cats))
+	for _, cat := range cats {
+		idx.Categories = append(idx.Categories, sb.class.LabelsByAssignmentCategory[cat])
+		groups = append(groups, chart.BoxGroup{Label: sb.class.LabelsByAssignmentCategory[cat]})
+	}
+
+	for _, email := range sb.emails {
+		s := sb.class.StudentsByEmail[email]
+		row := siteStudentRow{
+			Name:    sb.cmd.studentName(s, email),
+			Email:   email,
+			Section: sb.cmd.extras.student(email).Section,
+			Link:    studentPage(email),
+			Overall: s.TotalAverage(sb.class.WeightsByAssignmentCategory).String(),
+		}
+		for i, cat := range cats {
+			avg := s.Average(cat)
+			row.Categories = append(row.Categories, avg.String())
+			if avg.Valid {
+				groups[i].Values = append(groups[i].Values, avg.Value)
+			}
+		}
+		idx.Students = append(idx.Students, row)
+	}
+	idx.Chart = template.HTML(chart.BoxPlot("Student averages by category", groups)) //nolint:gosec // Generated SVG.
+
+	for _, gbf := range sb.gbFiles {
+		scores, records := sb.scores(gbf)
+		row := siteAssignmentRow{
+			Date:     gbf.AssignmentDate,
+			Name:     gbf.AssignmentName,
+			Category: sb.categoryLabel(gbf),
+			Link:     assignmentPage(gbf),
+			Scored:   fmt.Sprintf("%d/%d", len(scores), records),
+			Mean:     "-",
+		}
+		if len(scores) > 0 {
+			row.Mean = formatScore(fmeanScores(scores))
+		}
+		idx.Assignments = append(idx.Assignments, row)
+	}
+
+	return idx
+}
+
+// scores returns the scores on gbf of the students on the site, along with
+// the number of their records.
+func (sb *siteBuilder) scores(gbf *gradebookFile) ([]float64, int) {
+	cat := gbf.category(sb.class)
+	scores := make([]float64, 0, len(gbf.AssignmentRecords))
+	records := 0
+	for _, ar := range gbf.AssignmentRecords {
+		if !sb.inPage[ar.Email] {
+			continue
+		}
+		records++
+		if score, ok := sb.cmd.extras.score(cat, gbf, ar); ok {
+			scores = append(scores, score)
+		}
+	}
+
+	return scores, records
+}
+
+func (sb *siteBuilder) assignment(gbf *gradebookFile) siteAssignment {
+	page := siteAssignment{
+		sitePage: sb.page(gbf.AssignmentName, "../"),
+		Details: []siteField{
+			{Label: "Date", Value: gbf.AssignmentDate},
+			{Label: "Due", Value: gbf.dueDate()},
+			{Label: "Type", Value: gbf.AssignmentType},
+			{Label: "Category", Value: sb.categoryLabel(gbf)},
+		},
+	}
+	if gbf.Curve != nil {
+		page.Details = append(page.Details, siteField{Label: "Curve", Value: gbf.Curve.String()})
+	}
+	if gbf.Rubric != "" {
+		page.Details = append(page.Details, siteField{Label: "Rubric", Value: gbf.Rubric})
+	}
+
+	scores, records := sb.scores(gbf)
+	page.Stats = []siteField{
+		{Label: "Records", Value: strconv.Itoa(records)},
+		{Label: "Scored", Value: strconv.Itoa(len(scores))},
+	}
+	if len(scores) > 0 {
+		page.Stats = append(page.Stats,
+			siteField{Label: "Mean", Value: formatScore(fmeanScores(scores))},
+			siteField{Label: "Median", Value: formatScore(medianScores(scores))},
+			siteField{Label: "Low", Value: formatScore(slices.Min(scores))},
+			siteField{Label: "High", Value: formatScore(slices.Max(scores))},
+		)
+		page.Chart = template.HTML(chart.Histogram("Scores", scores)) //nolint:gosec // Generated SVG.
+	}
+
+	byEmail := recordsByEmail(gbf)
+	page.Criteria = sb.criteriaBreakdown(gbf, byEmail)
+	for _, email := range sb.emails {
+		ar, ok := byEmail[email]
+		if !ok {
+			continue
+		}
+		grade, score, status := sb.describeRecord(gbf, ar)
+		page.Records = append(page.Records, siteAssignmentRecord{
+			Student: sb.cmd.studentName(sb.class.StudentsByEmail[email], email),
+			Link:    "../" + studentPage(email),
+			Grade:   grade,
+			Score:   score,
+			Status:  status,
+			Comment: ar.Comment,
+		})
+	}
+
+	return page
+}
+
+// criteriaBreakdown returns the mean on each criterion of the assignment's
+// rubric for the students in the site, weakest criterion first.
+func (sb *siteBuilder) criteriaBreakdown(gbf *gradebookFile, byEmail map[string]*assignmentRecord) []siteCriterion {
+	if gbf.Rubric == "" {
+		return nil
+	}
+
+	records := make([]*assignmentRecord, 0, len(sb.emails))
+	for _, email := range sb.emails {
+		if ar, ok := byEmail[email]; ok {
+			records = append(records, ar)
+		}
+	}
+
+	r := sb.cmd.extras.rubric(gbf.Rubric)
+	stats := r.breakdown(records)
+	criteria := make([]siteCriterion, 0, len(stats))
+	for _, cs := range stats {
+		criteria = append(criteria, siteCriterion{
+			Label:   cs.criterion.label(),
+			Scored:  strconv.Itoa(cs.scored),
+			Mean:    formatScore(cs.mean) + "/" + formatScore(cs.criterion.MaxPoints),
+			Percent: formatScore(cs.percent) + "%",
+		})
+	}
+
+	return criteria
+}
+
+func recordsByEmail(gbf *gradebookFile) map[string]*assignmentRecord {
+	byEmail := make(map[string]*assignmentRecord, len(gbf.AssignmentRecords))
+	for _, ar := range gbf.AssignmentRecords {
+		byEmail[ar.Email] = ar
+	}
+
+	return byEmail
+}
+
+// describeRecord returns a record's grade as entered, the score that counts
+// after the class's policies, and a note on whether the work was late or is
+// missing.
+func (sb *siteBuilder) describeRecord(gbf *gradebookFile, ar *assignmentRecord) (string, string, string) {
+	cat := gbf.category(sb.class)
+	extras := sb.cmd.extras
+
+	grade, ok := extras.grade(gbf, ar)
+	if !ok {
+		if extras.isMissing(cat, gbf, sb.cmd.today) {
+			return "", "0", "missing"
+		}
+
+		return "", "", "unscored"
+	}
+
+	score, _ := extras.score(cat, gbf, ar)
+	status := ""
+	if days := daysLate(gbf.dueDate(), ar.Submitted); days == 1 {
+		status = "1 day late"
+	} else if days > 1 {
+		status = fmt.Sprintf("%d days late", days)
+	}
+
+	return formatScore(grade), formatScore(score), status
+}
+
+func (sb *siteBuilder) student(email string) siteStudent {
+	s := sb.class.StudentsByEmail[email]
+	se := sb.cmd.extras.student(email)
+	page := siteStudent{
+		sitePage: sb.page(sb.cmd.studentName(s, email), "../"),
+		Details:  []siteField{{Label: "Email", Value: email}},
+	}
+	if se.Section != "" {
+		page.Details = append(page.Details, siteField{Label: "Section", Value: se.Section})
+	}
+	if se.Pronouns != "" {
+		page.Details = append(page.Details, siteField{Label: "Pronouns", Value: se.Pronouns})
+	}
+
+	total := s.TotalAverage(sb.class.WeightsByAssignmentCategory)
+	page.Stats = append(page.Stats, siteField{Label: "Overall average", Value: total.String()})
+	if sb.cmd.extras.hasExtraCredit() {
+		bonus := sb.cmd.extras.extraCreditPoints(sb.gbFiles)[email]
+		page.Stats = append(page.Stats,
+			siteField{Label: "Extra credit", Value: "+" + formatScore(bonus)},
+			siteField{Label: "Overall with extra credit", Value: withBonus(total, bonus).String()},
+		)
+	}
+	for _, cat := range sb.class.AssignmentCategoriesSortedByLabel() {
+		page.Stats = append(page.Stats, siteField{
+			Label: sb.class.LabelsByAssignmentCategory[cat],
+			Value: s.Average(cat).String(),
+		})
+	}
+	page.Chart = sb.studentChart(email)
+
+	for _, gbf := range sb.gbFiles {
+		ar, ok := recordsByEmail(gbf)[email]
+		if !ok {
+			continue
+		}
+		grade, score, status := sb.describeRecord(gbf, ar)
+		page.Records = append(page.Records, siteStudentRecord{
+			Date:       gbf.AssignmentDate,
+			Assignment: gbf.AssignmentName,
+			Link:       "../" + assignmentPage(gbf),
+			Category:   sb.categoryLabel(gbf),
+			Grade:      grade,
+			Score:      score,
+			Status:     status,
+			Comment:    ar.Comment,
+			Criteria:   sb.criteria(gbf, ar),
+		})
+	}
+
+	return page
+}
+
+func (sb *siteBuilder) studentChart(email string) template.HTML {
+	points := sb.trends[email]
+	if len(points) == 0 {
+		return ""
+	}
+
+	xLabels := make([]string, 0, len(points))
+	for _, p := range points {
+		xLabels = append(xLabels, p.gbf.AssignmentName)
+	}
+	lines := []chart.Line{trendChartLine("Overall", points, func(p trendPoint) gradebook.AverageResult {
+		return p.overall
+	})}
+	for _, cat := range sb.class.AssignmentCategoriesSortedByLabel() {
+		lines = append(lines, trendChartLine(sb.class.LabelsByAssignmentCategory[cat], points, func(p trendPoint) gradebook.AverageResult {
+			return p.byCategory[cat]
+		}))
+	}
+
+	return template.HTML(chart.TrendLines("Running averages", xLabels, lines)) //nolint:gosec // Generated SVG.
+}
+
+// criteria describes a record's points on each criterion of the assignment's
+// rubric.
+func (sb *siteBuilder) criteria(gbf *gradebookFile, ar *assignmentRecord) []string {
+	if gbf.Rubric == "" || len(ar.ScoresByCriterion) == 0 {
+		return nil
+	}
+
+	r := sb.cmd.extras.rubric(gbf.Rubric)
+	criteria := make([]string, 0, len(r.Criteria))
+	for _, crit := range r.Criteria {
+		label := crit.label()
+
+		points, ok := ar.ScoresByCriterion[crit.Name]
+		if !ok {
+			criteria = append(criteria, label+": not scored")
+
+			continue
+		}
+		criteria = append(criteria, fmt.Sprintf("%s: %s/%s", label, formatScore(points), formatScore(crit.MaxPoints)))
+	}
+
+	return criteria
+}
+
+// writeSite writes the pages under out, creating directories as needed, and
+// removes the pages of assignments and students that are no longer in the
+// site.
+func (cmd *cmdEnv) writeSite(out string, pages map[string][]byte) {
+	if cmd.noOp() {
+		return
+	}
+
+	for _, dir := range []string{siteAssignmentsDir, siteStudentsDir} {
+		if err := os.MkdirAll(filepath.Join(out, dir), 0o755); err != nil {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+			return
+		}
+	}
+
+	for _, page := range slices.Sorted(maps.Keys(pages)) {
+		if err := replaceFile(filepath.Join(out, filepath.FromSlash(page)), pages[page]); err != nil {
+			cmd.exitValue = exitFailure
+			fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+			return
+		}
+	}
+
+	if err := removeStalePages(out, pages); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+	}
+}
+
+// removeStalePages removes the HTML files in the assignment and student
+// directories under out that are not among pages, such as the page of
+// a student who has left the class since the site was last generated.
+func removeStalePages(out string, pages map[string][]byte) error {
+	for _, dir := range []string{siteAssignmentsDir, siteStudentsDir} {
+		files, err := filepath.Glob(filepath.Join(out, dir, "*.html"))
+		if err != nil {
+			return fmt.Errorf("find old pages in %q: %w", dir, err)
+		}
+		for _, file := range files {
+			if _, ok := pages[path.Join(dir, filepath.Base(file))]; ok {
+				continue
+			}
+			if err = os.Remove(file); err != nil {
+				return fmt.Errorf("remove old page: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
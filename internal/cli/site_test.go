@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const siteGradebookJSON = `{
+    "assignment_category": "minor",
+    "assignment_date": "20240402",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 85,
+            "submitted": "20240404",
+            "comment": "Watch <units> & signs"
+        },
+        {
+            "email": "alice@example.com",
+            "grade": null
+        }
+    ],
+    "assignment_name": "quiz-2",
+    "assignment_type": "quiz"
+}`
+
+func writeSiteFixture(t *testing.T) string {
+	t.Helper()
+
+	dir := writeTrendFixture(t)
+	mustWriteFixtureFile(t, filepath.Join(dir, "quiz-quiz-2-20240402.gradebook"), siteGradebookJSON)
+
+	return dir
+}
+
+func readSite(t *testing.T, out string) map[string][]byte {
+	t.Helper()
+
+	pages := make(map[string][]byte)
+	err := filepath.WalkDir(out, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(out, p)
+		if err != nil {
+			return err
+		}
+		pages[filepath.ToSlash(rel)] = data
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read site: %v", err)
+	}
+
+	return pages
+}
+
+func TestPublicGradebookSite(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSiteFixture(t)
+	out := filepath.Join(t.TempDir(), "site")
+	exitCode, _, stderr := runPublicCommand(t, GradebookSite, []string{"-dir", dir, "-out", out})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	pages := readSite(t, out)
+	for _, page := range []string{
+		"index.html",
+		"assignments/quiz-quiz-1-20240319.html",
+		"assignments/quiz-quiz-2-20240402.html",
+		"assignments/test-test-1-20240401.html",
+		"students/alice_40example.com.html",
+		"students/bob_40example.com.html",
+	} {
+		if _, ok := pages[page]; !ok {
+			t.Fatalf("site is missing %s; got %d pages", page, len(pages))
+		}
+	}
+	if len(pages) != 6 {
+		t.Fatalf("site has %d pages; want 6", len(pages))
+	}
+
+	index := string(pages["index.html"])
+	for _, want := range []string{
+		`<a href="students/bob_40example.com.html">Bob Young</a>`,
+		`<a href="assignments/quiz-quiz-2-20240402.html">quiz-2</a>`,
+		"<svg ",
+	} {
+		if !strings.Contains(index, want) {
+			t.Fatalf("index.html does not contain %q", want)
+		}
+	}
+
+	bob := string(pages["students/bob_40example.com.html"])
+	for _, want := range []string{
+		"Watch &lt;units&gt; &amp; signs",
+		"2 days late",
+		"<title>Running averages</title>",
+	} {
+		if !strings.Contains(bob, want) {
+			t.Fatalf("Bob's page does not contain %q", want)
+		}
+	}
+
+	quiz := string(pages["assignments/quiz-quiz-2-20240402.html"])
+	if !strings.Contains(quiz, "<tr><th>Mean</th><td class=\"num\">85</td></tr>") {
+		t.Fatalf("quiz-2 page does not show the mean:\n%s", quiz)
+	}
+}
+
+func TestPublicGradebookSiteIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSiteFixture(t)
+	outA := filepath.Join(t.TempDir(), "site")
+	outB := filepath.Join(t.TempDir(), "site")
+
+	for _, out := range []string{outA, outB} {
+		exitCode, _, stderr := runPublicCommand(t, GradebookSite, []string{
+			"-dir", dir,
+			"-out", out,
+			"-as-of", "20240501",
+		})
+		if exitCode != exitSuccess {
+			t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+		}
+	}
+
+	pagesA, pagesB := readSite(t, outA), readSite(t, outB)
+	if len(pagesA) != len(pagesB) {
+		t.Fatalf("runs wrote %d and %d pages", len(pagesA), len(pagesB))
+	}
+	for page, data := range pagesA {
+		if !bytes.Equal(data, pagesB[page]) {
+			t.Fatalf("%s differs between runs", page)
+		}
+	}
+}
+
+func TestPublicGradebookSiteRemovesStalePages(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSiteFixture(t)
+	out := filepath.Join(t.TempDir(), "site")
+	exitCode, _, stderr := runPublicCommand(t, GradebookSite, []string{"-dir", dir, "-out", out})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	// quiz-2 is deleted, and the site has a page for a student who left.
+	if err := os.Remove(filepath.Join(dir, "quiz-quiz-2-20240402.gradebook")); err != nil {
+		t.Fatalf("failed to remove gradebook file: %v", err)
+	}
+	mustWriteFixtureFile(t, filepath.Join(out, "students", "carol_40example.com.html"), "<p>Carol</p>\n")
+	exitCode, _, stderr = runPublicCommand(t, GradebookSite, []string{"-dir", dir, "-out", out})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	pages := readSite(t, out)
+	for _, page := range []string{"assignments/quiz-quiz-2-20240402.html", "students/carol_40example.com.html"} {
+		if _, ok := pages[page]; ok {
+			t.Errorf("site still has %s", page)
+		}
+	}
+	if len(pages) != 5 {
+		t.Errorf("site has %d pages; want 5", len(pages))
+	}
+}
+
+func TestPublicGradebookSiteRubricAndMissing(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFixtureDir(t, `{
+    "rubrics_by_name": {
+        "essay": {
+            "criteria": [
+                {"name": "thesis", "label": "Thesis", "min_points": 0, "max_points": 4},
+                {"name": "evidence", "label": "Evidence", "min_points": 0, "max_points": 6}
+            ]
+        }
+    },
+    "missing_policies_by_assignment_category": {
+        "major": {"policy": "zero"}
+    }
+}`, map[string]string{
+		"test-essay-20240401.gradebook": `{
+    "assignment_category": "major",
+    "assignment_date": "20240401",
+    "assignment_name": "essay",
+    "assignment_type": "test",
+    "rubric": "essay",
+    "assignment_records": [
+        {"email": "bob@example.com", "grade": null, "scores_by_criterion": {"thesis": 3, "evidence": 5}},
+        {"email": "alice@example.com", "grade": null}
+    ]
+}`,
+	})
+
+	out := filepath.Join(t.TempDir(), "site")
+	exitCode, _, stderr := runPublicCommand(t, GradebookSite, []string{"-dir", dir, "-out", out})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	pages := readSite(t, out)
+	if bob := string(pages["students/bob_40example.com.html"]); !strings.Contains(bob, "<li>Thesis: 3/4</li><li>Evidence: 5/6</li>") {
+		t.Fatalf("Bob's page does not show the rubric breakdown:\n%s", bob)
+	}
+	wantCriteria := "" +
+		`<tr><td>Thesis</td><td class="num">1</td><td class="num">3/4</td><td class="num">75%</td></tr>` + "\n" +
+		`<tr><td>Evidence</td><td class="num">1</td><td class="num">5/6</td><td class="num">83.33%</td></tr>`
+	if essay := string(pages["assignments/test-essay-20240401.html"]); !strings.Contains(essay, wantCriteria) {
+		t.Fatalf("the essay's page does not show the class's rubric breakdown:\n%s", essay)
+	}
+	if alice := string(pages["students/alice_40example.com.html"]); !strings.Contains(alice, `<td class="num">0</td><td>missing</td>`) {
+		t.Fatalf("Alice's page does not show missing work as zero:\n%s", alice)
+	}
+}
+
+func TestPublicGradebookSiteKeepsSimilarEmailsApart(t *testing.T) {
+	t.Parallel()
+
+	// Both emails would become a-b-example.com if unsafe characters were
+	// simply replaced.
+	dir := writeFixtureDir(t, `{
+    "students_by_email": {
+        "a+b@example.com": {"first_name": "Ann", "last_name": "Plus"},
+        "a-b@example.com": {"first_name": "Abe", "last_name": "Minus"}
+    }
+}`, map[string]string{
+		"quiz-quiz-1-20240319.gradebook": `{
+    "assignment_category": "minor",
+    "assignment_date": "20240319",
+    "assignment_name": "quiz-1",
+    "assignment_type": "quiz",
+    "assignment_records": [
+        {"email": "a+b@example.com", "grade": 91},
+        {"email": "a-b@example.com", "grade": 42}
+    ]
+}`,
+	})
+	out := filepath.Join(t.TempDir(), "site")
+	exitCode, _, stderr := runPublicCommand(t, GradebookSite, []string{"-dir", dir, "-out", out})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	pages := readSite(t, out)
+	for page, want := range map[string]string{
+		"students/a_2bb_40example.com.html": "Ann Plus",
+		"students/a-b_40example.com.html":   "Abe Minus",
+	} {
+		if !strings.Contains(string(pages[page]), want) {
+			t.Errorf("%s does not contain %q", page, want)
+		}
+	}
+	if len(pages) != 4 {
+		t.Errorf("site has %d pages; want 4", len(pages))
+	}
+}
+
+func TestPublicGradebookSiteRequiresOut(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookSite, []string{"-dir", dir})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.Contains(stderr, "-out is required") {
+		t.Fatalf("stderr = %q; want -out error", stderr)
+	}
+}
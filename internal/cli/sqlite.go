@@ -0,0 +1,472 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/telemachus/gradebook"
+
+	_ "modernc.org/sqlite" // Registers the "sqlite" database/sql driver.
+)
+
+// sqliteSchema describes the database that gradebook-sqlite exports. Dates are
+// YYYYMMDD text, as in gradebook files. A record's grade is as recorded, and
+// its score is what counts toward the student's average. The files table
+// holds class.json and every gradebook file exactly as exported.
+const sqliteSchema = `
+CREATE TABLE class (
+    name TEXT NOT NULL
+);
+CREATE TABLE terms (
+    id TEXT PRIMARY KEY,
+    start_date TEXT NOT NULL,
+    end_date TEXT NOT NULL
+);
+CREATE TABLE categories (
+    id TEXT PRIMARY KEY,
+    label TEXT NOT NULL,
+    weight INTEGER NOT NULL
+);
+CREATE TABLE assignment_types (
+    type TEXT PRIMARY KEY,
+    category TEXT REFERENCES categories (id),
+    extra_credit INTEGER NOT NULL
+);
+CREATE TABLE students (
+    email TEXT PRIMARY KEY,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    preferred_name TEXT,
+    pronouns TEXT,
+    section TEXT,
+    student_id TEXT
+);
+CREATE TABLE gradebooks (
+    id INTEGER PRIMARY KEY,
+    file TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL REFERENCES assignment_types (type),
+    date TEXT NOT NULL,
+    due_date TEXT NOT NULL,
+    rubric TEXT
+);
+CREATE TABLE records (
+    gradebook_id INTEGER NOT NULL REFERENCES gradebooks (id),
+    email TEXT NOT NULL REFERENCES students (email),
+    grade REAL,
+    score REAL,
+    submitted TEXT,
+    comment TEXT,
+    PRIMARY KEY (gradebook_id, email)
+);
+CREATE TABLE files (
+    name TEXT PRIMARY KEY,
+    data BLOB NOT NULL
+);
+CREATE VIEW grades AS
+SELECT
+    s.email,
+    s.first_name,
+    s.last_name,
+    g.name AS assignment,
+    g.type,
+    t.category,
+    g.date,
+    r.grade,
+    r.score
+FROM records AS r
+JOIN gradebooks AS g ON g.id = r.gradebook_id
+JOIN students AS s ON s.email = r.email
+JOIN assignment_types AS t ON t.type = g.type;
+`
+
+// GradebookSQLite exports a class to a SQLite database and imports grades
+// back from it.
+func GradebookSQLite(args []string) int {
+	cmd := cmdFrom("gradebook-sqlite", sqliteUsage)
+
+	return runCommand(cmd, args, commandRun[sqliteCfg]{
+		parse:     (*cmdEnv).parseSQLite,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg sqliteCfg) {
+			gbFiles := cmd.readGradebooks(class, "")
+			switch cfg.subcommand {
+			case "export":
+				cmd.exportSQLite(class, gbFiles, cfg.out)
+			default:
+				changes := cmd.matchSQLite(class, gbFiles, cfg.file)
+				cmd.printGradeChanges(class, changes)
+				if !cmd.confirmGradeChanges(changes, cfg.yes) {
+					return
+				}
+				cmd.rewriteGrades(changes)
+			}
+		},
+	})
+}
+
+type sqliteCfg struct {
+	subcommand string
+	out        string
+	file       string
+	yes        bool
+}
+
+func (cmd *cmdEnv) parseSQLite(args []string) sqliteCfg {
+	var cfg sqliteCfg
+	cfg.subcommand, args = splitSubcommand(args)
+
+	og := cmd.commonOptsGroup(parseOpts{})
+	og.String(&cfg.out, "out", "")
+	og.String(&cfg.file, "file", "")
+	og.Bool(&cfg.yes, "yes")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkSubcommand(cfg.subcommand, "export", "import")
+	if cmd.noOp() {
+		return cfg
+	}
+
+	switch {
+	case cfg.subcommand == "export" && cfg.out == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -out is required\n", cmd.name)
+	case cfg.subcommand == "import" && cfg.file == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -file is required\n", cmd.name)
+	}
+
+	return cfg
+}
+
+func (cmd *cmdEnv) exportSQLite(class *gradebook.Class, gbFiles []*gradebookFile, out string) {
+	if cmd.noOp() {
+		return
+	}
+
+	if err := writeSQLite(out, cmd.classStore(), cmd.classFile, class, cmd.extras, gbFiles); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: problem writing %s: %s\n", cmd.name, out, err)
+	}
+}
+
+// writeSQLite writes the class to a new database in a temporary file and then
+// renames it over out, so a failed export never leaves a partial database.
+func writeSQLite(
+	out string,
+	store classStore,
+	classFile string,
+	class *gradebook.Class,
+	extras *classExtras,
+	gbFiles []*gradebookFile,
+) (err error) {
+	fh, err := os.CreateTemp(filepath.Dir(out), "."+filepath.Base(out)+"-*")
+	if err != nil {
+		return fmt.Errorf("create temporary file for %q: %w", out, err)
+	}
+	tmpName := fh.Name()
+	if err = fh.Close(); err != nil {
+		return fmt.Errorf("close temporary file %q: %w", tmpName, err)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		if removeErr := os.Remove(tmpName); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			err = errors.Join(err, fmt.Errorf("remove temporary file %q: %w", tmpName, removeErr))
+		}
+	}()
+
+	db, err := sql.Open("sqlite", tmpName)
+	if err != nil {
+		return fmt.Errorf("open database %q: %w", tmpName, err)
+	}
+	err = fillSQLite(db, store, classFile, class, extras, gbFiles)
+	if closeErr := db.Close(); closeErr != nil {
+		err = errors.Join(err, fmt.Errorf("close database %q: %w", tmpName, closeErr))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmpName, out); err != nil {
+		return fmt.Errorf("rename %q to %q: %w", tmpName, out, err)
+	}
+
+	return nil
+}
+
+func fillSQLite(
+	db *sql.DB,
+	store classStore,
+	classFile string,
+	class *gradebook.Class,
+	extras *classExtras,
+	gbFiles []*gradebookFile,
+) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	exec := func(query string, args ...any) {
+		if err != nil {
+			return
+		}
+		if _, err = tx.Exec(query, args...); err != nil {
+			err = fmt.Errorf("%w (query: %s)", err, query)
+		}
+	}
+
+	exec(sqliteSchema)
+	exec("INSERT INTO class (name) VALUES (?)", class.Name)
+	for id, term := range class.TermsByID {
+		exec("INSERT INTO terms (id, start_date, end_date) VALUES (?, ?, ?)", id, term.Start, term.End)
+	}
+	for _, cat := range class.AssignmentCategories {
+		exec(
+			"INSERT INTO categories (id, label, weight) VALUES (?, ?, ?)",
+			cat,
+			class.LabelsByAssignmentCategory[cat],
+			class.WeightsByAssignmentCategory[cat],
+		)
+	}
+	for gbType, cat := range class.CategoriesByAssignmentType {
+		exec("INSERT INTO assignment_types (type, category, extra_credit) VALUES (?, ?, 0)", gbType, cat)
+	}
+	if extras.hasExtraCredit() {
+		for _, gbType := range extras.ExtraCredit.AssignmentTypes {
+			exec("INSERT INTO assignment_types (type, category, extra_credit) VALUES (?, NULL, 1)", gbType)
+		}
+	}
+	for email, s := range class.StudentsByEmail {
+		se := extras.student(email)
+		exec(
+			"INSERT INTO students (email, first_name, last_name, preferred_name, pronouns, section, student_id) "+
+				"VALUES (?, ?, ?, ?, ?, ?, ?)",
+			email,
+			s.FirstName,
+			s.LastName,
+			nullIfEmpty(se.PreferredName),
+			nullIfEmpty(se.Pronouns),
+			nullIfEmpty(se.Section),
+			nullIfEmpty(se.StudentID),
+		)
+	}
+
+	for i, gbf := range sortedByDate(gbFiles) {
+		id := i + 1
+		exec(
+			"INSERT INTO gradebooks (id, file, name, type, date, due_date, rubric) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			id,
+			filepath.Base(gbf.path),
+			gbf.AssignmentName,
+			gbf.AssignmentType,
+			gbf.AssignmentDate,
+			gbf.dueDate(),
+			nullIfEmpty(gbf.Rubric),
+		)
+		for _, ar := range gbf.AssignmentRecords {
+			var grade, score any
+			if g, ok := extras.grade(gbf, ar); ok {
+				grade = g
+			}
+			if sc, ok := extras.score(gbf.category(class), gbf, ar); ok {
+				score = sc
+			}
+			exec(
+				"INSERT INTO records (gradebook_id, email, grade, score, submitted, comment) VALUES (?, ?, ?, ?, ?, ?)",
+				id,
+				ar.Email,
+				grade,
+				score,
+				nullIfEmpty(ar.Submitted),
+				nullIfEmpty(ar.Comment),
+			)
+		}
+	}
+
+	for _, path := range append([]string{classFile}, gbPaths(gbFiles)...) {
+		data, readErr := store.readFile(path)
+		if readErr != nil {
+			return errors.Join(err, fmt.Errorf("read %q: %w", path, readErr))
+		}
+		exec("INSERT INTO files (name, data) VALUES (?, ?)", filepath.Base(path), data)
+	}
+
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func gbPaths(gbFiles []*gradebookFile) []string {
+	paths := make([]string, 0, len(gbFiles))
+	for _, gbf := range sortedByDate(gbFiles) {
+		paths = append(paths, gbf.path)
+	}
+
+	return paths
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// matchSQLite compares the grades in the records table of a database that
+// gradebook-sqlite exported with the grades in gbFiles. Records match by
+// gradebook file name and email. Records for files or students that no longer
+// exist are skipped, as are changes to records scored by rubric.
+func (cmd *cmdEnv) matchSQLite(class *gradebook.Class, gbFiles []*gradebookFile, file string) *gradeChanges {
+	if cmd.noOp() {
+		return nil
+	}
+
+	changes, err := readSQLiteGrades(class, cmd.extras, gbFiles, file)
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return nil
+	}
+
+	return changes
+}
+
+func readSQLiteGrades(
+	class *gradebook.Class,
+	extras *classExtras,
+	gbFiles []*gradebookFile,
+	file string,
+) (changes *gradeChanges, err error) {
+	if _, err = os.Stat(file); err != nil {
+		return nil, fmt.Errorf("open database %q: %w", file, err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(file)+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("open database %q: %w", file, err)
+	}
+	defer func() {
+		if closeErr := db.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close database %q: %w", file, closeErr))
+		}
+	}()
+
+	rows, err := db.Query(
+		"SELECT g.file, r.email, r.grade, r.comment FROM records AS r " +
+			"JOIN gradebooks AS g ON g.id = r.gradebook_id ORDER BY g.date, g.file, r.email",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("read records from %q: %w", file, err)
+	}
+	defer rows.Close()
+
+	byName := make(map[string]*gradebookFile, len(gbFiles))
+	for _, gbf := range gbFiles {
+		byName[filepath.Base(gbf.path)] = gbf
+	}
+	records := make(map[*gradebookFile]map[string]*assignmentRecord, len(gbFiles))
+
+	changes = newGradeChanges()
+	for rows.Next() {
+		var name, email string
+		var grade sql.NullFloat64
+		var comment sql.NullString
+		if err = rows.Scan(&name, &email, &grade, &comment); err != nil {
+			return nil, fmt.Errorf("read records from %q: %w", file, err)
+		}
+
+		gbf := byName[name]
+		switch {
+		case gbf == nil:
+			changes.skip("Skipped records for missing gradebook files", fmt.Sprintf("%s <%s>", name, email))
+
+			continue
+		case class.StudentsByEmail[email] == nil:
+			changes.skip("Skipped records for students not in class", fmt.Sprintf("%s <%s>", name, email))
+
+			continue
+		}
+
+		if records[gbf] == nil {
+			records[gbf] = recordsByEmail(gbf)
+		}
+		ar := records[gbf][email]
+		change := sqliteChange(extras, gbf, ar, email, grade, comment.String)
+		switch {
+		case !change.gradeChanged() && change.comment == nil:
+			continue
+		case change.gradeChanged() && ar != nil && gbf.Rubric != "" && len(ar.ScoresByCriterion) > 0:
+			changes.skip("Skipped records", fmt.Sprintf("%s <%s> (scored by rubric)", name, email))
+
+			continue
+		}
+		changes.add(gbf, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read records from %q: %w", file, err)
+	}
+
+	return changes, nil
+}
+
+// sqliteChange compares a row of the records table with the record ar, which
+// is nil if the student has no record. The change's comment is nil unless the
+// comment differs.
+func sqliteChange(
+	extras *classExtras,
+	gbf *gradebookFile,
+	ar *assignmentRecord,
+	email string,
+	grade sql.NullFloat64,
+	comment string,
+) gradeChange {
+	change := gradeChange{email: email}
+	if grade.Valid {
+		change.grade = &grade.Float64
+	}
+	if ar != nil {
+		if g, ok := extras.grade(gbf, ar); ok {
+			change.old = &g
+		}
+		change.oldComment = ar.Comment
+	}
+	if comment != change.oldComment {
+		change.comment = &comment
+	}
+
+	return change
+}
+
+func sameGrade(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func exportSQLiteFixture(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := writeTrendFixture(t)
+	out := filepath.Join(t.TempDir(), "class.db")
+	exitCode, _, stderr := runPublicCommand(t, GradebookSQLite, []string{"export", "-dir", dir, "-out", out})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	return dir, out
+}
+
+func openSQLiteFixture(t *testing.T, file string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", file)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", file, err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestPublicGradebookSQLiteExport(t *testing.T) {
+	t.Parallel()
+
+	_, out := exportSQLiteFixture(t)
+	db := openSQLiteFixture(t, out)
+
+	counts := map[string]int{
+		"class":            1,
+		"categories":       3,
+		"assignment_types": 3,
+		"students":         2,
+		"gradebooks":       2,
+		"records":          4,
+		"files":            3,
+	}
+	for table, want := range counts {
+		var got int
+		if err := db.QueryRow("SELECT count(*) FROM " + table).Scan(&got); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		if got != want {
+			t.Errorf("rows in %s = %d; want %d", table, got, want)
+		}
+	}
+
+	var below []string
+	rows, err := db.Query("SELECT email FROM grades WHERE category = 'major' AND score < 70 ORDER BY email")
+	if err != nil {
+		t.Fatalf("query grades view: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		below = append(below, email)
+	}
+	if got := strings.Join(below, " "); got != "bob@example.com" {
+		t.Errorf("students below 70 on major work = %q; want %q", got, "bob@example.com")
+	}
+
+	var grade sql.NullFloat64
+	err = db.QueryRow(
+		"SELECT grade FROM records JOIN gradebooks ON gradebooks.id = records.gradebook_id " +
+			"WHERE file = 'quiz-quiz-1-20240319.gradebook' AND email = 'alice@example.com'",
+	).Scan(&grade)
+	if err != nil {
+		t.Fatalf("query unscored record: %v", err)
+	}
+	if grade.Valid {
+		t.Errorf("unscored grade = %v; want NULL", grade.Float64)
+	}
+}
+
+func TestPublicGradebookSQLiteImport(t *testing.T) {
+	t.Parallel()
+
+	dir, out := exportSQLiteFixture(t)
+	db := openSQLiteFixture(t, out)
+	_, err := db.Exec(
+		"UPDATE records SET grade = 95, comment = 'Q&A <after class>' WHERE email = 'alice@example.com' AND gradebook_id = " +
+			"(SELECT id FROM gradebooks WHERE name = 'quiz-1')",
+	)
+	if err != nil {
+		t.Fatalf("update records: %v", err)
+	}
+	if _, err = db.Exec("UPDATE records SET grade = NULL WHERE email = 'bob@example.com' AND grade = 60"); err != nil {
+		t.Fatalf("update records: %v", err)
+	}
+
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookSQLite, []string{"import", "-dir", dir, "-file", out, "-yes"})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "quiz-quiz-1-20240319.gradebook:\n" +
+		"\tAlice Zephyr <alice@example.com>: unscored -> 95, comment \"\" -> \"Q&A <after class>\"\n" +
+		"test-test-1-20240401.gradebook:\n" +
+		"\tBob Young <bob@example.com>: 60 -> unscored\n"
+	if stdout != want {
+		t.Fatalf("stdout = %q; want %q", stdout, want)
+	}
+
+	gbf, err := readGradebookFile(dirStore{}, filepath.Join(dir, "quiz-quiz-1-20240319.gradebook"))
+	if err != nil {
+		t.Fatalf("failed to read imported gradebook file: %v", err)
+	}
+	for _, ar := range gbf.AssignmentRecords {
+		if ar.Email == "alice@example.com" && ar.Comment != "Q&A <after class>" {
+			t.Fatalf("Alice's comment = %q; want it imported", ar.Comment)
+		}
+	}
+
+	exitCode, stdout, stderr = runPublicCommand(t, GradebookSQLite, []string{"import", "-dir", dir, "-file", out, "-yes"})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if stdout != "No grade changes\n" {
+		t.Fatalf("second import stdout = %q; want %q", stdout, "No grade changes\n")
+	}
+}
+
+func TestPublicGradebookSQLiteErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"missing out": {
+			args: []string{"export", "-dir", dir},
+			want: "gradebook-sqlite: -out is required",
+		},
+		"missing file": {
+			args: []string{"import", "-dir", dir},
+			want: "gradebook-sqlite: -file is required",
+		},
+		"nonexistent database": {
+			args: []string{"import", "-dir", dir, "-file", filepath.Join(dir, "missing.db")},
+			want: "gradebook-sqlite: open database",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exitCode, _, stderr := runPublicCommand(t, GradebookSQLite, tc.args)
+			if exitCode != exitFailure {
+				t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+			}
+			if !strings.Contains(stderr, tc.want) {
+				t.Fatalf("stderr = %q; want it to contain %q", stderr, tc.want)
+			}
+		})
+	}
+}
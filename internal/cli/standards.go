@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	minMastery = 1
+	maxMastery = 4
+
+	standardsMostRecent      = "most_recent"
+	standardsHighest         = "highest"
+	standardsDecayingAverage = "decaying_average"
+
+	defaultDecayWeight = 0.65
+)
+
+// standardsCalculation determines how a student's scores on a standard
+// combine into a single mastery level. Method is one of "most_recent",
+// "highest", or "decaying_average" (default: "most_recent"). For a decaying
+// average, each new score counts for DecayWeight of the result, and the
+// previous result counts for the rest (default: 0.65).
+type standardsCalculation struct {
+	Method      string  `json:"method"`
+	DecayWeight float64 `json:"decay_weight"`
+}
+
+// masteryResult represents a student's mastery level on one standard. The
+// result is only valid if the student has at least one score on the standard.
+type masteryResult struct {
+	Value float64
+	Valid bool
+}
+
+// String returns a string representation of a masteryResult.
+func (mr masteryResult) String() string {
+	if mr.Valid {
+		return formatScore(mr.Value)
+	}
+
+	return "No results"
+}
+
+// GradebookStandards prints each student's mastery level on each standard
+// alongside the student's category averages.
+func GradebookStandards(args []string) int {
+	cmd := cmdFrom("gradebook-standards", standardsUsage)
+
+	return runCommand(cmd, args, commandRun[string]{
+		parse:     (*cmdEnv).parseCalculate,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, term string) {
+			cmd.findTerm(class, term)
+			cmd.findSection()
+			cmd.checkHasStandards()
+			gbFiles := cmd.loadGrades(class, term)
+			cmd.printStandards(class, gbFiles)
+		},
+	})
+}
+
+func (ce *classExtras) checkStandards() error {
+	if ce == nil {
+		return nil
+	}
+
+	errs := make([]error, 0, len(ce.LabelsByStandard)+2)
+	for _, std := range slices.Sorted(maps.Keys(ce.LabelsByStandard)) {
+		if std == "" || ce.LabelsByStandard[std] == "" {
+			errs = append(errs, fmt.Errorf("standard %q must have a non-empty name and label", std))
+		}
+	}
+
+	if sc := ce.StandardsCalculation; sc != nil {
+		switch sc.Method {
+		case "", standardsMostRecent, standardsHighest, standardsDecayingAverage:
+		default:
+			errs = append(errs, fmt.Errorf("unknown standards calculation method %q", sc.Method))
+		}
+		if sc.DecayWeight < 0 || sc.DecayWeight > 1 {
+			errs = append(errs, fmt.Errorf("standards decay_weight %v must be between 0 and 1", sc.DecayWeight))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkStandardScores checks that every standard in a record is defined in
+// labels_by_standard and that every score is on the mastery scale.
+func (ce *classExtras) checkStandardScores(ar *assignmentRecord) error {
+	for _, std := range slices.Sorted(maps.Keys(ar.ScoresByStandard)) {
+		if ce == nil || ce.LabelsByStandard[std] == "" {
+			return fmt.Errorf("unknown standard %q for %q", std, ar.Email)
+		}
+
+		score := ar.ScoresByStandard[std]
+		if score < minMastery || score > maxMastery {
+			return fmt.Errorf(
+				"score %v on standard %q for %q is outside %d-%d",
+				score,
+				std,
+				ar.Email,
+				minMastery,
+				maxMastery,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (cmd *cmdEnv) checkHasStandards() {
+	if cmd.noOp() {
+		return
+	}
+
+	if cmd.extras == nil || len(cmd.extras.LabelsByStandard) == 0 {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: class has no labels_by_standard\n", cmd.name)
+	}
+}
+
+// standardsSortedByLabel returns the class's standards sorted by label.
+func (ce *classExtras) standardsSortedByLabel() []string {
+	standards := slices.Collect(maps.Keys(ce.LabelsByStandard))
+	slices.SortFunc(standards, func(stdA, stdB string) int {
+		return cmp.Compare(ce.LabelsByStandard[stdA], ce.LabelsByStandard[stdB])
+	})
+
+	return standards
+}
+
+// standardScores collects each student's scores on each standard in the order
+// in which the assignments took place.
+func standardScores(gbFiles []*gradebookFile) map[string]map[string][]float64 {
+	ordered := slices.Clone(gbFiles)
+	slices.SortStableFunc(ordered, func(gbfA, gbfB *gradebookFile) int {
+		return cmp.Compare(gbfA.AssignmentDate, gbfB.AssignmentDate)
+	})
+
+	scores := make(map[string]map[string][]float64)
+	for _, gbf := range ordered {
+		for _, ar := range gbf.AssignmentRecords {
+			for std, score := range ar.ScoresByStandard {
+				if scores[ar.Email] == nil {
+					scores[ar.Email] = make(map[string][]float64)
+				}
+				scores[ar.Email][std] = append(scores[ar.Email][std], score)
+			}
+		}
+	}
+
+	return scores
+}
+
+// mastery combines scores, which are in chronological order, into a single
+// mastery level using the class's standards calculation.
+func (ce *classExtras) mastery(scores []float64) masteryResult {
+	if len(scores) == 0 {
+		return masteryResult{Valid: false}
+	}
+
+	sc := &standardsCalculation{}
+	if ce != nil && ce.StandardsCalculation != nil {
+		sc = ce.StandardsCalculation
+	}
+
+	switch sc.Method {
+	case standardsHighest:
+		return masteryResult{Value: slices.Max(scores), Valid: true}
+	case standardsDecayingAverage:
+		weight := cmp.Or(sc.DecayWeight, defaultDecayWeight)
+		value := scores[0]
+		for _, score := range scores[1:] {
+			value = value*(1-weight) + score*weight
+		}
+
+		return masteryResult{Value: value, Valid: true}
+	default:
+		return masteryResult{Value: scores[len(scores)-1], Valid: true}
+	}
+}
+
+func (cmd *cmdEnv) printStandards(class *gradebook.Class, gbFiles []*gradebookFile) {
+	if cmd.noOp() {
+		return
+	}
+
+	scores := standardScores(gbFiles)
+	standards := cmd.extras.standardsSortedByLabel()
+
+	for _, email := range cmd.emailsInSection(class) {
+		s := class.StudentsByEmail[email]
+		fmt.Fprintf(cmd.stdout, "%s %s\n", s.FirstName, s.LastName)
+
+		fmt.Fprintf(cmd.stdout, "\tOverall average: %s\n", s.TotalAverage(class.WeightsByAssignmentCategory))
+		for _, cat := range class.AssignmentCategoriesSortedByLabel() {
+			fmt.Fprintf(cmd.stdout, "\t%s: %s\n", class.LabelsByAssignmentCategory[cat], s.Average(cat))
+		}
+
+		fmt.Fprintln(cmd.stdout, "\tStandards:")
+		for _, std := range standards {
+			mastery := cmd.extras.mastery(scores[email][std])
+			fmt.Fprintf(cmd.stdout, "\t\t%s: %s\n", cmd.extras.LabelsByStandard[std], mastery)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const standardsClassJSON = `{
+    "labels_by_standard": {
+        "evidence": "Argues from evidence",
+        "sources": "Cites sources"
+    },
+    "standards_calculation": {
+        "method": "highest"
+    }
+}`
+
+const standardsGradebook1JSON = `{
+    "assignment_category": "major",
+    "assignment_date": "20240301",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 80,
+            "scores_by_standard": {"evidence": 4, "sources": 2}
+        },
+        {
+            "email": "alice@example.com",
+            "grade": 70,
+            "scores_by_standard": {"evidence": 2}
+        }
+    ],
+    "assignment_name": "essay-1",
+    "assignment_type": "test"
+}`
+
+const standardsGradebook2JSON = `{
+    "assignment_category": "major",
+    "assignment_date": "20240401",
+    "assignment_records": [
+        {
+            "email": "bob@example.com",
+            "grade": 90,
+            "scores_by_standard": {"evidence": 3}
+        },
+        {
+            "email": "alice@example.com",
+            "grade": null
+        }
+    ],
+    "assignment_name": "essay-2",
+    "assignment_type": "test"
+}`
+
+func writeStandardsFixture(t *testing.T) string {
+	t.Helper()
+
+	// The second essay sorts first by file name, so the fixture also checks
+	// that scores are replayed by assignment date.
+	return writeFixtureDir(t, standardsClassJSON, map[string]string{
+		"test-a-essay-2-20240401.gradebook": standardsGradebook2JSON,
+		"test-b-essay-1-20240301.gradebook": standardsGradebook1JSON,
+	})
+}
+
+func TestPublicGradebookStandards(t *testing.T) {
+	t.Parallel()
+
+	dir := writeStandardsFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookStandards, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "" +
+		"Bob Young\n" +
+		"\tOverall average: 85\n" +
+		"\tMajor: 85\n" +
+		"\tMinor: No results\n" +
+		"\tParticipation: No results\n" +
+		"\tStandards:\n" +
+		"\t\tArgues from evidence: 4\n" +
+		"\t\tCites sources: 2\n" +
+		"Alice Zephyr\n" +
+		"\tOverall average: 70\n" +
+		"\tMajor: 70\n" +
+		"\tMinor: No results\n" +
+		"\tParticipation: No results\n" +
+		"\tStandards:\n" +
+		"\t\tArgues from evidence: 2\n" +
+		"\t\tCites sources: No results\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%q\ngot:\n%q", want, stdout)
+	}
+}
+
+func TestPublicGradebookStandardsWithoutStandards(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookStandards, []string{"-dir", dir})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if want := "gradebook-standards: class has no labels_by_standard\n"; stderr != want {
+		t.Fatalf("stderr mismatch:\nwant:\n%q\ngot:\n%q", want, stderr)
+	}
+}
+
+func TestPublicGradebookCalcRejectsUnknownStandard(t *testing.T) {
+	t.Parallel()
+
+	dir := writeStandardsFixture(t)
+	gbData := strings.Replace(standardsGradebook1JSON, `"sources": 2`, `"style": 2`, 1)
+	mustWriteFixtureFile(t, filepath.Join(dir, "test-b-essay-1-20240301.gradebook"), gbData)
+
+	exitCode, _, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.Contains(stderr, `unknown standard "style"`) {
+		t.Fatalf("stderr = %q; want unknown standard error", stderr)
+	}
+}
+
+var masteryCases = map[string]struct {
+	calc   *standardsCalculation
+	scores []float64
+	want   string
+}{
+	"no scores": {
+		calc: &standardsCalculation{Method: standardsMostRecent},
+		want: "No results",
+	},
+	"default is most recent": {
+		scores: []float64{4, 2, 3},
+		want:   "3",
+	},
+	"highest": {
+		calc:   &standardsCalculation{Method: standardsHighest},
+		scores: []float64{2, 4, 3},
+		want:   "4",
+	},
+	"decaying average with default weight": {
+		calc:   &standardsCalculation{Method: standardsDecayingAverage},
+		scores: []float64{2, 4},
+		want:   "3.3",
+	},
+	"decaying average with custom weight": {
+		calc:   &standardsCalculation{Method: standardsDecayingAverage, DecayWeight: 0.5},
+		scores: []float64{1, 3, 4},
+		want:   "3",
+	},
+}
+
+func TestMastery(t *testing.T) {
+	t.Parallel()
+
+	for testName, tt := range masteryCases {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			extras := &classExtras{StandardsCalculation: tt.calc}
+			if got := extras.mastery(tt.scores).String(); got != tt.want {
+				t.Fatalf("mastery(%v) = %s; want %s", tt.scores, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/telemachus/gradebook"
+)
+
+const (
+	storeDir     = "dir"
+	storeArchive = "archive"
+	storeZip     = "zip"
+	storeSQLite  = "sqlite"
+)
+
+// classStore holds a class's files: its class file and its gradebook files.
+// Commands that only read a class read through a classStore, so they work the
+// same on a directory, a zip archive, or a database from gradebook-sqlite.
+// Commands that change files work only on a directory.
+type classStore interface {
+	// readFile returns the contents of the named file.
+	readFile(name string) ([]byte, error)
+	// gradebookFiles returns the names of all gradebook files in lexical
+	// order.
+	gradebookFiles() ([]string, error)
+}
+
+// readClass reads and unmarshals the class file from store. It also returns
+// the file's contents, which hold the class's extras.
+func readClass(store classStore, classFile string) (*gradebook.Class, []byte, error) {
+	data, err := store.readFile(classFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read class file %q: %w", classFile, err)
+	}
+
+	var class gradebook.Class
+	if err = json.Unmarshal(data, &class); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal class file %q: %w", classFile, err)
+	}
+
+	return &class, data, nil
+}
+
+// dirStore is a directory on disk. Its names are file paths.
+type dirStore struct {
+	dir string
+}
+
+func (ds dirStore) readFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Clean(name))
+}
+
+func (ds dirStore) gradebookFiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Clean(ds.dir))
+	if err != nil {
+		return nil, fmt.Errorf("read directory %q: %w", ds.dir, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == gradebookSuffix {
+			names = append(names, filepath.Join(ds.dir, entry.Name()))
+		}
+	}
+
+	return names, nil
+}
+
+// fsStore is any fs.FS, such as a zip archive. Its names are slash-separated
+// paths within the file system.
+type fsStore struct {
+	fsys fs.FS
+}
+
+func (fss fsStore) readFile(name string) ([]byte, error) {
+	return fs.ReadFile(fss.fsys, name)
+}
+
+func (fss fsStore) gradebookFiles() ([]string, error) {
+	entries, err := fs.ReadDir(fss.fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && path.Ext(entry.Name()) == gradebookSuffix {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// openZipStore opens a zip archive of a class directory. The class's files
+// may be at the top of the archive or inside a single top-level folder, as
+// when a folder is zipped.
+func openZipStore(archive, classFile string) (classStore, error) {
+	data, err := os.ReadFile(filepath.Clean(archive))
+	if err != nil {
+		return nil, fmt.Errorf("read zip archive %q: %w", archive, err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open zip archive %q: %w", archive, err)
+	}
+
+	if _, err = fs.Stat(zr, classFile); err == nil {
+		return fsStore{fsys: zr}, nil
+	}
+
+	entries, err := fs.ReadDir(zr, ".")
+	if err == nil && len(entries) == 1 && entries[0].IsDir() {
+		sub, err := fs.Sub(zr, entries[0].Name())
+		if err == nil {
+			return fsStore{fsys: sub}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no %q in zip archive %q", classFile, archive)
+}
+
+// memStore holds a class's files in memory, keyed by name.
+type memStore struct {
+	files map[string][]byte
+}
+
+func (ms memStore) readFile(name string) ([]byte, error) {
+	data, ok := ms.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return data, nil
+}
+
+func (ms memStore) gradebookFiles() ([]string, error) {
+	names := slices.Sorted(maps.Keys(ms.files))
+
+	return slices.DeleteFunc(names, func(name string) bool {
+		return !strings.HasSuffix(name, gradebookSuffix)
+	}), nil
+}
+
+// openSQLiteStore reads the files table of a database that gradebook-sqlite
+// exported.
+func openSQLiteStore(file string) (store classStore, err error) {
+	if _, err = os.Stat(file); err != nil {
+		return nil, fmt.Errorf("open database %q: %w", file, err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(file)+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("open database %q: %w", file, err)
+	}
+	defer func() {
+		if closeErr := db.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close database %q: %w", file, closeErr))
+		}
+	}()
+
+	rows, err := db.Query("SELECT name, data FROM files")
+	if err != nil {
+		return nil, fmt.Errorf("read files from %q: %w", file, err)
+	}
+	defer rows.Close()
+
+	ms := memStore{files: make(map[string][]byte)}
+	for rows.Next() {
+		var name string
+		var data []byte
+		if err = rows.Scan(&name, &data); err != nil {
+			return nil, fmt.Errorf("read files from %q: %w", file, err)
+		}
+		ms.files[name] = data
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read files from %q: %w", file, err)
+	}
+
+	return ms, nil
+}
+
+// openStore opens the store that -store names at -dir. Without -store, the
+// class is in the directory -dir, or in the archive -dir if -dir is a file.
+func (cmd *cmdEnv) openStore() {
+	if cmd.noOp() {
+		return
+	}
+
+	var err error
+	switch cmd.storeKind {
+	case "", storeDir:
+		cmd.store = dirStore{dir: cmd.directory}
+	case storeArchive:
+		cmd.store, err = openArchiveStore(cmd.directory)
+	case storeZip:
+		cmd.store, err = openZipStore(cmd.directory, cmd.classFile)
+	case storeSQLite:
+		cmd.store, err = openSQLiteStore(cmd.directory)
+	default:
+		err = fmt.Errorf("invalid argument for -store: %q", cmd.storeKind)
+	}
+
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return
+	}
+	cmd.store = sealedStore{classStore: cmd.store, keys: cmd.keys}
+}
+
+// classStore returns the store that openStore opened or, if none was opened,
+// the directory -dir.
+func (cmd *cmdEnv) classStore() classStore {
+	if cmd.store == nil {
+		return sealedStore{classStore: dirStore{dir: cmd.directory}, keys: cmd.keys}
+	}
+
+	return cmd.store
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"archive/zip"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// zipFixture zips the files in dir into a new archive, under prefix if it is
+// not empty.
+func zipFixture(t *testing.T, dir, prefix string) string {
+	t.Helper()
+
+	archive := filepath.Join(t.TempDir(), "class.zip")
+	fh, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", archive, err)
+	}
+	zw := zip.NewWriter(fh)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", dir, err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", entry.Name(), err)
+		}
+		w, err := zw.Create(path.Join(prefix, entry.Name()))
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", entry.Name(), err)
+		}
+		if _, err = w.Write(data); err != nil {
+			t.Fatalf("failed to write %s: %v", entry.Name(), err)
+		}
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatalf("failed to close zip writer: %v", err)
+	}
+	if err = fh.Close(); err != nil {
+		t.Fatalf("failed to close %s: %v", archive, err)
+	}
+
+	return archive
+}
+
+func TestPublicGradebookCalcStores(t *testing.T) {
+	t.Parallel()
+
+	dir, db := exportSQLiteFixture(t)
+	exitCode, want, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", dir})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	testCases := map[string][]string{
+		"zip":           {"-store", "zip", "-dir", zipFixture(t, dir, "")},
+		"zipped folder": {"-store", "zip", "-dir", zipFixture(t, dir, "fall")},
+		"sqlite":        {"-store", "sqlite", "-dir", db},
+	}
+
+	for msg, args := range testCases {
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+
+			exitCode, got, stderr := runPublicCommand(t, GradebookCalc, args)
+			if exitCode != exitSuccess {
+				t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+			}
+			if got != want {
+				t.Errorf("stdout = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPublicGradebookNamesZipStore(t *testing.T) {
+	t.Parallel()
+
+	archive := zipFixture(t, writeExtrasFixture(t), "")
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookNames, []string{
+		"-store", "zip", "-dir", archive, "-section", "A",
+	})
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if want := "Carol Xu\nAlice Zephyr\n"; stdout != want {
+		t.Errorf("stdout = %q; want %q", stdout, want)
+	}
+}
+
+func TestFSStore(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	fsys := fstest.MapFS{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", dir, err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", entry.Name(), err)
+		}
+		fsys[entry.Name()] = &fstest.MapFile{Data: data}
+	}
+	fsys["notes/todo.gradebook"] = &fstest.MapFile{Data: []byte("not a gradebook")}
+
+	store := fsStore{fsys: fsys}
+	class, _, err := readClass(store, suiteClassFile)
+	if err != nil {
+		t.Fatalf("readClass() error = %v", err)
+	}
+	gbFiles, err := loadGradebookFiles(store, class, nil, nil)
+	if err != nil {
+		t.Fatalf("loadGradebookFiles() error = %v", err)
+	}
+
+	var names []string
+	for _, gbf := range gbFiles {
+		names = append(names, gbf.path)
+	}
+	want := "quiz-quiz-1-20240319.gradebook test-test-1-20240401.gradebook"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("gradebook files = %q; want %q", got, want)
+	}
+}
+
+func TestPublicGradebookStoreErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	notZip := filepath.Join(dir, "class.json")
+	emptyZip := zipFixture(t, t.TempDir(), "")
+
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"invalid store": {
+			args: []string{"-store", "tarball", "-dir", dir},
+			want: `invalid argument for -store: "tarball"`,
+		},
+		"missing archive": {
+			args: []string{"-store", "zip", "-dir", filepath.Join(dir, "missing.zip")},
+			want: "read zip archive",
+		},
+		"not a zip archive": {
+			args: []string{"-store", "zip", "-dir", notZip},
+			want: "open zip archive",
+		},
+		"no class file in archive": {
+			args: []string{"-store", "zip", "-dir", emptyZip},
+			want: `no "class.json" in zip archive`,
+		},
+		"missing database": {
+			args: []string{"-store", "sqlite", "-dir", filepath.Join(dir, "missing.db")},
+			want: "open database",
+		},
+	}
+
+	for msg, tc := range testCases {
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+
+			exitCode, _, stderr := runPublicCommand(t, GradebookCalc, tc.args)
+			if exitCode != exitFailure {
+				t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+			}
+			if !strings.Contains(stderr, tc.want) {
+				t.Errorf("stderr = %q; want it to contain %q", stderr, tc.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -70,6 +71,47 @@ func writeSuiteFixture(t *testing.T) string {
 	return dir
 }
 
+// classFixtureWith returns classFixtureJSON with the fields of the JSON object
+// fields added to it. A field that the class already has is replaced in
+// place; a new field goes at the end.
+func classFixtureWith(t *testing.T, fields string) string {
+	t.Helper()
+
+	class := newJSONObject()
+	if err := json.Unmarshal([]byte(classFixtureJSON), class); err != nil {
+		t.Fatalf("failed to unmarshal class fixture: %v", err)
+	}
+	extra := newJSONObject()
+	if err := json.Unmarshal([]byte(fields), extra); err != nil {
+		t.Fatalf("failed to unmarshal class fields: %v", err)
+	}
+	for _, key := range extra.keys {
+		class.set(key, extra.get(key))
+	}
+
+	data, err := marshalJSONFile(class)
+	if err != nil {
+		t.Fatalf("failed to marshal class fixture: %v", err)
+	}
+
+	return string(data)
+}
+
+// writeFixtureDir writes a class with the given extra fields (see
+// classFixtureWith) and the gradebook files in gbFiles, keyed by file name, to
+// a new directory.
+func writeFixtureDir(t *testing.T, fields string, gbFiles map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	mustWriteFixtureFile(t, filepath.Join(dir, suiteClassFile), classFixtureWith(t, fields))
+	for name, data := range gbFiles {
+		mustWriteFixtureFile(t, filepath.Join(dir, name), data)
+	}
+
+	return dir
+}
+
 func mustWriteFixtureFile(t *testing.T, path, data string) {
 	t.Helper()
 
@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/telemachus/gradebook"
+	"github.com/telemachus/gradebook-suite/internal/chart"
+)
+
+// sparkBlocks are the characters of a sparkline from lowest to highest.
+// A sparkline always spans 0 to 100, so sparklines for different students
+// and categories can be compared at a glance.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+const sparkNoResult = '·'
+
+// GradebookTrend replays a class's gradebook files in date order and prints
+// each student's running averages after each assignment.
+func GradebookTrend(args []string) int {
+	cmd := cmdFrom("gradebook-trend", trendUsage)
+
+	return runCommand(cmd, args, commandRun[trendCfg]{
+		parse:     (*cmdEnv).parseTrend,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, cfg trendCfg) {
+			cmd.findTerm(class, cfg.term)
+			cmd.findSection()
+			gbFiles := cmd.readGradebooks(class, cfg.term)
+			cmd.findStudent(class, cfg.student)
+			trends := cmd.replayGrades(class, gbFiles)
+			cmd.printTrends(class, trends, cfg)
+		},
+	})
+}
+
+type trendCfg struct {
+	term    string
+	format  string
+	student string
+}
+
+func (cmd *cmdEnv) parseTrend(args []string) trendCfg {
+	og := cmd.commonOptsGroup(parseOpts{lastFirst: true, section: true, store: true})
+
+	var cfg trendCfg
+	og.String(&cfg.term, "term", "")
+	og.String(&cfg.format, "format", formatText)
+	og.String(&cfg.student, "student", "")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return cfg
+	}
+
+	cmd.checkNameStyle()
+	cmd.checkTrendFormat(cfg)
+
+	return cfg
+}
+
+func (cmd *cmdEnv) checkTrendFormat(cfg trendCfg) {
+	if cmd.noOp() {
+		return
+	}
+
+	switch {
+	case cfg.format != formatText && cfg.format != formatCSV && cfg.format != formatSVG:
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(
+			cmd.stderr,
+			"%s: unknown format %q (want %q, %q, or %q)\n",
+			cmd.name,
+			cfg.format,
+			formatText,
+			formatCSV,
+			formatSVG,
+		)
+	case cfg.format == formatSVG && cfg.student == "":
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -format svg needs -student\n", cmd.name)
+	}
+}
+
+func (cmd *cmdEnv) findStudent(class *gradebook.Class, email string) {
+	if cmd.noOp() || email == "" {
+		return
+	}
+
+	if _, ok := class.StudentsByEmail[email]; !ok {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: no student with email %q\n", cmd.name, email)
+	}
+}
+
+// trendEmails returns the emails of the students to report on: the one
+// student chosen by -student, or else every student in the section.
+func (cmd *cmdEnv) trendEmails(class *gradebook.Class, cfg trendCfg) []string {
+	if cfg.student != "" {
+		return []string{cfg.student}
+	}
+
+	return cmd.emailsInSection(class)
+}
+
+// trendPoint holds a student's running averages just after one assignment.
+type trendPoint struct {
+	gbf        *gradebookFile
+	byCategory map[string]gradebook.AverageResult
+	overall    gradebook.AverageResult
+}
+
+// replayGrades adds the gradebook files one at a time in AssignmentDate order
+// and records each student's averages after each one. Missing work is judged
+// as of each assignment's date. Extra credit assignments do not change any
+// average, so they are left out.
+func (cmd *cmdEnv) replayGrades(class *gradebook.Class, gbFiles []*gradebookFile) map[string][]trendPoint {
+	if cmd.noOp() {
+		return nil
+	}
+
+	ordered := make([]*gradebookFile, 0, len(gbFiles))
+	for _, gbf := range sortedByDate(gbFiles) {
+		if gbf.category(class) != "" {
+			ordered = append(ordered, gbf)
+		}
+	}
+
+	trends := make(map[string][]trendPoint, len(class.StudentsByEmail))
+	for i, gbf := range ordered {
+		addGrades(class, cmd.extras, ordered[:i+1], gbf.AssignmentDate)
+
+		for email, s := range class.StudentsByEmail {
+			point := trendPoint{
+				gbf:        gbf,
+				byCategory: make(map[string]gradebook.AverageResult, len(class.AssignmentCategories)),
+				overall:    s.TotalAverage(class.WeightsByAssignmentCategory),
+			}
+			for _, cat := range class.AssignmentCategories {
+				point.byCategory[cat] = s.Average(cat)
+			}
+			trends[email] = append(trends[email], point)
+		}
+	}
+
+	return trends
+}
+
+func (cmd *cmdEnv) printTrends(class *gradebook.Class, trends map[string][]trendPoint, cfg trendCfg) {
+	if cmd.noOp() {
+		return
+	}
+
+	var err error
+	switch cfg.format {
+	case formatCSV:
+		err = cmd.printTrendsCSV(class, trends, cfg)
+	case formatSVG:
+		err = cmd.printTrendsSVG(class, trends, cfg.student)
+	default:
+		cmd.printTrendsText(class, trends, cfg)
+	}
+
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+	}
+}
+
+func (cmd *cmdEnv) printTrendsText(class *gradebook.Class, trends map[string][]trendPoint, cfg trendCfg) {
+	cats := class.AssignmentCategoriesSortedByLabel()
+	width := utf8.RuneCountInString("Overall")
+	for _, cat := range cats {
+		width = max(width, utf8.RuneCountInString(class.LabelsByAssignmentCategory[cat]))
+	}
+
+	for _, email := range cmd.trendEmails(class, cfg) {
+		points := trends[email]
+		fmt.Fprintln(cmd.stdout, cmd.studentName(class.StudentsByEmail[email], email))
+
+		cmd.printTrendLine("Overall", width, points, func(p trendPoint) gradebook.AverageResult {
+			return p.overall
+		})
+		for _, cat := range cats {
+			cmd.printTrendLine(class.LabelsByAssignmentCategory[cat], width, points, func(p trendPoint) gradebook.AverageResult {
+				return p.byCategory[cat]
+			})
+		}
+	}
+}
+
+// printTrendLine prints one series: a label padded to width, a sparkline, and
+// the first and last results.
+func (cmd *cmdEnv) printTrendLine(
+	label string,
+	width int,
+	points []trendPoint,
+	result func(trendPoint) gradebook.AverageResult,
+) {
+	if len(points) == 0 {
+		fmt.Fprintf(cmd.stdout, "\t%-*s  No results\n", width, label)
+
+		return
+	}
+
+	results := make([]gradebook.AverageResult, 0, len(points))
+	for _, p := range points {
+		results = append(results, result(p))
+	}
+
+	fmt.Fprintf(
+		cmd.stdout,
+		"\t%-*s  %s  %s -> %s\n",
+		width,
+		label,
+		sparkline(results),
+		results[0],
+		results[len(results)-1],
+	)
+}
+
+// sparkline draws one character per result on a fixed scale from 0 to 100.
+func sparkline(results []gradebook.AverageResult) string {
+	var sb strings.Builder
+	for _, ar := range results {
+		if !ar.Valid {
+			sb.WriteRune(sparkNoResult)
+
+			continue
+		}
+
+		i := int(ar.Value / 100 * float64(len(sparkBlocks)-1))
+		sb.WriteRune(sparkBlocks[max(min(i, len(sparkBlocks)-1), 0)])
+	}
+
+	return sb.String()
+}
+
+// printTrendsCSV prints one row per student per assignment. Averages are
+// rounded to two decimal places, and an empty cell means no result.
+func (cmd *cmdEnv) printTrendsCSV(class *gradebook.Class, trends map[string][]trendPoint, cfg trendCfg) error {
+	cats := class.AssignmentCategoriesSortedByLabel()
+
+	header := []string{"Email", "Name", "Date", "Assignment", "Overall"}
+	for _, cat := range cats {
+		header = append(header, class.LabelsByAssignmentCategory[cat])
+	}
+
+	rows := [][]string{header}
+	for _, email := range cmd.trendEmails(class, cfg) {
+		name := cmd.studentName(class.StudentsByEmail[email], email)
+		for _, p := range trends[email] {
+			row := []string{email, name, p.gbf.AssignmentDate, p.gbf.AssignmentName, csvResult(p.overall)}
+			for _, cat := range cats {
+				row = append(row, csvResult(p.byCategory[cat]))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if err := csv.NewWriter(cmd.stdout).WriteAll(rows); err != nil {
+		return fmt.Errorf("write trends: %w", err)
+	}
+
+	return nil
+}
+
+func csvResult(ar gradebook.AverageResult) string {
+	if !ar.Valid {
+		return ""
+	}
+
+	return formatScore(ar.Value)
+}
+
+// printTrendsSVG prints a chart of one student's overall and category
+// averages after each assignment.
+func (cmd *cmdEnv) printTrendsSVG(class *gradebook.Class, trends map[string][]trendPoint, email string) error {
+	points := trends[email]
+	xLabels := make([]string, 0, len(points))
+	for _, p := range points {
+		xLabels = append(xLabels, p.gbf.AssignmentName)
+	}
+
+	cats := class.AssignmentCategoriesSortedByLabel()
+	lines := make([]chart.Line, 0, len(cats)+1)
+	lines = append(lines, trendChartLine("Overall", points, func(p trendPoint) gradebook.AverageResult {
+		return p.overall
+	}))
+	for _, cat := range cats {
+		lines = append(lines, trendChartLine(class.LabelsByAssignmentCategory[cat], points, func(p trendPoint) gradebook.AverageResult {
+			return p.byCategory[cat]
+		}))
+	}
+
+	title := cmd.studentName(class.StudentsByEmail[email], email)
+	if _, err := cmd.stdout.Write(chart.TrendLines(title, xLabels, lines)); err != nil {
+		return fmt.Errorf("write trends: %w", err)
+	}
+
+	return nil
+}
+
+// trendChartLine turns one series of results into a chart line, with NaN for
+// each point where there is no result yet.
+func trendChartLine(label string, points []trendPoint, result func(trendPoint) gradebook.AverageResult) chart.Line {
+	values := make([]float64, 0, len(points))
+	for _, p := range points {
+		ar := result(p)
+		if !ar.Valid {
+			values = append(values, math.NaN())
+
+			continue
+		}
+		values = append(values, ar.Value)
+	}
+
+	return chart.Line{Label: label, Values: values}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/telemachus/gradebook"
+)
+
+func writeTrendFixture(t *testing.T) string {
+	t.Helper()
+
+	dir := writeSuiteFixture(t)
+	mustWriteFixtureFile(t, filepath.Join(dir, "test-test-1-20240401.gradebook"), alertsTestGradebookJSON)
+
+	return dir
+}
+
+func TestPublicGradebookTrendText(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookTrend, []string{"-dir", dir})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "" +
+		"Bob Young\n" +
+		"\tOverall        ▇▅  90 -> 71\n" +
+		"\tMajor          ·▅  No results -> 60\n" +
+		"\tMinor          ▇▇  90 -> 90\n" +
+		"\tParticipation  ··  No results -> No results\n" +
+		"Alice Zephyr\n" +
+		"\tOverall        ·▅  No results -> 70\n" +
+		"\tMajor          ·▅  No results -> 70\n" +
+		"\tMinor          ··  No results -> No results\n" +
+		"\tParticipation  ··  No results -> No results\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%s\ngot:\n%s", want, stdout)
+	}
+}
+
+func TestPublicGradebookTrendCSV(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookTrend, []string{
+		"-dir", dir,
+		"-format", "csv",
+		"-name-style", "last-first",
+	})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+
+	want := "" +
+		"Email,Name,Date,Assignment,Overall,Major,Minor,Participation\n" +
+		"bob@example.com,\"Young, Bob\",20240319,quiz-1,90,,90,\n" +
+		"bob@example.com,\"Young, Bob\",20240401,test-1,71.25,60,90,\n" +
+		"alice@example.com,\"Zephyr, Alice\",20240319,quiz-1,,,,\n" +
+		"alice@example.com,\"Zephyr, Alice\",20240401,test-1,70,70,,\n"
+	if stdout != want {
+		t.Fatalf("stdout mismatch:\nwant:\n%s\ngot:\n%s", want, stdout)
+	}
+}
+
+func TestPublicGradebookTrendUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	exitCode, _, _ := runPublicCommand(t, GradebookTrend, []string{"-dir", dir, "-format", "xml"})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+
+	results := []gradebook.AverageResult{
+		{Valid: false},
+		{Value: 0, Valid: true},
+		{Value: 50, Valid: true},
+		{Value: 100, Valid: true},
+		{Value: 105, Valid: true},
+	}
+	if got, want := sparkline(results), "·▁▄██"; got != want {
+		t.Fatalf("sparkline() = %q; want %q", got, want)
+	}
+}
+
+func TestPublicGradebookTrendSVG(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	exitCode, stdout, stderr := runPublicCommand(t, GradebookTrend, []string{
+		"-dir", dir,
+		"-format", "svg",
+		"-student", "bob@example.com",
+	})
+
+	if exitCode != exitSuccess {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitSuccess, stderr)
+	}
+	if !strings.HasPrefix(stdout, "<svg ") || !strings.Contains(stdout, "<title>Bob Young</title>") {
+		t.Fatalf("stdout = %q; want an SVG chart for Bob", stdout)
+	}
+	if n := strings.Count(stdout, "<polyline "); n != 3 {
+		t.Fatalf("polyline count = %d; want 3 (overall, major, minor)", n)
+	}
+}
+
+func TestPublicGradebookTrendSVGNeedsStudent(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	exitCode, _, stderr := runPublicCommand(t, GradebookTrend, []string{"-dir", dir, "-format", "svg"})
+
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if !strings.Contains(stderr, "needs -student") {
+		t.Fatalf("stderr = %q; want -student error", stderr)
+	}
+}
@@ -0,0 +1,754 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/telemachus/gradebook"
+	"golang.org/x/term"
+)
+
+const (
+	// tuiCellWidth is the width of each assignment column.
+	tuiCellWidth = 10
+	// tuiMaxNameWidth caps the width of the student name column.
+	tuiMaxNameWidth = 24
+	// tuiChromeLines counts the lines that are not student rows: the title,
+	// two header lines, and two lines at the bottom for details and status.
+	tuiChromeLines = 5
+	tuiHelp        = "arrows move  enter edit  x clear  c category  s save  q quit"
+)
+
+// Terminal control sequences.
+const (
+	ansiAltScreen   = "\x1b[?1049h"
+	ansiMainScreen  = "\x1b[?1049l"
+	ansiHideCursor  = "\x1b[?25l"
+	ansiShowCursor  = "\x1b[?25h"
+	ansiHome        = "\x1b[H"
+	ansiClearLine   = "\x1b[K"
+	ansiClearBelow  = "\x1b[J"
+	ansiReverse     = "\x1b[7m"
+	ansiBold        = "\x1b[1m"
+	ansiResetFormat = "\x1b[0m"
+)
+
+// GradebookTUI opens a full-screen editor for the grades in a class.
+func GradebookTUI(args []string) int {
+	cmd := cmdFrom("gradebook-tui", tuiUsage)
+
+	return runCommand(cmd, args, commandRun[string]{
+		parse:     (*cmdEnv).parseTUI,
+		loadClass: true,
+		action: func(cmd *cmdEnv, class *gradebook.Class, term string) {
+			cmd.findTerm(class, term)
+			cmd.findSection()
+			gbFiles := cmd.readGradebooks(class, term)
+			ui := cmd.newTUI(class, gbFiles, term)
+			cmd.runTUI(ui)
+		},
+	})
+}
+
+func (cmd *cmdEnv) parseTUI(args []string) string {
+	og := cmd.commonOptsGroup(parseOpts{lastFirst: true, section: true})
+
+	term := ""
+	og.String(&term, "term", "")
+
+	if err := og.Parse(args); err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+		fmt.Fprintln(cmd.stderr, cmd.usage)
+
+		return ""
+	}
+
+	cmd.checkNameStyle()
+
+	return term
+}
+
+// tuiState is everything the editor shows and every edit made so far. It
+// knows nothing about the terminal, so that keys can be fed to it and its
+// screens checked without one.
+type tuiState struct {
+	class      *gradebook.Class
+	extras     *classExtras
+	changes    *gradeChanges
+	save       func(*gradeChanges) error
+	records    map[*gradebookFile]map[string]*assignmentRecord
+	names      map[string]string
+	title      string
+	today      string
+	status     string
+	gbFiles    []*gradebookFile
+	emails     []string
+	categories []string
+	input      []rune
+	filter     int
+	row        int
+	col        int
+	top        int
+	left       int
+	editing    bool
+	quitArmed  bool
+	quit       bool
+}
+
+func (cmd *cmdEnv) newTUI(class *gradebook.Class, gbFiles []*gradebookFile, term string) *tuiState {
+	if cmd.noOp() {
+		return nil
+	}
+
+	ui := &tuiState{
+		class:      class,
+		extras:     cmd.extras,
+		changes:    newGradeChanges(),
+		records:    make(map[*gradebookFile]map[string]*assignmentRecord, len(gbFiles)),
+		names:      make(map[string]string, len(class.StudentsByEmail)),
+		today:      cmd.today,
+		gbFiles:    sortedByDate(gbFiles),
+		emails:     cmd.emailsInSection(class),
+		categories: class.AssignmentCategoriesSortedByLabel(),
+		status:     tuiHelp,
+	}
+	ui.save = func(changes *gradeChanges) error {
+		return writeGradeChanges(cmd.keys, changes)
+	}
+
+	ui.title = class.Name
+	if term != "" {
+		ui.title += ", term " + term
+	}
+	if cmd.section != "" {
+		ui.title += ", section " + cmd.section
+	}
+
+	for _, email := range ui.emails {
+		ui.names[email] = cmd.studentName(class.StudentsByEmail[email], email)
+	}
+	for _, gbf := range ui.gbFiles {
+		byEmail := make(map[string]*assignmentRecord, len(gbf.AssignmentRecords))
+		for _, ar := range gbf.AssignmentRecords {
+			byEmail[ar.Email] = ar
+		}
+		ui.records[gbf] = byEmail
+	}
+	ui.recompute()
+
+	return ui
+}
+
+// runTUI runs the editor until the user quits. The terminal is put in raw
+// mode on the alternate screen, and both are undone before returning.
+func (cmd *cmdEnv) runTUI(ui *tuiState) {
+	if cmd.noOp() {
+		return
+	}
+
+	in, ok := cmd.stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(in.Fd())) {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: needs a terminal\n", cmd.name)
+
+		return
+	}
+
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+
+		return
+	}
+	fmt.Fprint(cmd.stdout, ansiAltScreen+ansiHideCursor)
+
+	err = ui.run(in, cmd.stdout, func() (int, int) {
+		fh, ok := cmd.stdout.(*os.File)
+		if !ok {
+			return 80, 24
+		}
+		width, height, err := term.GetSize(int(fh.Fd()))
+		if err != nil {
+			return 80, 24
+		}
+
+		return width, height
+	})
+
+	fmt.Fprint(cmd.stdout, ansiShowCursor+ansiMainScreen)
+	_ = term.Restore(int(in.Fd()), oldState)
+
+	if err != nil {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
+	}
+}
+
+// run draws the editor, reads keys, and draws it again after each batch of
+// keys, until the user quits or r runs out.
+func (ui *tuiState) run(r io.Reader, w io.Writer, size func() (int, int)) error {
+	buf := make([]byte, 256)
+	for !ui.quit {
+		width, height := size()
+		if _, err := io.WriteString(w, ui.render(width, height)); err != nil {
+			return fmt.Errorf("draw screen: %w", err)
+		}
+
+		n, err := r.Read(buf)
+		for _, key := range tuiKeys(buf[:n]) {
+			ui.handle(key)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read keys: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Names for the keys that are not plain characters.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyDelete    = "delete"
+	keyEnter     = "enter"
+	keyTab       = "tab"
+	keyBackspace = "backspace"
+	keyEscape    = "esc"
+	keyCtrlC     = "ctrl-c"
+)
+
+var tuiEscapes = map[string]string{
+	"\x1b[A":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1b[C":  keyRight,
+	"\x1b[D":  keyLeft,
+	"\x1bOA":  keyUp,
+	"\x1bOB":  keyDown,
+	"\x1bOC":  keyRight,
+	"\x1bOD":  keyLeft,
+	"\x1b[H":  keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[1~": keyHome,
+	"\x1b[4~": keyEnd,
+	"\x1b[3~": keyDelete,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+}
+
+// tuiKeys splits what the terminal sent into keys. Keys with names come back
+// as those names; other keys come back as the character typed. Escape
+// sequences that the editor does not use are dropped.
+func tuiKeys(data []byte) []string {
+	var keys []string
+	for len(data) > 0 {
+		key, size := tuiKey(data)
+		if key != "" {
+			keys = append(keys, key)
+		}
+		data = data[size:]
+	}
+
+	return keys
+}
+
+// tuiKey returns the first key in data and how many bytes it takes.
+func tuiKey(data []byte) (string, int) {
+	switch data[0] {
+	case 0x1b:
+		return tuiEscapeKey(data)
+	case '\r', '\n':
+		return keyEnter, 1
+	case '\t':
+		return keyTab, 1
+	case 0x7f, 0x08:
+		return keyBackspace, 1
+	case 0x03:
+		return keyCtrlC, 1
+	}
+
+	r, size := utf8.DecodeRune(data)
+
+	return string(r), size
+}
+
+// tuiEscapeKey returns the key for the escape sequence at the start of data.
+// An escape that does not start a sequence is the escape key itself.
+func tuiEscapeKey(data []byte) (string, int) {
+	if len(data) == 1 || (data[1] != '[' && data[1] != 'O') {
+		return keyEscape, 1
+	}
+
+	// A sequence ends with its first byte in the range @ to ~.
+	end := 2
+	for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+		end++
+	}
+	end = min(end+1, len(data))
+
+	return tuiEscapes[string(data[:end])], end
+}
+
+// columns returns the gradebook files that the category filter lets through.
+func (ui *tuiState) columns() []*gradebookFile {
+	if ui.filter == 0 {
+		return ui.gbFiles
+	}
+
+	cat := ui.categories[ui.filter-1]
+	var cols []*gradebookFile
+	for _, gbf := range ui.gbFiles {
+		if gbf.category(ui.class) == cat {
+			cols = append(cols, gbf)
+		}
+	}
+
+	return cols
+}
+
+// averageCategories returns the categories whose averages are shown: every
+// category, or only the one that the filter picks.
+func (ui *tuiState) averageCategories() []string {
+	if ui.filter == 0 {
+		return ui.categories
+	}
+
+	return ui.categories[ui.filter-1 : ui.filter]
+}
+
+func (ui *tuiState) filterLabel() string {
+	if ui.filter == 0 {
+		return "all categories"
+	}
+
+	return ui.class.LabelsByAssignmentCategory[ui.categories[ui.filter-1]]
+}
+
+// recompute works out every student's averages from the grades as edited.
+func (ui *tuiState) recompute() {
+	addGrades(ui.class, ui.extras, ui.gbFiles, ui.today)
+}
+
+// current returns the file and student under the cursor, or nil if there is
+// no cell there.
+func (ui *tuiState) current() (*gradebookFile, string) {
+	cols := ui.columns()
+	if ui.row >= len(ui.emails) || ui.col >= len(cols) {
+		return nil, ""
+	}
+
+	return cols[ui.col], ui.emails[ui.row]
+}
+
+// cellText returns what a cell shows: the grade as entered, or "-" if the
+// record is unscored. Grades that come from a rubric's criteria are shown,
+// but they cannot be edited here.
+func (ui *tuiState) cellText(gbf *gradebookFile, email string) string {
+	ar := ui.records[gbf][email]
+	if ar == nil {
+		return "-"
+	}
+	grade, ok := ui.extras.grade(gbf, ar)
+	if !ok {
+		return "-"
+	}
+
+	return formatScore(grade)
+}
+
+// tuiBindings maps keys to what they do outside of editing a cell.
+var tuiBindings = map[string]func(ui *tuiState){
+	keyUp:       func(ui *tuiState) { ui.row-- },
+	"k":         func(ui *tuiState) { ui.row-- },
+	keyDown:     func(ui *tuiState) { ui.row++ },
+	"j":         func(ui *tuiState) { ui.row++ },
+	keyLeft:     func(ui *tuiState) { ui.col-- },
+	"h":         func(ui *tuiState) { ui.col-- },
+	keyRight:    func(ui *tuiState) { ui.col++ },
+	"l":         func(ui *tuiState) { ui.col++ },
+	keyTab:      func(ui *tuiState) { ui.col++ },
+	keyHome:     func(ui *tuiState) { ui.col = 0 },
+	keyEnd:      func(ui *tuiState) { ui.col = len(ui.columns()) - 1 },
+	keyPageUp:   func(ui *tuiState) { ui.row -= 10 },
+	keyPageDown: func(ui *tuiState) { ui.row += 10 },
+	keyEnter:    func(ui *tuiState) { ui.startEdit(ui.cellText(ui.current())) },
+	"e":         func(ui *tuiState) { ui.startEdit(ui.cellText(ui.current())) },
+	"x":         func(ui *tuiState) { ui.setGrade(nil) },
+	keyDelete:   func(ui *tuiState) { ui.setGrade(nil) },
+	"c":         (*tuiState).nextFilter,
+	"s":         (*tuiState).saveChanges,
+	"q":         (*tuiState).requestQuit,
+	keyCtrlC:    (*tuiState).requestQuit,
+}
+
+func (ui *tuiState) handle(key string) {
+	if ui.editing {
+		ui.handleEdit(key)
+
+		return
+	}
+
+	if key != "q" && key != keyCtrlC {
+		ui.quitArmed = false
+	}
+
+	switch action, ok := tuiBindings[key]; {
+	case ok:
+		action(ui)
+	case isScoreKey(key):
+		// Typing a score starts editing the cell afresh.
+		ui.startEdit("")
+		if ui.editing {
+			ui.handleEdit(key)
+		}
+	}
+	ui.clampCursor()
+}
+
+// isScoreKey reports whether key is a character that can be part of a score.
+func isScoreKey(key string) bool {
+	return len(key) == 1 && (key[0] == '.' || (key[0] >= '0' && key[0] <= '9'))
+}
+
+func (ui *tuiState) nextFilter() {
+	ui.filter = (ui.filter + 1) % (len(ui.categories) + 1)
+	ui.col, ui.left = 0, 0
+	ui.status = "Showing " + ui.filterLabel()
+}
+
+func (ui *tuiState) clampCursor() {
+	ui.row = max(min(ui.row, len(ui.emails)-1), 0)
+	ui.col = max(min(ui.col, len(ui.columns())-1), 0)
+}
+
+func (ui *tuiState) startEdit(text string) {
+	gbf, email := ui.current()
+	if gbf == nil {
+		return
+	}
+	if ui.rubricScored(gbf, email) {
+		return
+	}
+	if text == "-" {
+		text = ""
+	}
+
+	ui.editing = true
+	ui.input = []rune(text)
+	ui.status = "Enter a score (blank for unscored); enter saves it, esc cancels"
+}
+
+func (ui *tuiState) handleEdit(key string) {
+	switch key {
+	case keyEscape, keyCtrlC:
+		ui.editing = false
+		ui.status = tuiHelp
+	case keyBackspace:
+		if len(ui.input) > 0 {
+			ui.input = ui.input[:len(ui.input)-1]
+		}
+	case keyEnter, keyTab:
+		grade, err := parseTUIScore(string(ui.input))
+		if err != nil {
+			ui.status = err.Error()
+
+			return
+		}
+		ui.editing = false
+		ui.setGrade(grade)
+		if key == keyTab {
+			ui.col++
+		} else {
+			ui.row++
+		}
+		ui.clampCursor()
+	default:
+		if isScoreKey(key) {
+			ui.input = append(ui.input, rune(key[0]))
+		}
+	}
+}
+
+// rubricScored reports whether a cell's grade comes from rubric scores, which
+// the editor leaves alone, and says so in the status line.
+func (ui *tuiState) rubricScored(gbf *gradebookFile, email string) bool {
+	ar := ui.records[gbf][email]
+	if ar == nil || gbf.Rubric == "" || len(ar.ScoresByCriterion) == 0 {
+		return false
+	}
+	ui.status = "This grade comes from rubric scores; edit them in " + filepath.Base(gbf.path)
+
+	return true
+}
+
+// parseTUIScore parses a score typed into a cell. A blank score makes the
+// record unscored.
+func parseTUIScore(text string) (*float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+
+	score, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsInf(score, 0) || math.IsNaN(score) || score < 0 {
+		return nil, fmt.Errorf("invalid score %q", text)
+	}
+
+	return &score, nil
+}
+
+// setGrade changes the grade in the cell under the cursor, adding a record
+// for the student if the file lacks one, and recomputes the averages.
+func (ui *tuiState) setGrade(grade *float64) {
+	gbf, email := ui.current()
+	if gbf == nil {
+		return
+	}
+
+	if ui.rubricScored(gbf, email) {
+		return
+	}
+	ar := ui.records[gbf][email]
+	if ar == nil {
+		if grade == nil {
+			return
+		}
+		ar = &assignmentRecord{Email: email}
+		gbf.AssignmentRecords = append(gbf.AssignmentRecords, ar)
+		ui.records[gbf][email] = ar
+	}
+	if (ar.Grade == nil && grade == nil) || (ar.Grade != nil && grade != nil && *ar.Grade == *grade) {
+		return
+	}
+
+	ui.changes.add(gbf, gradeChange{email: email, old: ar.Grade, grade: grade})
+	ar.Grade = grade
+	ui.recompute()
+	ui.status = tuiHelp
+}
+
+func (ui *tuiState) saveChanges() {
+	if ui.changes.empty() {
+		ui.status = "No changes to save"
+
+		return
+	}
+
+	n := len(ui.changes.byFile)
+	if err := ui.save(ui.changes); err != nil {
+		ui.status = "Problem saving: " + err.Error()
+
+		return
+	}
+	ui.changes = newGradeChanges()
+	ui.status = fmt.Sprintf("Saved %d gradebook file(s)", n)
+}
+
+// requestQuit quits, unless there are unsaved changes. Then it warns first,
+// and quits if asked again.
+func (ui *tuiState) requestQuit() {
+	if ui.changes.empty() || ui.quitArmed {
+		ui.quit = true
+
+		return
+	}
+
+	ui.quitArmed = true
+	ui.status = "Unsaved changes: press s to save or q again to quit without saving"
+}
+
+// render draws the whole screen for a terminal of the given size.
+func (ui *tuiState) render(width, height int) string {
+	width = max(width, 20)
+	rows := max(height-tuiChromeLines, 1)
+
+	nameWidth := len("Student")
+	for _, email := range ui.emails {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(ui.studentName(email)))
+	}
+	nameWidth = min(nameWidth, tuiMaxNameWidth)
+
+	avgCats := ui.averageCategories()
+	avgLabels := make([]string, 0, len(avgCats)+1)
+	avgLabels = append(avgLabels, "Overall")
+	for _, cat := range avgCats {
+		avgLabels = append(avgLabels, ui.class.LabelsByAssignmentCategory[cat])
+	}
+	avgWidths := make([]int, len(avgLabels))
+	fixedWidth := nameWidth
+	for i, label := range avgLabels {
+		avgWidths[i] = min(max(utf8.RuneCountInString(label), 7), tuiCellWidth)
+		fixedWidth += avgWidths[i] + 1
+	}
+	fixedWidth += 2
+
+	cols := ui.columns()
+	visible := max((width-fixedWidth)/(tuiCellWidth+1), 1)
+	ui.scrollTo(rows, visible)
+
+	var sb strings.Builder
+	sb.WriteString(ansiHome)
+	line := func(text string) {
+		sb.WriteString(text)
+		sb.WriteString(ansiClearLine + "\r\n")
+	}
+
+	title := fmt.Sprintf("%s: %s", ui.title, ui.filterLabel())
+	if !ui.changes.empty() {
+		title += " [modified]"
+	}
+	line(ansiBold + fitText(title, width) + ansiResetFormat)
+
+	var head1, head2 strings.Builder
+	head1.WriteString(alignLeft("Student", nameWidth))
+	head2.WriteString(alignLeft("", nameWidth))
+	for i, label := range avgLabels {
+		head1.WriteString(" " + alignRight(label, avgWidths[i]))
+		head2.WriteString(" " + alignLeft("", avgWidths[i]))
+	}
+	head1.WriteString(" |")
+	head2.WriteString(" |")
+	for _, gbf := range cols[ui.left:min(ui.left+visible, len(cols))] {
+		head1.WriteString(" " + alignRight(gbf.AssignmentName, tuiCellWidth))
+		head2.WriteString(" " + alignRight(tuiDate(gbf.AssignmentDate), tuiCellWidth))
+	}
+	line(head1.String())
+	line(head2.String())
+
+	for r := ui.top; r < min(ui.top+rows, len(ui.emails)); r++ {
+		email := ui.emails[r]
+		s := ui.class.StudentsByEmail[email]
+
+		var row strings.Builder
+		row.WriteString(alignLeft(ui.studentName(email), nameWidth))
+		row.WriteString(" " + alignRight(tuiAverage(s.TotalAverage(ui.class.WeightsByAssignmentCategory)), avgWidths[0]))
+		for i, cat := range avgCats {
+			row.WriteString(" " + alignRight(tuiAverage(s.Average(cat)), avgWidths[i+1]))
+		}
+		row.WriteString(" |")
+		for c := ui.left; c < min(ui.left+visible, len(cols)); c++ {
+			text := ui.cellText(cols[c], email)
+			if r == ui.row && c == ui.col && ui.editing {
+				text = string(ui.input) + "_"
+			}
+			cell := alignRight(text, tuiCellWidth)
+			if r == ui.row && c == ui.col {
+				cell = ansiReverse + cell + ansiResetFormat
+			}
+			row.WriteString(" " + cell)
+		}
+		line(row.String())
+	}
+	if len(ui.emails) == 0 {
+		line("No students")
+	}
+	sb.WriteString(ansiClearBelow)
+
+	// The details and status lines sit at the bottom of the screen.
+	fmt.Fprintf(&sb, "\x1b[%d;1H", max(height-1, 1))
+	line(fitText(ui.details(), width))
+	sb.WriteString(fitText(ui.status, width) + ansiClearLine)
+
+	return sb.String()
+}
+
+// scrollTo moves the visible part of the grid so that the cursor is on screen.
+func (ui *tuiState) scrollTo(rows, cols int) {
+	if ui.row < ui.top {
+		ui.top = ui.row
+	}
+	if ui.row >= ui.top+rows {
+		ui.top = ui.row - rows + 1
+	}
+	if ui.col < ui.left {
+		ui.left = ui.col
+	}
+	if ui.col >= ui.left+cols {
+		ui.left = ui.col - cols + 1
+	}
+}
+
+// details describes the cell under the cursor.
+func (ui *tuiState) details() string {
+	gbf, email := ui.current()
+	if gbf == nil {
+		return "No assignments for " + ui.filterLabel()
+	}
+
+	label := ui.class.LabelsByAssignmentCategory[gbf.category(ui.class)]
+	if label == "" {
+		label = "extra credit"
+	}
+
+	return fmt.Sprintf(
+		"%s <%s>: %s, %s (%s), %s",
+		ui.studentName(email),
+		email,
+		gbf.AssignmentName,
+		tuiDate(gbf.AssignmentDate),
+		label,
+		filepath.Base(gbf.path),
+	)
+}
+
+func (ui *tuiState) studentName(email string) string {
+	return ui.names[email]
+}
+
+func tuiAverage(avg gradebook.AverageResult) string {
+	if !avg.Valid {
+		return "-"
+	}
+
+	return avg.String()
+}
+
+// tuiDate formats a YYYYMMDD date as YYYY-MM-DD.
+func tuiDate(date string) string {
+	if len(date) != len(dateLayout) {
+		return date
+	}
+
+	return date[:4] + "-" + date[4:6] + "-" + date[6:]
+}
+
+// fitText cuts text to at most width characters.
+func fitText(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+
+	return string([]rune(text)[:width])
+}
+
+// alignLeft cuts or pads text to exactly width characters, aligned left.
+func alignLeft(text string, width int) string {
+	text = fitText(text, width)
+
+	return text + strings.Repeat(" ", width-utf8.RuneCountInString(text))
+}
+
+// alignRight cuts or pads text to exactly width characters, aligned right.
+func alignRight(text string, width int) string {
+	text = fitText(text, width)
+
+	return strings.Repeat(" ", width-utf8.RuneCountInString(text)) + text
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+func tuiFixture(t *testing.T, dir string, args ...string) *tuiState {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	cmd := cmdFromWithWriters("gradebook-tui", tuiUsage, &stdout, &stderr)
+	term := cmd.parseTUI(append([]string{"-dir", dir}, args...))
+	cmd.resolvePaths()
+	class := cmd.unmarshalClass()
+	ui := cmd.newTUI(class, cmd.readGradebooks(class, term), term)
+	if cmd.exitValue != exitSuccess {
+		t.Fatalf("exitValue = %d; want %d (stderr: %q)", cmd.exitValue, exitSuccess, stderr.String())
+	}
+
+	return ui
+}
+
+// tuiRow returns the fields of the grid row for a student, without any
+// terminal formatting.
+func tuiRow(t *testing.T, screen, name string) []string {
+	t.Helper()
+
+	for line := range strings.SplitSeq(ansiEscapeRegex.ReplaceAllString(screen, ""), "\r\n") {
+		if strings.HasPrefix(line, name) {
+			return strings.Fields(strings.TrimPrefix(line, name))
+		}
+	}
+	t.Fatalf("no row for %q in screen %q", name, screen)
+
+	return nil
+}
+
+func runTUIKeys(t *testing.T, ui *tuiState, keys string) {
+	t.Helper()
+
+	err := ui.run(strings.NewReader(keys), &bytes.Buffer{}, func() (int, int) { return 100, 20 })
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+}
+
+func TestTUIEditAndSave(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	ui := tuiFixture(t, dir)
+
+	screen := ui.render(100, 20)
+	if got, want := tuiRow(t, screen, "Bob Young"), []string{"71", "60", "90", "-", "|", "90", "60"}; !slices.Equal(got, want) {
+		t.Errorf("Bob's row = %q; want %q", got, want)
+	}
+
+	// Bob's quiz becomes 75, the cursor moves down, and Alice's quiz
+	// becomes 100. Then only major grades are shown, and the changes saved.
+	runTUIKeys(t, ui, "75\r100\rcsq")
+	if !ui.quit {
+		t.Error("q did not quit after saving")
+	}
+	if want := "Saved 1 gradebook file(s)"; ui.status != want {
+		t.Errorf("status = %q; want %q", ui.status, want)
+	}
+
+	screen = ui.render(100, 20)
+	if got, want := tuiRow(t, screen, "Bob Young"), []string{"66", "60", "|", "60"}; !slices.Equal(got, want) {
+		t.Errorf("Bob's row = %q; want %q", got, want)
+	}
+	if got, want := tuiRow(t, screen, "Alice Zephyr"), []string{"81", "70", "|", "70"}; !slices.Equal(got, want) {
+		t.Errorf("Alice's row = %q; want %q", got, want)
+	}
+
+	gbf, err := readGradebookFile(dirStore{}, filepath.Join(dir, "quiz-quiz-1-20240319.gradebook"))
+	if err != nil {
+		t.Fatalf("failed to read saved gradebook file: %v", err)
+	}
+	got := make(map[string]float64)
+	for _, ar := range gbf.AssignmentRecords {
+		if ar.Grade != nil {
+			got[ar.Email] = *ar.Grade
+		}
+	}
+	if got["bob@example.com"] != 75 || got["alice@example.com"] != 100 || len(got) != 2 {
+		t.Errorf("saved grades = %v; want bob 75 and alice 100", got)
+	}
+}
+
+func TestTUINameStyle(t *testing.T) {
+	t.Parallel()
+
+	ui := tuiFixture(t, writeTrendFixture(t), "-name-style", "last-first")
+	if got, want := tuiRow(t, ui.render(100, 20), "Young, Bob"), []string{"71", "60", "90", "-", "|", "90", "60"}; !slices.Equal(got, want) {
+		t.Errorf("Bob's row = %q; want %q", got, want)
+	}
+}
+
+func TestTUIQuitWithUnsavedChanges(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTrendFixture(t)
+	gbPath := filepath.Join(dir, "quiz-quiz-1-20240319.gradebook")
+	ui := tuiFixture(t, dir)
+
+	runTUIKeys(t, ui, "x")
+	if ui.quit {
+		t.Fatal("quit without being asked")
+	}
+	runTUIKeys(t, ui, "q")
+	if ui.quit {
+		t.Fatal("the first q quit with unsaved changes")
+	}
+	if !strings.HasPrefix(ui.status, "Unsaved changes") {
+		t.Errorf("status = %q; want a warning about unsaved changes", ui.status)
+	}
+	runTUIKeys(t, ui, "q")
+	if !ui.quit {
+		t.Fatal("the second q did not quit")
+	}
+
+	data, err := os.ReadFile(gbPath)
+	if err != nil {
+		t.Fatalf("failed to read gradebook file: %v", err)
+	}
+	if string(data) != gradebookFixtureJSON {
+		t.Errorf("gradebook file changed without saving: %q", data)
+	}
+}
+
+func TestTUIRejectsInvalidScores(t *testing.T) {
+	t.Parallel()
+
+	ui := tuiFixture(t, writeSuiteFixture(t))
+
+	runTUIKeys(t, ui, "1.2.3\r")
+	if !ui.editing {
+		t.Fatal("an invalid score ended editing")
+	}
+	if want := `invalid score "1.2.3"`; ui.status != want {
+		t.Errorf("status = %q; want %q", ui.status, want)
+	}
+
+	runTUIKeys(t, ui, "\x1b")
+	if ui.editing || !ui.changes.empty() {
+		t.Errorf("esc did not cancel the edit (editing: %t)", ui.editing)
+	}
+}
+
+func TestTUIKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		data string
+		want []string
+	}{
+		"characters": {data: "7.5é", want: []string{"7", ".", "5", "é"}},
+		"arrows":     {data: "\x1b[A\x1b[B\x1bOC\x1b[D", want: []string{keyUp, keyDown, keyRight, keyLeft}},
+		"controls":   {data: "\r\t\x7f\x03", want: []string{keyEnter, keyTab, keyBackspace, keyCtrlC}},
+		"tilde keys": {data: "\x1b[3~\x1b[5~\x1b[6~", want: []string{keyDelete, keyPageUp, keyPageDown}},
+		"escape":     {data: "\x1b", want: []string{keyEscape}},
+		"unknown":    {data: "\x1b[15~q", want: []string{"q"}},
+	}
+
+	for msg, tc := range testCases {
+		t.Run(msg, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tuiKeys([]byte(tc.data)); !slices.Equal(got, tc.want) {
+				t.Errorf("tuiKeys(%q) = %q; want %q", tc.data, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGradebookTUINeedsTerminal(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := cmdFromWithWriters("gradebook-tui", tuiUsage, &stdout, &stderr)
+	cmd.stdin = strings.NewReader("q")
+	cmd.runTUI(&tuiState{})
+
+	if cmd.exitValue != exitFailure {
+		t.Fatalf("exitValue = %d; want %d", cmd.exitValue, exitFailure)
+	}
+	if want := "gradebook-tui: needs a terminal\n"; stderr.String() != want {
+		t.Errorf("stderr = %q; want %q", stderr.String(), want)
+	}
+}
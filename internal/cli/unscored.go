@@ -15,14 +15,15 @@ func GradebookUnscored(args []string) int {
 		loadClass: true,
 		action: func(cmd *cmdEnv, class *gradebook.Class, term string) {
 			cmd.findTerm(class, term)
-			cmd.loadUnscored(class, term)
-			cmd.printUnscored(class)
+			cmd.findSection()
+			missingByEmail := cmd.loadUnscored(class, term)
+			cmd.printUnscored(class, missingByEmail)
 		},
 	})
 }
 
 func (cmd *cmdEnv) parseUnscored(args []string) string {
-	og := cmd.commonOptsGroup(parseOpts{})
+	og := cmd.commonOptsGroup(parseOpts{section: true, store: true, watch: true})
 
 	term := ""
 	og.String(&term, "term", "")
@@ -38,25 +39,27 @@ func (cmd *cmdEnv) parseUnscored(args []string) string {
 	return term
 }
 
-func (cmd *cmdEnv) loadUnscored(class *gradebook.Class, term string) {
+func (cmd *cmdEnv) loadUnscored(class *gradebook.Class, term string) map[string]map[string]int {
+	gbFiles := cmd.readGradebooks(class, term)
 	if cmd.noOp() {
-		return
+		return nil
 	}
 
-	err := class.LoadUnscored(cmd.directory, class.TermsByID[term])
-	if err != nil {
-		cmd.exitValue = exitFailure
-		fmt.Fprintf(cmd.stderr, "%s: %s\n", cmd.name, err)
-	}
+	addUnscored(class, cmd.extras, gbFiles)
+
+	return countMissing(class, cmd.extras, gbFiles, cmd.today)
 }
 
-func (cmd *cmdEnv) printUnscored(class *gradebook.Class) {
+// printUnscored prints each student's unscored records by category. In
+// a category whose missing policy counts unscored records as zero, the count
+// is split into records that are missing and records that are not yet due.
+func (cmd *cmdEnv) printUnscored(class *gradebook.Class, missingByEmail map[string]map[string]int) {
 	if cmd.noOp() {
 		return
 	}
 
-	students := class.StudentsSortedByName()
-	for _, s := range students {
+	for _, email := range cmd.emailsInSection(class) {
+		s := class.StudentsByEmail[email]
 		fmt.Fprintf(cmd.stdout, "%s %s:\n", s.FirstName, s.LastName)
 
 		for _, cat := range class.AssignmentCategoriesSortedByLabel() {
@@ -66,7 +69,22 @@ func (cmd *cmdEnv) printUnscored(class *gradebook.Class) {
 			if n == 1 {
 				word = "assignment"
 			}
-			fmt.Fprintf(cmd.stdout, "\t%s: %d unscored %s\n", label, n, word)
+			if n == 0 || !cmd.extras.countsMissing(cat) {
+				fmt.Fprintf(cmd.stdout, "\t%s: %d unscored %s\n", label, n, word)
+
+				continue
+			}
+
+			missing := missingByEmail[email][cat]
+			fmt.Fprintf(
+				cmd.stdout,
+				"\t%s: %d unscored %s (%d missing, %d not yet due)\n",
+				label,
+				n,
+				word,
+				missing,
+				n-missing,
+			)
 		}
 	}
 }
@@ -1,71 +1,616 @@
 package cli
 
 var (
-	calcUsage = `usage: gradebook-calc [-class CLASS -dir DIR -term TERM] [-help -version]
+	alertsUsage = `usage: gradebook-alerts [-class CLASS -dir DIR -section SECTION -store STORE -term TERM] [thresholds] [-format FORMAT] [-help -version]
+
+List students who may be in trouble
+
+A student is flagged if the student's overall average is below a threshold,
+if the average has dropped by more than a number of points since a date, or
+if the student has more than a number of unscored assignments. Thresholds
+that are not set by flags come from the class's alerts, and thresholds set
+in neither place are not checked.
+
+The json and mail-merge formats also give each student's latest comment, from
+the most recent assignment with one.
+
+options:
+    -class CLASS        Class file to use (default: ./class.json)
+    -dir DIR            Directory for gradebook and class.json files (default: ".")
+    -format FORMAT      Output format: "text", "json", or "mail-merge" (CSV)
+                        (default: "text")
+    -name-style STYLE   Name style: "legal", "preferred", or "last-first"
+                        (default: "legal")
+    -section SECTION    Limit output to students in a given SECTION
+    -store STORE        Read -dir as a "dir", an "archive" from gradebook-archive,
+                        a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                        (default: "dir", or "archive" if -dir is a file)
+    -term TERM          Limit calculation to grades in a given TERM
+
+thresholds:
+    -below N            Flag overall averages below N (alerts: below_average)
+    -drop N             Flag averages that dropped more than N points since
+                        -since (alerts: drop_points)
+    -since DATE         YYYYMMDD date to measure drops from (default: today
+                        minus alerts drop_days)
+    -max-unscored N     Flag more than N unscored assignments
+                        (alerts: max_unscored)
+
+general:
+    -help               Print this message
+    -version            Print version`
+
+	anonymizeUsage = `usage: gradebook-anonymize -out DIR -secret FILE [-class CLASS -dir DIR -key FILE -store STORE] [-help -version]
+
+Write a copy of a class with every student replaced by a pseudonym
+
+Each student's email becomes a pseudonym such as
+"student-3f9a1c2b7d@example.invalid" in class.json and in every gradebook
+file, and the student's name becomes "Student 3F9A1C2B7D". A pseudonym is
+keyed by the secret, so the same secret always gives the same pseudonyms.
+Student IDs, preferred names, pronouns, and record comments are dropped;
+sections and grades are kept. The copy is never encrypted.
+
+With -key, a CSV that maps each pseudonym back to the student's email and
+name is written as well, readable only by you. Keep it, and the secret, to
+yourself.
+
+required flags:
+    -out DIR        Directory to write the anonymized class to
+    -secret FILE    File that holds the secret that keys the pseudonyms
+
+options:
+    -class CLASS    Class file to use (default: ./class.json)
+    -dir DIR        Directory for gradebook and class.json files (default: ".")
+    -key FILE       Write the key that maps pseudonyms back to students
+    -store STORE    Read -dir as a "dir", an "archive" from gradebook-archive,
+                    a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                    (default: "dir", or "archive" if -dir is a file)
+
+general:
+    -help           Print this message
+    -version        Print version`
+
+	archiveUsage = `usage: gradebook-archive -term TERM -out FILE [-class CLASS -dir DIR] [-help -version]
+
+Bundle a term into a single archive
+
+The archive is a zip file that holds class.json, every gradebook file in the
+term, the students' final grades (final-grades.csv), and a manifest
+(manifest.json) that lists each file with its SHA-256 checksum. An existing
+file is never replaced.
+
+Read-only commands such as gradebook-calc, gradebook-names, gradebook-emails,
+and gradebook-unscored accept the archive in place of a directory (e.g.,
+"gradebook-calc -dir fall.zip"). They refuse an archive whose files do not
+match its manifest.
+
+required flags:
+    -out FILE      Archive to create
+    -term TERM     Term to archive
+
+options:
+    -class CLASS   Class file to use (default: ./class.json)
+    -dir DIR       Directory for gradebook and class.json files (default: ".")
+
+general:
+    -help          Print this message
+    -version       Print version`
+
+	calcUsage = `usage: gradebook-calc [-class CLASS -dir DIR -section SECTION -store STORE -term TERM] [-watch] [-help -version]
 
 Calculate and print the grades for a class
 
+If the class defines extra_credit, each student's bonus points are shown
+separately from the overall average, followed by the overall average with the
+bonus added. If a missing policy counts unscored records as zero, the number
+of missing records is shown after each category average.
+
 options:
-    -class CLASS  Class file to use (default: ./class.json)
-    -dir DIR      Directory for gradebook and class.json files (default: ".")
-    -term TERM    Limit calculation to grades in a given TERM
+    -class CLASS      Class file to use (default: ./class.json)
+    -dir DIR          Directory for gradebook and class.json files (default: ".")
+    -section SECTION  Limit output to students in a given SECTION
+    -store STORE      Read -dir as a "dir", an "archive" from gradebook-archive,
+                      a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                      (default: "dir", or "archive" if -dir is a file)
+    -term TERM        Limit calculation to grades in a given TERM
+    -watch            Reprint whenever the class file or a gradebook file
+                      changes (class directories only)
 
 general:
-    -help         Print this message
-    -version      Print version`
+    -help             Print this message
+    -version          Print version`
+
+	classroomUsage = `usage: gradebook-classroom import -file FILE [-class CLASS -date DATE -dir DIR -type TYPE] [-yes] [-help -version]
+
+Create gradebook files from a Google Classroom grade CSV
+
+Each assignment column becomes a new gradebook file with a record for every
+student in class.json. The column's title becomes the assignment name:
+lowercase, with every run of characters outside [A-Za-z0-9._-] replaced by
+a hyphen (e.g., "Quiz 3: Fractions" becomes "quiz-3-fractions"). Scores are
+converted to percentages of the Points row (default: 100 points), and blank
+scores are left unscored. Students who are not in class.json are skipped.
+
+Without -type, the assignment type is inferred from the title when exactly one
+type appears in it as a word (e.g., "quiz" in "Quiz 3"). Otherwise, you are
+asked for the type. The new files are listed before any file is written.
+
+required flags:
+    -file FILE          Google Classroom grade CSV to import
+
+options:
+    -class CLASS        Class file to use (default: ./class.json)
+    -date DATE          Date for assignments without one in the CSV, as
+                        YYYYMMDD (default: today)
+    -dir DIR            Directory for gradebook and class.json files (default: ".")
+    -type TYPE          Assignment type for every column
+    -yes                Write files without asking for confirmation; fail
+                        instead of asking for a type
+
+general:
+    -help               Print this message
+    -version            Print version`
+
+	curveUsage = `usage: gradebook-curve -file FILE METHOD [-class CLASS -dir DIR] [-preview -yes] [-help -version]
+
+Record a curve in a gradebook file
+
+The grades in the file are not changed. Instead, the curve is stored in the
+file and applied whenever grades are calculated, before any late penalty.
+Curved grades never go above 100. The distribution of grades before and after
+the curve is printed before the file is rewritten.
+
+required flags:
+    -file FILE     Gradebook file to curve (relative to DIR)
+
+methods (choose one):
+    -add N         Add N points to every grade
+    -mean TARGET   Add the points that move the current mean to TARGET
+    -scale         Scale grades so that the current top grade becomes 100
+    -sqrt          Replace each grade with 10 times its square root
+    -remove        Remove the file's curve
+
+options:
+    -class CLASS   Class file to use (default: ./class.json)
+    -dir DIR       Directory for gradebook and class.json files (default: ".")
+    -preview       Print the distributions without writing the file
+    -yes           Write the curve without asking for confirmation
+
+general:
+    -help          Print this message
+    -version       Print version`
+
+	decryptUsage = `usage: gradebook-decrypt [-class CLASS -dir DIR -keyfile FILE] [-help -version]
+
+Decrypt a class file and its gradebook files in place
+
+Every file is decrypted before any is written, so a wrong key changes
+nothing. Files that are not encrypted are left alone.
+
+The key is the key file from -keyfile, or else the key file named by
+GRADEBOOK_KEYFILE or the passphrase in GRADEBOOK_PASSPHRASE. Without any of
+these, you are asked for the passphrase.
+
+options:
+    -class CLASS    Class file to use (default: ./class.json)
+    -dir DIR        Directory for gradebook and class.json files (default: ".")
+    -keyfile FILE   Key file that holds 64 hex digits (a 256-bit key)
+
+general:
+    -help           Print this message
+    -version        Print version`
 
-	emailsUsage = `usage: gradebook-emails [-class CLASS -dir DIR] [-help -version]
+	emailsUsage = `usage: gradebook-emails [-class CLASS -dir DIR -section SECTION -store STORE] [-help -version]
 
 Print the emails of students in a class
 
 options:
-    -class CLASS  Class file to use (default: ./class.json)
-    -dir DIR      Directory for gradebook and class.json files (default: ".")
+    -class CLASS      Class file to use (default: ./class.json)
+    -dir DIR          Directory for gradebook and class.json files (default: ".")
+    -section SECTION  Limit output to students in a given SECTION
+    -store STORE      Read -dir as a "dir", an "archive" from gradebook-archive,
+                      a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                      (default: "dir", or "archive" if -dir is a file)
 
 general:
-    -help         Print this message
-    -version      Print version`
+    -help             Print this message
+    -version          Print version`
+
+	encryptUsage = `usage: gradebook-encrypt [-class CLASS -dir DIR -keyfile FILE] [-help -version]
+
+Encrypt a class file and its gradebook files in place
+
+Files are encrypted with AES-256-GCM. The key is the key file from -keyfile,
+or else the key file named by GRADEBOOK_KEYFILE or the passphrase in
+GRADEBOOK_PASSPHRASE. Without any of these, you are asked for a passphrase.
+A key file holds 64 hex digits (e.g., from "openssl rand -hex 32").
+
+Every command reads encrypted files when GRADEBOOK_KEYFILE or
+GRADEBOOK_PASSPHRASE holds the key, and files that commands write in an
+encrypted directory stay encrypted. Files that are already encrypted are
+checked against the key and left alone.
+
+options:
+    -class CLASS    Class file to use (default: ./class.json)
+    -dir DIR        Directory for gradebook and class.json files (default: ".")
+    -keyfile FILE   Key file that holds 64 hex digits (a 256-bit key)
+
+general:
+    -help           Print this message
+    -version        Print version`
+
+	exportUsage = `usage: gradebook-export -out FILE [-class CLASS -dir DIR -format FORMAT -name-style STYLE -section SECTION -store STORE -term TERM] [-help -version]
+
+Export a class's grades to an XLSX or ODS spreadsheet
+
+The spreadsheet has a Summary sheet with each student's averages (as in
+gradebook-calc), a sheet for each category with each student's score on each
+assignment, and a Roster sheet. Numbers are stored as numbers, and unscored
+records and averages without results are left empty.
+
+required flags:
+    -out FILE          Spreadsheet file to write
+
+options:
+    -class CLASS       Class file to use (default: ./class.json)
+    -dir DIR           Directory for gradebook and class.json files (default: ".")
+    -format FORMAT     "xlsx" or "ods" (default: from the extension of FILE)
+    -name-style STYLE  Name style: "legal", "preferred", or "last-first"
+                       (default: "legal")
+    -section SECTION   Limit output to students in a given SECTION
+    -store STORE       Read -dir as a "dir", an "archive" from gradebook-archive,
+                       a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                       (default: "dir", or "archive" if -dir is a file)
+    -term TERM         Limit calculation to grades in a given TERM
+
+general:
+    -help              Print this message
+    -version           Print version`
 
-	namesUsage = `usage: gradebook-names [-class CLASS -dir DIR -last-first] [-help -version]
+	lateUsage = `usage: gradebook-late [-class CLASS -dir DIR -section SECTION -store STORE -term TERM] [-watch] [-help -version]
+
+List late submissions for each student in a class
+
+A record is late if its submitted date is after the gradebook's due_date (or
+its assignment_date if there is no due_date). Penalties come from the class's
+late_policies_by_assignment_category.
+
+options:
+    -class CLASS      Class file to use (default: ./class.json)
+    -dir DIR          Directory for gradebook and class.json files (default: ".")
+    -section SECTION  Limit output to students in a given SECTION
+    -store STORE      Read -dir as a "dir", an "archive" from gradebook-archive,
+                      a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                      (default: "dir", or "archive" if -dir is a file)
+    -term TERM        Limit output to grades in a given TERM
+    -watch            Reprint whenever the class file or a gradebook file
+                      changes (class directories only)
+
+general:
+    -help             Print this message
+    -version          Print version`
+
+	lmsUsage = `usage: gradebook-lms export [-class CLASS -dir DIR -format FORMAT -section SECTION -term TERM] [-help -version]
+       gradebook-lms import -file FILE [-class CLASS -dir DIR] [-yes] [-help -version]
+
+Move grades between gradebook files and an LMS gradebook CSV
+
+export prints a gradebook CSV for the LMS given by -format. Extra credit
+assignments are left out, but overall averages include extra credit.
+
+    canvas      Student, ID, SIS User ID, SIS Login ID, and Section columns,
+                a column for each assignment, and a Points Possible row.
+                Grades are printed as recorded, before curves or late
+                penalties, out of 100 points.
+    moodle      First name, Last name, ID number, and Email address columns,
+                then a column for each assignment's score, each category's
+                average, and the course total, as in gradebook-calc, so
+                missing work scores 0. "-" marks a score that does not
+                count yet.
+    blackboard  Last Name, First Name, Username (email), and Student ID
+                columns, then a column for each assignment's score, each
+                category's average, and the overall average, as in
+                gradebook-calc, so missing work scores 0.
+
+import reads a Canvas gradebook CSV and updates the grades in gradebook files.
+Students match by SIS Login ID (email) or by SIS User ID (student_id), and
+assignment columns match gradebook files by assignment name, ignoring the
+Canvas ID in parentheses. Scores are converted to percentages of the points
+possible. Blank and excused scores are ignored, and columns without
+a matching gradebook file are skipped. The changes are printed before any
+gradebook file is rewritten.
+
+import flags:
+    -file FILE          Canvas gradebook CSV to import (required)
+    -yes                Rewrite gradebook files without asking for confirmation
+
+export options:
+    -format FORMAT      "canvas", "moodle", or "blackboard" (default: "canvas")
+    -section SECTION    Limit output to students in a given SECTION
+    -term TERM          Limit output to grades in a given TERM
+
+options:
+    -class CLASS        Class file to use (default: ./class.json)
+    -dir DIR            Directory for gradebook and class.json files (default: ".")
+
+general:
+    -help               Print this message
+    -version            Print version`
+
+	namesUsage = `usage: gradebook-names [-class CLASS -dir DIR -name-style STYLE -section SECTION -store STORE] [-help -version]
 
 Print the names of students in a class (in "First Last" or "Last, First" format)
 
 options:
-    -class CLASS  Class file to use (default: $PWD/class.json)
-    -dir DIR      Directory for gradebook and class.json files (default: $PWD)
-    -last-first   Print names in "Last, First" format (default: "First Last")
+    -class CLASS       Class file to use (default: $PWD/class.json)
+    -dir DIR           Directory for gradebook and class.json files (default: $PWD)
+    -name-style STYLE  Print names in one of the following styles
+                           legal: "First Last" (default)
+                           preferred: "Preferred Last" (falls back to legal)
+                           last-first: "Last, First"
+    -last-first        Same as -name-style last-first
+    -section SECTION   Limit output to students in a given SECTION
+    -store STORE       Read -dir as a "dir", an "archive" from gradebook-archive,
+                       a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                       (default: "dir", or "archive" if -dir is a file)
 
 general:
-    -help         Print this message
-    -version      Print version`
+    -help              Print this message
+    -version           Print version`
 
-	newUsage = `usage: gradebook-new -name NAME -type TYPE [-class CLASS -date DATE -dir DIR] [-help -version]
+	newUsage = `usage: gradebook-new -name NAME -type TYPE [-class CLASS -date DATE -due DATE -dir DIR] [-help -version]
 
 Create a new gradebook file for a class
 
 required flags:
     -name NAME    Name of the gradebook file (only [A-Za-z0-9._-] are valid)
-    -type TYPE    Type of gradebook file to create (must be in class.json)
+    -type TYPE    Type of gradebook file to create (must be in class.json,
+                  either in categories_by_assignment_type or extra_credit)
 
 options:
     -class        Class file to use (default: ./class.json)
     -date DATE    YYYYMMDD date for gradebook file (default: current date)
+    -due DATE     YYYYMMDD due date for late work (default: same as -date)
     -dir DIR      Directory for gradebook and class.json files (default: $PWD)
 
 general:
     -help         Print this message
     -version      Print version`
 
-	unscoredUsage = `usage: gradebook-unscored [-class CLASS -dir DIR -term TERM] [-help -version]
+	rosterUsage = `usage: gradebook-roster import -file FILE [-class CLASS -dir DIR] [column options] [-yes] [-help -version]
 
-Display how many unscored assignments each student has in each category.
+Merge a roster CSV (e.g., an SIS or LMS export) into the students in class.json
+
+Students in the CSV but not in class.json are added, students in class.json
+but not in the CSV are removed, and students whose names differ are renamed.
+With -id-column, each student's student_id is set from the CSV. The changes,
+including any removed students who still have gradebook records, are printed
+before class.json is rewritten.
+
+required flags:
+    -file FILE          Roster CSV file to import
+
+options:
+    -class CLASS        Class file to use (default: ./class.json)
+    -dir DIR            Directory for gradebook and class.json files (default: ".")
+    -yes                Rewrite class.json without asking for confirmation
+
+column options:
+    -email-column COL   Header of the email column (default: "Email")
+    -name-column COL    Header of the full name column (default: "Student Name")
+    -first-column COL   Header of a first name column (requires -last-column)
+    -last-column COL    Header of a last name column (requires -first-column)
+    -id-column COL      Header of a student ID column (default: none; IDs are
+                        left alone)
+    -name-order ORDER   Word order in the full name column: "first-last" or
+                        "last-first" (default: "first-last"); names with
+                        a comma are always read as "Last, First"
+
+general:
+    -help               Print this message
+    -version            Print version`
+
+	sectionsUsage = `usage: gradebook-sections [-class CLASS -dir DIR -store STORE -term TERM] [-help -version]
+
+Compare the category means of each section of a class side by side
+
+Each mean is the mean of the averages of the students in a section. Students
+without a section are grouped under "(none)".
 
 options:
     -class CLASS  Class file to use (default: ./class.json)
     -dir DIR      Directory for gradebook and class.json files (default: ".")
+    -store STORE  Read -dir as a "dir", an "archive" from gradebook-archive,
+                  a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                  (default: "dir", or "archive" if -dir is a file)
     -term TERM    Limit calculation to grades in a given TERM
 
 general:
     -help         Print this message
     -version      Print version`
+
+	siteUsage = `usage: gradebook-site -out DIR [-as-of DATE -class CLASS -dir DIR -name-style STYLE -section SECTION -store STORE -term TERM] [-help -version]
+
+Generate a static HTML site for a class
+
+The site has an index with the roster, averages, and a box plot of category
+averages; a page for each assignment with statistics, a histogram, the class's
+mean on each rubric criterion (weakest first), and every student's score; and
+a page for each student with averages, a trend chart,
+and every score with its comment and rubric breakdown. Charts are inline SVG,
+so the site needs no other files. Pages of assignments and students that are
+no longer in the class are removed. The same input always produces the same
+output, so the site can be checked into version control and diffed. If the
+class has missing policies, pass -as-of as well: otherwise missing work is
+judged against today's date, and the output changes from day to day.
+
+required flags:
+    -out DIR           Directory to write the site into (created if needed)
+
+options:
+    -as-of DATE        YYYYMMDD date for judging missing work (default: today)
+    -class CLASS       Class file to use (default: ./class.json)
+    -dir DIR           Directory for gradebook and class.json files (default: ".")
+    -name-style STYLE  Name style: "legal", "preferred", or "last-first"
+                       (default: "legal")
+    -section SECTION   Limit the site to students in a given SECTION
+    -store STORE       Read -dir as a "dir", an "archive" from gradebook-archive,
+                       a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                       (default: "dir", or "archive" if -dir is a file)
+    -term TERM         Limit the site to grades in a given TERM
+
+general:
+    -help              Print this message
+    -version           Print version`
+
+	sqliteUsage = `usage: gradebook-sqlite export -out FILE [-class CLASS -dir DIR] [-help -version]
+       gradebook-sqlite import -file FILE [-class CLASS -dir DIR] [-yes] [-help -version]
+
+Export a class to a SQLite database for ad hoc queries, and import grades back
+
+export writes a new SQLite database with these tables:
+
+    class             name
+    terms             id, start_date, end_date
+    categories        id, label, weight
+    assignment_types  type, category, extra_credit
+    students          email, first_name, last_name, preferred_name,
+                      pronouns, section, student_id
+    gradebooks        id, file, name, type, date, due_date, rubric
+    records           gradebook_id, email, grade, score, submitted, comment
+    files             name, data (class.json and each gradebook file as
+                      exported)
+
+Dates are YYYYMMDD text. A record's grade is as recorded, and its score is
+what counts toward the student's average after curves and late penalties.
+The grades view joins records with students, gradebooks, and assignment types.
+
+import reads the records table of a database that export wrote and updates
+the grades and comments in gradebook files to match, so comments can be
+written in any SQLite tool. Records match by gradebook file name and email.
+Records for missing files or students are skipped, and so are new grades for
+records scored by rubric. The changes are printed before any gradebook file
+is rewritten.
+
+export flags:
+    -out FILE          Database file to write (required)
+
+import flags:
+    -file FILE         Database file to import (required)
+    -yes               Rewrite gradebook files without asking for confirmation
+
+options:
+    -class CLASS       Class file to use (default: ./class.json)
+    -dir DIR           Directory for gradebook and class.json files (default: ".")
+
+general:
+    -help              Print this message
+    -version           Print version`
+
+	standardsUsage = `usage: gradebook-standards [-class CLASS -dir DIR -section SECTION -store STORE -term TERM] [-watch] [-help -version]
+
+Print each student's mastery level on each learning standard alongside the
+student's category averages
+
+Standards are defined in the class's labels_by_standard, and scores on them
+(from 1 to 4) are stored in each record's scores_by_standard. The class's
+standards_calculation chooses how scores combine: most_recent (the default),
+highest, or decaying_average.
+
+options:
+    -class CLASS      Class file to use (default: ./class.json)
+    -dir DIR          Directory for gradebook and class.json files (default: ".")
+    -section SECTION  Limit output to students in a given SECTION
+    -store STORE      Read -dir as a "dir", an "archive" from gradebook-archive,
+                      a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                      (default: "dir", or "archive" if -dir is a file)
+    -term TERM        Limit calculation to grades in a given TERM
+    -watch            Reprint whenever the class file or a gradebook file
+                      changes (class directories only)
+
+general:
+    -help             Print this message
+    -version          Print version`
+
+	trendUsage = `usage: gradebook-trend [-class CLASS -dir DIR -format FORMAT -name-style STYLE -section SECTION -store STORE -student EMAIL -term TERM] [-help -version]
+
+Show how each student's averages changed over the term
+
+The gradebook files are replayed in order of assignment date, and each
+student's overall and category averages are recorded after each assignment.
+The text output shows each series as a sparkline on a scale from 0 to 100
+(· means no result yet), followed by the first and last results. The CSV
+output has one row per student per assignment, for charting elsewhere. The
+SVG output is a line chart of one student's averages.
+
+options:
+    -class CLASS       Class file to use (default: ./class.json)
+    -dir DIR           Directory for gradebook and class.json files (default: ".")
+    -format FORMAT     Output format: "text", "csv", or "svg" (default: "text");
+                       "svg" requires -student
+    -name-style STYLE  Name style: "legal", "preferred", or "last-first"
+                       (default: "legal")
+    -section SECTION   Limit output to students in a given SECTION
+    -store STORE       Read -dir as a "dir", an "archive" from gradebook-archive,
+                       a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                       (default: "dir", or "archive" if -dir is a file)
+    -student EMAIL     Limit output to the student with a given EMAIL
+    -term TERM         Limit calculation to grades in a given TERM
+
+general:
+    -help              Print this message
+    -version           Print version`
+
+	tuiUsage = `usage: gradebook-tui [-class CLASS -dir DIR -name-style STYLE -section SECTION -term TERM] [-help -version]
+
+Edit the grades for a class in a full-screen grid of students and assignments
+
+Each row is a student and each column an assignment, oldest first, after the
+student's overall and category averages. The averages are recomputed as
+grades change. Changes are kept until saved; saving rewrites only the grades in
+changed gradebook files, and each file is replaced in one step, so that
+a failed save never leaves a file half written.
+
+keys:
+    arrows, hjkl       Move between cells
+    enter, e           Edit the score in a cell (or start typing a score)
+    x, delete          Make a record unscored
+    c                  Show the next category (or all categories)
+    s                  Save changes
+    q                  Quit (twice if there are unsaved changes)
+
+options:
+    -class CLASS       Class file to use (default: ./class.json)
+    -dir DIR           Directory for gradebook and class.json files (default: ".")
+    -name-style STYLE  Name style: "legal", "preferred", or "last-first"
+                       (default: "legal")
+    -section SECTION   Limit the grid to students in a given SECTION
+    -term TERM         Limit the grid to grades in a given TERM
+
+general:
+    -help              Print this message
+    -version           Print version`
+
+	unscoredUsage = `usage: gradebook-unscored [-class CLASS -dir DIR -section SECTION -store STORE -term TERM] [-watch] [-help -version]
+
+Display how many unscored assignments each student has in each category.
+
+In a category whose missing policy counts unscored records as zero, each count
+is split into missing records and records that are not yet due.
+
+options:
+    -class CLASS      Class file to use (default: ./class.json)
+    -dir DIR          Directory for gradebook and class.json files (default: ".")
+    -section SECTION  Limit output to students in a given SECTION
+    -store STORE      Read -dir as a "dir", an "archive" from gradebook-archive,
+                      a "zip" archive, or a "sqlite" database from gradebook-sqlite
+                      (default: "dir", or "archive" if -dir is a file)
+    -term TERM        Limit calculation to grades in a given TERM
+    -watch            Reprint whenever the class file or a gradebook file
+                      changes (class directories only)
+
+general:
+    -help             Print this message
+    -version          Print version`
 )
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// clearScreen moves the cursor home and clears the terminal.
+	clearScreen = "\x1b[H\x1b[2J"
+	// watchSettle is how long a watcher waits for a burst of changes to end,
+	// since an editor often writes a file in several steps.
+	watchSettle = 100 * time.Millisecond
+	// watchPollInterval is how often the polling watcher looks for changes.
+	watchPollInterval = 500 * time.Millisecond
+)
+
+// dirWatcher waits for a class's files to change.
+type dirWatcher interface {
+	// wait blocks until at least one watched file changes.
+	wait() error
+	close() error
+}
+
+// watchedFiles says which files in a watched directory matter: the class
+// file and gradebook files.
+type watchedFiles struct {
+	dirs      []string
+	classFile string
+}
+
+func newWatchedFiles(directory, classFile string) watchedFiles {
+	dirs := []string{directory}
+	if classDir := filepath.Dir(classFile); classDir != directory {
+		dirs = append(dirs, classDir)
+	}
+
+	return watchedFiles{dirs: dirs, classFile: classFile}
+}
+
+func (wf watchedFiles) matters(path string) bool {
+	return path == wf.classFile || filepath.Ext(path) == gradebookSuffix
+}
+
+// watchCommand runs a command, and then runs it again whenever the class file
+// or a gradebook file changes. Each run clears the screen first. Problems,
+// such as a gradebook file that does not parse, are printed with the output,
+// and the command keeps watching until it is interrupted.
+func watchCommand[T any](cmd *cmdEnv, parsed T, runCfg commandRun[T]) int {
+	cmd.loadKeys("")
+	cmd.resolvePaths()
+	if cmd.noOp() {
+		return cmd.exitValue
+	}
+	if cmd.storeKind != "" && cmd.storeKind != storeDir {
+		cmd.exitValue = exitFailure
+		fmt.Fprintf(cmd.stderr, "%s: -watch works only with a class directory\n", cmd.name)
+
+		return cmd.exitValue
+	}
+
+	w := newDirWatcher(newWatchedFiles(cmd.directory, cmd.classFile))
+	defer w.close()
+
+	return watchClass(cmd, w, parsed, runCfg)
+}
+
+// watchClass loads the class and runs the command's action each time w
+// reports a change.
+func watchClass[T any](cmd *cmdEnv, w dirWatcher, parsed T, runCfg commandRun[T]) int {
+	return cmd.watchLoop(w, func() {
+		cmd.openStore()
+		class := cmd.unmarshalClass()
+		runCfg.action(cmd, class, parsed)
+	})
+}
+
+// watchLoop calls run, and then calls it again each time w reports a change.
+// Every run starts afresh, so a problem in one run does not stop the next.
+// Each run also judges late and missing work by the date it starts on, unless
+// the date was given with -as-of, so a session left running overnight keeps
+// up. The loop ends only when the watcher fails; otherwise it runs until the
+// command is interrupted.
+func (cmd *cmdEnv) watchLoop(w dirWatcher, run func()) int {
+	for {
+		cmd.exitValue = exitSuccess
+		cmd.store = nil
+		cmd.extras = nil
+		if !cmd.asOf {
+			cmd.today = time.Now().Format(dateLayout)
+		}
+
+		fmt.Fprint(cmd.stdout, clearScreen)
+		fmt.Fprintf(
+			cmd.stdout,
+			"%s: watching %s (updated %s; press Ctrl-C to stop)\n\n",
+			cmd.name,
+			cmd.directory,
+			time.Now().Format(time.TimeOnly),
+		)
+		run()
+
+		if err := w.wait(); err != nil {
+			fmt.Fprintf(cmd.stderr, "%s: problem watching %s: %s\n", cmd.name, cmd.directory, err)
+
+			return exitFailure
+		}
+	}
+}
+
+// fileStamp is what the polling watcher compares to notice a change.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// pollWatcher notices changes by listing the watched directories over and
+// over. It works everywhere, but it is slower to notice changes than the
+// watchers that the operating system provides.
+type pollWatcher struct {
+	files    watchedFiles
+	interval time.Duration
+	last     map[string]fileStamp
+}
+
+func newPollWatcher(files watchedFiles, interval time.Duration) *pollWatcher {
+	pw := &pollWatcher{files: files, interval: interval}
+	pw.last = pw.snapshot()
+
+	return pw
+}
+
+func (pw *pollWatcher) snapshot() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, dir := range pw.files.dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if entry.IsDir() || !pw.files.matters(path) {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			}
+		}
+	}
+
+	return stamps
+}
+
+func (pw *pollWatcher) wait() error {
+	for {
+		time.Sleep(pw.interval)
+		now := pw.snapshot()
+		if maps.Equal(now, pw.last) {
+			continue
+		}
+
+		// Wait out the rest of a burst of changes.
+		for {
+			time.Sleep(watchSettle)
+			settled := pw.snapshot()
+			if maps.Equal(settled, now) {
+				break
+			}
+			now = settled
+		}
+		pw.last = now
+
+		return nil
+	}
+}
+
+func (pw *pollWatcher) close() error {
+	return nil
+}
@@ -0,0 +1,152 @@
+//go:build linux
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask asks for the events that mean a file's contents may have
+// changed. Editors that save by writing a new file and renaming it over the
+// old one show up as IN_MOVED_TO.
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_CREATE |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// inotifyWatcher waits for changes with Linux's inotify.
+type inotifyWatcher struct {
+	files watchedFiles
+	wds   map[int]string
+	fd    int
+}
+
+// newDirWatcher returns an inotify watcher, or a polling watcher if inotify
+// is not available (e.g., because the system's limit on watches is reached).
+func newDirWatcher(files watchedFiles) dirWatcher {
+	iw, err := newInotifyWatcher(files)
+	if err != nil {
+		return newPollWatcher(files, watchPollInterval)
+	}
+
+	return iw
+}
+
+func newInotifyWatcher(files watchedFiles) (*inotifyWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("start inotify: %w", err)
+	}
+
+	iw := &inotifyWatcher{files: files, fd: fd, wds: make(map[int]string, len(files.dirs))}
+	for _, dir := range files.dirs {
+		wd, err := syscall.InotifyAddWatch(fd, dir, inotifyMask)
+		if err != nil {
+			_ = iw.close()
+
+			return nil, fmt.Errorf("watch %q: %w", dir, err)
+		}
+		iw.wds[wd] = dir
+	}
+
+	return iw, nil
+}
+
+func (iw *inotifyWatcher) wait() error {
+	for {
+		if _, err := iw.ready(-1); err != nil {
+			return err
+		}
+		changed, err := iw.drain()
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+
+		// Wait out the rest of a burst of changes.
+		for {
+			ready, err := iw.ready(watchSettle)
+			if err != nil {
+				return err
+			}
+			if !ready {
+				return nil
+			}
+			if _, err := iw.drain(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ready waits until there are events to read or the timeout passes. A
+// negative timeout waits for as long as it takes.
+func (iw *inotifyWatcher) ready(timeout time.Duration) (bool, error) {
+	ms := -1
+	if timeout >= 0 {
+		ms = int(timeout.Milliseconds())
+	}
+
+	for {
+		fds := []unix.PollFd{{Fd: int32(iw.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, ms)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("wait for inotify: %w", err)
+		}
+
+		return n > 0, nil
+	}
+}
+
+// drain reads every pending event and reports whether any of them is about
+// a file that matters.
+func (iw *inotifyWatcher) drain() (bool, error) {
+	changed := false
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := syscall.Read(iw.fd, buf)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if errors.Is(err, syscall.EAGAIN) {
+			return changed, nil
+		}
+		if err != nil {
+			return changed, fmt.Errorf("read inotify events: %w", err)
+		}
+
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameStart := off + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			off = nameEnd
+
+			if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+				changed = true
+
+				continue
+			}
+			name := string(buf[nameStart:nameEnd])
+			for len(name) > 0 && name[len(name)-1] == 0 {
+				name = name[:len(name)-1]
+			}
+			if dir, ok := iw.wds[int(event.Wd)]; ok && name != "" && iw.files.matters(filepath.Join(dir, name)) {
+				changed = true
+			}
+		}
+	}
+}
+
+func (iw *inotifyWatcher) close() error {
+	return syscall.Close(iw.fd)
+}
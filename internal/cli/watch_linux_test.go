@@ -0,0 +1,21 @@
+//go:build linux
+
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestInotifyWatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	iw, err := newInotifyWatcher(newWatchedFiles(dir, filepath.Join(dir, suiteClassFile)))
+	if err != nil {
+		t.Skipf("inotify is not available: %v", err)
+	}
+	defer iw.close()
+
+	testWatcherNotices(t, iw, dir)
+}
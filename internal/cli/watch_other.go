@@ -0,0 +1,8 @@
+//go:build !linux
+
+package cli
+
+// newDirWatcher returns a polling watcher, since inotify is only on Linux.
+func newDirWatcher(files watchedFiles) dirWatcher {
+	return newPollWatcher(files, watchPollInterval)
+}
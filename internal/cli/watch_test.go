@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/telemachus/gradebook"
+)
+
+// errNoMoreChanges ends a watch loop in tests, which otherwise runs until the
+// command is interrupted.
+var errNoMoreChanges = errors.New("no more changes")
+
+// fakeWatcher makes one change to the class directory each time it is
+// waited on, and ends the loop when it runs out of changes.
+type fakeWatcher struct {
+	changes []func()
+}
+
+func (fw *fakeWatcher) wait() error {
+	if len(fw.changes) == 0 {
+		return errNoMoreChanges
+	}
+	fw.changes[0]()
+	fw.changes = fw.changes[1:]
+
+	return nil
+}
+
+func (fw *fakeWatcher) close() error {
+	return nil
+}
+
+func TestWatchClass(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	gbFile := filepath.Join(dir, "quiz-quiz-1-20240319.gradebook")
+	rewrite := func(data string) func() {
+		return func() { mustWriteFixtureFile(t, gbFile, data) }
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := cmdFromWithWriters("gradebook-calc", calcUsage, &stdout, &stderr)
+	term := cmd.parseCalculate([]string{"-dir", dir, "-watch"})
+	cmd.resolvePaths()
+
+	w := &fakeWatcher{changes: []func(){
+		rewrite(strings.Replace(gradebookFixtureJSON, `"grade": 90`, `"grade": 75`, 1)),
+		rewrite("{"),
+		rewrite(gradebookFixtureJSON),
+	}}
+	exitCode := watchClass(cmd, w, term, commandRun[string]{
+		action: func(cmd *cmdEnv, class *gradebook.Class, term string) {
+			gbFiles := cmd.loadGrades(class, term)
+			cmd.printAll(class, gbFiles)
+		},
+	})
+
+	// Only a failing watcher ends the loop.
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d (stderr: %q)", exitCode, exitFailure, stderr.String())
+	}
+	if want := "problem watching " + dir + ": no more changes\n"; !strings.HasSuffix(stderr.String(), want) {
+		t.Errorf("stderr = %q; want it to end with %q", stderr.String(), want)
+	}
+
+	runs := strings.Split(stdout.String(), clearScreen)[1:]
+	if len(runs) != 4 {
+		t.Fatalf("got %d runs; want 4 (stdout: %q)", len(runs), stdout.String())
+	}
+	for i, want := range []string{"Overall average: 90\n", "Overall average: 75\n", "", "Overall average: 90\n"} {
+		if !strings.HasPrefix(runs[i], "gradebook-calc: watching "+dir) {
+			t.Errorf("run %d = %q; want it to start with a header", i, runs[i])
+		}
+		if want != "" && !strings.Contains(runs[i], want) {
+			t.Errorf("run %d = %q; want it to contain %q", i, runs[i], want)
+		}
+	}
+	if !strings.Contains(stderr.String(), gbFile) {
+		t.Errorf("stderr = %q; want it to report the broken gradebook file", stderr.String())
+	}
+}
+
+func TestPublicGradebookCalcWatchNeedsDirectory(t *testing.T) {
+	t.Parallel()
+
+	archive := archiveFixture(t, writeArchiveFixture(t), "q1")
+	exitCode, _, stderr := runPublicCommand(t, GradebookCalc, []string{"-dir", archive, "-watch"})
+	if exitCode != exitFailure {
+		t.Fatalf("exitCode = %d; want %d", exitCode, exitFailure)
+	}
+	if want := "-watch works only with a class directory"; !strings.Contains(stderr, want) {
+		t.Errorf("stderr = %q; want it to contain %q", stderr, want)
+	}
+}
+
+func TestPollWatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := writeSuiteFixture(t)
+	pw := newPollWatcher(newWatchedFiles(dir, filepath.Join(dir, suiteClassFile)), 10*time.Millisecond)
+	testWatcherNotices(t, pw, dir)
+}
+
+// testWatcherNotices checks that w notices a change to a gradebook file.
+func testWatcherNotices(t *testing.T, w dirWatcher, dir string) {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() { done <- w.wait() }()
+
+	// Give the watcher a moment to start waiting.
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "quiz-quiz-2-20240320.gradebook"), []byte(gradebookFixtureJSON), 0o644); err != nil {
+		t.Fatalf("failed to write gradebook file: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("wait() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait() did not notice a new gradebook file")
+	}
+}
+
+func TestWatchLoopUpdatesToday(t *testing.T) {
+	t.Parallel()
+
+	for _, asOf := range []bool{false, true} {
+		var stdout, stderr bytes.Buffer
+		cmd := cmdFromWithWriters("gradebook-calc", calcUsage, &stdout, &stderr)
+		cmd.today = "20000101"
+		cmd.asOf = asOf
+
+		var days []string
+		cmd.watchLoop(&fakeWatcher{changes: []func(){func() {}}}, func() {
+			days = append(days, cmd.today)
+		})
+
+		want := time.Now().Format(dateLayout)
+		if asOf {
+			want = "20000101"
+		}
+		if len(days) != 2 || days[0] != want || days[1] != want {
+			t.Errorf("with asOf %t, runs saw dates %q; want %q each time", asOf, days, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+const odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+<manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="` + odsMimeType + `"/>
+<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>
+`
+
+// WriteODS writes the workbook to w as an OpenDocument spreadsheet.
+func WriteODS(w io.Writer, wb *Workbook) error {
+	names := wb.sheetNames()
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<office:document-content ` +
+		`xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+		`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+		`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+		`office:version="1.2">` + "\n<office:body>\n<office:spreadsheet>\n")
+
+	for i, sh := range wb.Sheets {
+		fmt.Fprintf(&sb, `<table:table table:name="%s">`+"\n", escape(names[i]))
+		for _, row := range sh.Rows {
+			sb.WriteString("<table:table-row>")
+			for _, cell := range row {
+				sb.WriteString(odsCell(cell))
+			}
+			sb.WriteString("</table:table-row>\n")
+		}
+		sb.WriteString("</table:table>\n")
+	}
+
+	sb.WriteString("</office:spreadsheet>\n</office:body>\n</office:document-content>\n")
+
+	// The mimetype file must come first and must not be compressed, so that
+	// programs can identify the file from its first bytes.
+	return writeZip(w, []zipFile{
+		{name: "mimetype", data: []byte(odsMimeType), method: zip.Store},
+		{name: "META-INF/manifest.xml", data: []byte(odsManifest), method: zip.Deflate},
+		{name: "content.xml", data: []byte(sb.String()), method: zip.Deflate},
+	})
+}
+
+func odsCell(cell Cell) string {
+	switch {
+	case cell.IsNumber:
+		n := cell.number()
+
+		return fmt.Sprintf(`<table:table-cell office:value-type="float" office:value="%s"><text:p>%s</text:p></table:table-cell>`, n, n)
+	case cell.isEmpty():
+		return "<table:table-cell/>"
+	default:
+		return fmt.Sprintf(
+			`<table:table-cell office:value-type="string"><text:p>%s</text:p></table:table-cell>`,
+			escape(cell.Text),
+		)
+	}
+}
@@ -0,0 +1,170 @@
+// Package spreadsheet writes simple workbooks as XLSX or ODS files.
+//
+// Both formats are zip archives of XML documents, and both are generated
+// here with the standard library alone. A workbook holds only values: text
+// cells, number cells, and empty cells. Output depends only on the workbook,
+// so the same workbook always produces byte-identical files.
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSheetName is the longest sheet name that Excel accepts.
+const maxSheetName = 31
+
+// zipTime is the modification time of every file in a workbook archive, so
+// that archives do not depend on when they were made.
+var zipTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Workbook is an ordered list of sheets.
+type Workbook struct {
+	Sheets []*Sheet
+}
+
+// Sheet is a named grid of cells. Rows may have different lengths.
+type Sheet struct {
+	Name string
+	Rows [][]Cell
+}
+
+// Cell is a single value. The zero Cell is empty.
+type Cell struct {
+	Text     string
+	Number   float64
+	IsNumber bool
+}
+
+// Text returns a text cell. Empty text gives an empty cell.
+func Text(s string) Cell {
+	return Cell{Text: s}
+}
+
+// Number returns a number cell.
+func Number(f float64) Cell {
+	return Cell{Number: f, IsNumber: true}
+}
+
+// Empty returns an empty cell.
+func Empty() Cell {
+	return Cell{}
+}
+
+func (c Cell) isEmpty() bool {
+	return !c.IsNumber && c.Text == ""
+}
+
+func (c Cell) number() string {
+	return strconv.FormatFloat(c.Number, 'f', -1, 64)
+}
+
+// AddSheet appends a sheet to the workbook and returns it.
+func (wb *Workbook) AddSheet(name string) *Sheet {
+	sh := &Sheet{Name: name}
+	wb.Sheets = append(wb.Sheets, sh)
+
+	return sh
+}
+
+// AddRow appends a row of cells to the sheet.
+func (sh *Sheet) AddRow(cells ...Cell) {
+	sh.Rows = append(sh.Rows, cells)
+}
+
+// sheetNames returns a name for each sheet that every spreadsheet program
+// accepts: no more than 31 characters, none of []:*?/\, and unique within the
+// workbook.
+func (wb *Workbook) sheetNames() []string {
+	names := make([]string, 0, len(wb.Sheets))
+	seen := make(map[string]bool, len(wb.Sheets))
+	for i, sh := range wb.Sheets {
+		base := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`[]:*?/\`, r) {
+				return '_'
+			}
+
+			return r
+		}, strings.TrimSpace(sh.Name))
+		if base == "" {
+			base = fmt.Sprintf("Sheet%d", i+1)
+		}
+
+		name := truncate(base, maxSheetName)
+		for n := 2; seen[strings.ToLower(name)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			name = truncate(base, maxSheetName-len(suffix)) + suffix
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n])
+}
+
+// zipWriter adds files to a zip archive with a fixed modification time.
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z zipWriter) add(name string, method uint16, data []byte) error {
+	fw, err := z.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: zipTime,
+	})
+	if err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+	if _, err = fw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// writeZip writes files, in order, as a zip archive to w.
+func writeZip(w io.Writer, files []zipFile) error {
+	z := zipWriter{zw: zip.NewWriter(w)}
+	for _, f := range files {
+		if err := z.add(f.name, f.method, f.data); err != nil {
+			return err
+		}
+	}
+
+	if err := z.zw.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+
+	return nil
+}
+
+type zipFile struct {
+	name   string
+	data   []byte
+	method uint16
+}
+
+// escape returns s with XML special characters escaped.
+func escape(s string) string {
+	var buf bytes.Buffer
+	// Writing to a bytes.Buffer never fails.
+	_ = xml.EscapeText(&buf, []byte(s))
+
+	return buf.String()
+}
@@ -0,0 +1,193 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testWorkbook() *Workbook {
+	wb := &Workbook{}
+	sh := wb.AddSheet("Grades: <Term 1>")
+	sh.AddRow(Text("Student"), Text("Score"), Text("Note"))
+	sh.AddRow(Text("Bob & Co"), Number(71.25), Empty())
+	wb.AddSheet("grades: <term 1>").AddRow(Text("duplicate name"))
+
+	return wb
+}
+
+// readZip checks that every file in the archive is present in order and that
+// every XML file is well-formed.
+func readZip(t *testing.T, data []byte, want ...string) map[string]string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+
+	files := make(map[string]string, len(zr.File))
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		if strings.HasSuffix(f.Name, ".xml") || strings.HasSuffix(f.Name, ".rels") {
+			checkWellFormed(t, f.Name, b)
+		}
+		files[f.Name] = string(b)
+		names = append(names, f.Name)
+	}
+
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Fatalf("archive files = %q; want %q", names, want)
+	}
+
+	return files
+}
+
+func checkWellFormed(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("%s is not well-formed: %v\n%s", name, err, data)
+		}
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, testWorkbook()); err != nil {
+		t.Fatalf("WriteXLSX() error = %v", err)
+	}
+
+	files := readZip(t, buf.Bytes(),
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels",
+		"xl/worksheets/sheet1.xml",
+		"xl/worksheets/sheet2.xml",
+	)
+
+	book := files["xl/workbook.xml"]
+	for _, want := range []string{
+		`<sheet name="Grades_ &lt;Term 1&gt;" sheetId="1" r:id="rId1"/>`,
+		`<sheet name="grades_ &lt;term 1&gt; (2)" sheetId="2" r:id="rId2"/>`,
+	} {
+		if !strings.Contains(book, want) {
+			t.Errorf("workbook.xml does not contain %q\n%s", want, book)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="B2"><v>71.25</v></c>`) {
+		t.Errorf("sheet1.xml does not store 71.25 as a number\n%s", sheet)
+	}
+	if !strings.Contains(sheet, `<t xml:space="preserve">Bob &amp; Co</t>`) {
+		t.Errorf("sheet1.xml does not contain escaped text\n%s", sheet)
+	}
+	if strings.Contains(sheet, `r="C2"`) {
+		t.Errorf("sheet1.xml contains the empty cell C2\n%s", sheet)
+	}
+}
+
+func TestWriteODS(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := WriteODS(&buf, testWorkbook()); err != nil {
+		t.Fatalf("WriteODS() error = %v", err)
+	}
+
+	files := readZip(t, buf.Bytes(), "mimetype", "META-INF/manifest.xml", "content.xml")
+
+	zr, _ := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if zr.File[0].Method != zip.Store {
+		t.Errorf("mimetype method = %d; want %d (stored)", zr.File[0].Method, zip.Store)
+	}
+	if files["mimetype"] != odsMimeType {
+		t.Errorf("mimetype = %q; want %q", files["mimetype"], odsMimeType)
+	}
+
+	content := files["content.xml"]
+	for _, want := range []string{
+		`<table:table table:name="Grades_ &lt;Term 1&gt;">`,
+		`<table:table table:name="grades_ &lt;term 1&gt; (2)">`,
+		`office:value-type="float" office:value="71.25"`,
+		`<text:p>Bob &amp; Co</text:p>`,
+		`<table:table-cell/>`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content.xml does not contain %q\n%s", want, content)
+		}
+	}
+}
+
+func TestWritersAreDeterministic(t *testing.T) {
+	t.Parallel()
+
+	for name, write := range map[string]func(io.Writer, *Workbook) error{
+		"xlsx": WriteXLSX,
+		"ods":  WriteODS,
+	} {
+		var first, second bytes.Buffer
+		if err := write(&first, testWorkbook()); err != nil {
+			t.Fatalf("%s: write error = %v", name, err)
+		}
+		if err := write(&second, testWorkbook()); err != nil {
+			t.Fatalf("%s: write error = %v", name, err)
+		}
+		if !bytes.Equal(first.Bytes(), second.Bytes()) {
+			t.Errorf("%s: output differs between runs", name)
+		}
+	}
+}
+
+func TestSheetNames(t *testing.T) {
+	t.Parallel()
+
+	wb := &Workbook{}
+	wb.AddSheet("")
+	wb.AddSheet(strings.Repeat("x", 40))
+	wb.AddSheet(strings.Repeat("x", 40))
+	wb.AddSheet("a/b?c")
+
+	got := wb.sheetNames()
+	want := []string{
+		"Sheet1",
+		strings.Repeat("x", 31),
+		strings.Repeat("x", 27) + " (2)",
+		"a_b_c",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("sheetNames() = %q; want %q", got, want)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	t.Parallel()
+
+	for c, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(c); got != want {
+			t.Errorf("columnName(%d) = %q; want %q", c, got, want)
+		}
+	}
+}
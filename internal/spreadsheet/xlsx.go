@@ -0,0 +1,108 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const xlsxContentTypesHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>
+`
+
+// WriteXLSX writes the workbook to w as an Office Open XML spreadsheet.
+// Text cells are stored inline, so the file needs no shared string table.
+func WriteXLSX(w io.Writer, wb *Workbook) error {
+	names := wb.sheetNames()
+
+	var types, book, rels strings.Builder
+	types.WriteString(xlsxContentTypesHead)
+	book.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` + "\n<sheets>\n")
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + "\n")
+
+	files := make([]zipFile, 0, len(wb.Sheets)+4)
+	for i, sh := range wb.Sheets {
+		n := i + 1
+		fmt.Fprintf(
+			&types,
+			`<Override PartName="/xl/worksheets/sheet%d.xml" `+
+				`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`+"\n",
+			n,
+		)
+		fmt.Fprintf(&book, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`+"\n", escape(names[i]), n, n)
+		fmt.Fprintf(
+			&rels,
+			`<Relationship Id="rId%d" `+
+				`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+				`Target="worksheets/sheet%d.xml"/>`+"\n",
+			n,
+			n,
+		)
+		files = append(files, zipFile{
+			name:   fmt.Sprintf("xl/worksheets/sheet%d.xml", n),
+			data:   xlsxSheet(sh),
+			method: zip.Deflate,
+		})
+	}
+	types.WriteString("</Types>\n")
+	book.WriteString("</sheets>\n</workbook>\n")
+	rels.WriteString("</Relationships>\n")
+
+	files = append([]zipFile{
+		{name: "[Content_Types].xml", data: []byte(types.String()), method: zip.Deflate},
+		{name: "_rels/.rels", data: []byte(xlsxRootRels), method: zip.Deflate},
+		{name: "xl/workbook.xml", data: []byte(book.String()), method: zip.Deflate},
+		{name: "xl/_rels/workbook.xml.rels", data: []byte(rels.String()), method: zip.Deflate},
+	}, files...)
+
+	return writeZip(w, files)
+}
+
+func xlsxSheet(sh *Sheet) []byte {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + "\n<sheetData>\n")
+
+	for r, row := range sh.Rows {
+		fmt.Fprintf(&sb, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			switch {
+			case cell.IsNumber:
+				fmt.Fprintf(&sb, `<c r="%s"><v>%s</v></c>`, ref, cell.number())
+			case !cell.isEmpty():
+				fmt.Fprintf(&sb, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(cell.Text))
+			}
+		}
+		sb.WriteString("</row>\n")
+	}
+
+	sb.WriteString("</sheetData>\n</worksheet>\n")
+
+	return []byte(sb.String())
+}
+
+// columnName returns the spreadsheet name of the zero-based column c: A, B,
+// ..., Z, AA, AB, and so on.
+func columnName(c int) string {
+	name := ""
+	for c++; c > 0; c = (c - 1) / 26 {
+		name = string(rune('A'+(c-1)%26)) + name
+	}
+
+	return name
+}